@@ -5,6 +5,7 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"os"
 	"strconv"
 	"strings"
@@ -46,14 +47,64 @@ var (
 	thrustL float64 // drone left thrust
 	thrustR float64 // drone right thrust
 	// Phase plot axes
-	xAxis int
-	yAxis int
+	xAxis string
+	yAxis string
 	// Config file
 	configFile string
 	// Frame rate for live view
 	frameRate int
 	// Preset name
 	preset string
+	// Streaming output
+	stream      bool
+	sampleEvery int
+	sampleDt    float64
+	outputDt    float64
+	// Sampled control
+	controlDt     float64
+	controlJitter float64
+	// Adaptive stepping
+	adaptive  bool
+	tolerance float64
+	minDt     float64
+	maxDt     float64
+	// Ensembles
+	numRuns      int
+	workers      int
+	stateStd     float64
+	paramStd     []string
+	ensembleSeed int64
+	// Model parameter overrides
+	modelParams []string
+	// Feedback latency
+	delay float64
+	// Declarative model definition
+	modelFile string
+	// Reference tracking
+	referenceFile string
+	// Linearization
+	guesses       []string
+	numSeeds      int
+	seedSpread    float64
+	linearizeSeed int64
+	operatingU    []float64
+	observedVars  []string
+	// LQR synthesis and MPC
+	lqrQ       []float64
+	lqrR       []float64
+	setpoint   []float64
+	horizon    int
+	mpcDt      float64
+	linearMPC  bool
+	uMin, uMax []float64
+	xMin, xMax []float64
+	pidLoops   []config.PIDLoopConfig
+	// State estimation
+	estimatorType string
+	sensorNoise   []float64
+	sensorBias    []float64
+	sensorDt      float64
+	processNoise  []float64
 )
 
 func main() {
@@ -71,7 +122,7 @@ func main() {
 	runCmd := &cobra.Command{
 		Use:   "run [model]",
 		Short: "run simulation",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		RunE:  runSimulation,
 	}
 	runCmd.Flags().Float64Var(&dt, "dt", 0.01, "timestep")
@@ -87,11 +138,41 @@ func main() {
 	runCmd.Flags().Float64Var(&ki, "ki", 0.1, "pid ki")
 	runCmd.Flags().Float64Var(&kd, "kd", 5.0, "pid kd")
 	runCmd.Flags().Float64Var(&target, "target", 0.0, "pid target")
+	runCmd.Flags().Float64SliceVar(&lqrQ, "q", nil, "lqr state weights, one value or one per state; designs the gains from the model")
+	runCmd.Flags().Float64SliceVar(&lqrR, "r", nil, "lqr control weights, one value or one per input")
+	runCmd.Flags().Float64SliceVar(&setpoint, "setpoint", nil, "full state the lqr or mpc regulates to (default: origin)")
+	runCmd.Flags().IntVar(&horizon, "horizon", control.DefaultHorizon, "mpc prediction steps")
+	runCmd.Flags().Float64Var(&mpcDt, "mpc-dt", control.DefaultMPCDt, "mpc prediction step and replanning period")
+	runCmd.Flags().BoolVar(&linearMPC, "linear", false, "mpc predicts with the linearization at the setpoint")
+	runCmd.Flags().Float64SliceVar(&uMin, "u-min", nil, "mpc input lower bounds, one value or one per input")
+	runCmd.Flags().Float64SliceVar(&uMax, "u-max", nil, "mpc input upper bounds, one value or one per input")
+	runCmd.Flags().Float64SliceVar(&xMin, "x-min", nil, "mpc soft state lower bounds (-inf for none)")
+	runCmd.Flags().Float64SliceVar(&xMax, "x-max", nil, "mpc soft state upper bounds (inf for none)")
+	runCmd.Flags().StringVar(&estimatorType, "estimator", "", "feed the controller a state estimate: kf, ekf or ukf")
+	runCmd.Flags().StringSliceVar(&observedVars, "observe", nil, "state variables the sensor measures (default: all)")
+	runCmd.Flags().Float64SliceVar(&sensorNoise, "sensor-noise", nil, "sensor noise standard deviation, one value or one per measurement")
+	runCmd.Flags().Float64SliceVar(&sensorBias, "sensor-bias", nil, "constant sensor bias, one value or one per measurement")
+	runCmd.Flags().Float64Var(&sensorDt, "sensor-dt", 0, "sensor sample period (0 = every step)")
+	runCmd.Flags().Float64SliceVar(&processNoise, "process-noise", []float64{1e-4}, "process noise density the estimator assumes, one value or one per state")
 	runCmd.Flags().IntVar(&numBodies, "bodies", 3, "number of bodies (nbody)")
 	runCmd.Flags().Float64Var(&theta2, "theta2", 0.5, "second angle (double_pendulum)")
 	runCmd.Flags().Float64Var(&omega2, "omega2", 0.0, "second angular velocity (double_pendulum)")
 	runCmd.Flags().StringVar(&configFile, "config", "", "config file path (yaml)")
 	runCmd.Flags().StringVar(&preset, "preset", "", "use preset configuration")
+	runCmd.Flags().BoolVar(&stream, "stream", false, "write states to disk as they are computed instead of buffering the run")
+	runCmd.Flags().IntVar(&sampleEvery, "every", 0, "record every Nth step")
+	runCmd.Flags().Float64Var(&sampleDt, "sample-dt", 0, "record at most once per this much simulated time")
+	runCmd.Flags().Float64Var(&outputDt, "output-dt", 0, "record on a uniform time grid with this spacing, interpolating between steps")
+	runCmd.Flags().BoolVar(&adaptive, "adaptive", false, "adaptive step size control, starting from --dt")
+	runCmd.Flags().Float64Var(&tolerance, "tol", 1e-6, "error tolerance for adaptive stepping")
+	runCmd.Flags().Float64Var(&minDt, "min-dt", 1e-8, "smallest adaptive step before giving up")
+	runCmd.Flags().Float64Var(&maxDt, "max-dt", 0, "largest adaptive step (0 = unlimited)")
+	runCmd.Flags().StringSliceVar(&modelParams, "param", nil, "set a model parameter, name=value (repeatable)")
+	runCmd.Flags().Float64Var(&delay, "delay", 0, "feed the controller measurements this many seconds old")
+	runCmd.Flags().Float64Var(&controlDt, "control-dt", 0, "update the controller at this period and hold its output in between (0 = every step)")
+	runCmd.Flags().Float64Var(&controlJitter, "control-jitter", 0, "delay each controller update by a random time of up to this many seconds")
+	runCmd.Flags().StringVar(&modelFile, "model-file", "", "load the model from a yaml definition")
+	runCmd.Flags().StringVar(&referenceFile, "reference", "", "track a csv trajectory: a time column and one column per state variable")
 
 	listCmd := &cobra.Command{
 		Use:   "list",
@@ -148,6 +229,9 @@ func main() {
 	liveCmd.Flags().Float64Var(&ki, "ki", 0.1, "pid ki")
 	liveCmd.Flags().Float64Var(&kd, "kd", 5.0, "pid kd")
 	liveCmd.Flags().Float64Var(&target, "target", 0.0, "pid target")
+	liveCmd.Flags().Float64SliceVar(&lqrQ, "q", nil, "lqr state weights, one value or one per state")
+	liveCmd.Flags().Float64SliceVar(&lqrR, "r", nil, "lqr control weights, one value or one per input")
+	liveCmd.Flags().Float64SliceVar(&setpoint, "setpoint", nil, "full state the lqr regulates to (default: origin)")
 	liveCmd.Flags().IntVar(&frameRate, "fps", 30, "frame rate")
 
 	phaseCmd := &cobra.Command{
@@ -156,8 +240,8 @@ func main() {
 		Args:  cobra.ExactArgs(1),
 		RunE:  phasePlot,
 	}
-	phaseCmd.Flags().IntVar(&xAxis, "x-axis", 0, "state index for x-axis")
-	phaseCmd.Flags().IntVar(&yAxis, "y-axis", 1, "state index for y-axis")
+	phaseCmd.Flags().StringVar(&xAxis, "x-axis", "0", "state index or name for x-axis")
+	phaseCmd.Flags().StringVar(&yAxis, "y-axis", "1", "state index or name for y-axis")
 
 	exportCSVCmd := &cobra.Command{
 		Use:   "export-csv [run_id]",
@@ -202,6 +286,57 @@ func main() {
 		},
 	}
 
+	ensembleCmd := &cobra.Command{
+		Use:   "ensemble [model]",
+		Short: "run perturbed copies of a simulation in parallel and summarize the spread",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runEnsemble,
+	}
+	ensembleCmd.Flags().Float64Var(&dt, "dt", 0.01, "timestep")
+	ensembleCmd.Flags().Float64Var(&duration, "time", 10.0, "duration")
+	ensembleCmd.Flags().Float64Var(&theta, "theta", 0.5, "initial angle")
+	ensembleCmd.Flags().Float64Var(&omega, "omega", 0.0, "initial angular velocity")
+	ensembleCmd.Flags().Int64Var(&ensembleSeed, "seed", 1, "seed of the first member; member i uses seed+i")
+	ensembleCmd.Flags().StringVar(&integrator, "integrator", "rk4", "integrator")
+	ensembleCmd.Flags().StringVar(&controller, "controller", "none", "controller")
+	ensembleCmd.Flags().Float64SliceVar(&lqrQ, "q", nil, "lqr state weights, one value or one per state; designs the gains from the nominal model")
+	ensembleCmd.Flags().Float64SliceVar(&lqrR, "r", nil, "lqr control weights, one value or one per input")
+	ensembleCmd.Flags().Float64SliceVar(&setpoint, "setpoint", nil, "full state the lqr or mpc regulates to (default: origin)")
+	ensembleCmd.Flags().IntVar(&horizon, "horizon", control.DefaultHorizon, "mpc prediction steps")
+	ensembleCmd.Flags().Float64Var(&mpcDt, "mpc-dt", control.DefaultMPCDt, "mpc prediction step and replanning period")
+	ensembleCmd.Flags().BoolVar(&linearMPC, "linear", false, "mpc predicts with the linearization at the setpoint")
+	ensembleCmd.Flags().Float64SliceVar(&uMin, "u-min", nil, "mpc input lower bounds, one value or one per input")
+	ensembleCmd.Flags().Float64SliceVar(&uMax, "u-max", nil, "mpc input upper bounds, one value or one per input")
+	ensembleCmd.Flags().Float64SliceVar(&xMin, "x-min", nil, "mpc soft state lower bounds (-inf for none)")
+	ensembleCmd.Flags().Float64SliceVar(&xMax, "x-max", nil, "mpc soft state upper bounds (inf for none)")
+	ensembleCmd.Flags().StringVar(&modelFile, "model-file", "", "load the model from a yaml definition")
+	ensembleCmd.Flags().IntVar(&numRuns, "runs", 50, "number of members")
+	ensembleCmd.Flags().IntVar(&workers, "workers", 0, "members run at once (0 = one per cpu)")
+	ensembleCmd.Flags().Float64Var(&stateStd, "state-std", 0.01, "std dev of initial state noise")
+	ensembleCmd.Flags().StringSliceVar(&paramStd, "param-std", nil, "relative std dev of a parameter, name=value (repeatable)")
+
+	paramsCmd := &cobra.Command{
+		Use:   "params [model]",
+		Short: "list tunable parameters of a model",
+		Args:  cobra.ExactArgs(1),
+		RunE:  listParams,
+	}
+
+	linearizeCmd := &cobra.Command{
+		Use:   "linearize [model]",
+		Short: "find equilibria and analyse the linearized model around each",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runLinearize,
+	}
+	linearizeCmd.Flags().StringArrayVar(&guesses, "guess", nil, "starting state for the equilibrium search, comma separated (repeatable)")
+	linearizeCmd.Flags().IntVar(&numSeeds, "samples", 20, "starting states sampled around the default state when no --guess is given")
+	linearizeCmd.Flags().Float64Var(&seedSpread, "spread", 1.0, "half-width of the sampling box around the default state")
+	linearizeCmd.Flags().Int64Var(&linearizeSeed, "seed", 1, "random seed for sampling")
+	linearizeCmd.Flags().Float64SliceVar(&operatingU, "control", nil, "control input held at the operating point")
+	linearizeCmd.Flags().StringSliceVar(&observedVars, "observe", nil, "state variables measured, for the observability rank (default: all)")
+	linearizeCmd.Flags().StringSliceVar(&modelParams, "param", nil, "set a model parameter, name=value (repeatable)")
+	linearizeCmd.Flags().StringVar(&modelFile, "model-file", "", "load the model from a yaml definition")
+
 	exportJSONCmd := &cobra.Command{
 		Use:   "export-json [run_id]",
 		Short: "export run data to JSON",
@@ -222,14 +357,24 @@ func main() {
 		},
 	}
 
-	rootCmd.AddCommand(runCmd, listCmd, plotCmd, exportCmd, benchCmd, analyzeCmd, liveCmd, phaseCmd, exportCSVCmd, tuiCmd, compareCmd, presetsCmd, exportJSONCmd, guiCmd)
+	rootCmd.AddCommand(runCmd, listCmd, plotCmd, exportCmd, benchCmd, analyzeCmd, liveCmd, phaseCmd, exportCSVCmd, tuiCmd, compareCmd, ensembleCmd, presetsCmd, paramsCmd, linearizeCmd, exportJSONCmd, guiCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 func runSimulation(cmd *cobra.Command, args []string) error {
-	model := args[0]
+	var model string
+	if len(args) > 0 {
+		model = args[0]
+	}
+	if model == "" && modelFile == "" {
+		return fmt.Errorf("run needs a model name or --model-file")
+	}
+
+	var events []config.EventConfig
+	var actuators []config.ActuatorConfig
+	var references []config.ReferenceConfig
 
 	// Load preset if specified
 	if preset != "" {
@@ -250,6 +395,9 @@ func runSimulation(cmd *cobra.Command, args []string) error {
 		omega2 = cfg.InitState.Omega2
 		pos = cfg.InitState.Pos
 		vel = cfg.InitState.Vel
+		applyDesign(cmd, cfg.ControllerParams)
+		actuators = cfg.Actuators
+		references = cfg.References
 	}
 
 	// Load config file if specified (overrides preset)
@@ -295,9 +443,27 @@ func runSimulation(cmd *cobra.Command, args []string) error {
 		if !cmd.Flags().Changed("target") {
 			target = cfg.ControllerParams.Target
 		}
+		applyDesign(cmd, cfg.ControllerParams)
+		applyEstimator(cmd, cfg.Estimator)
 		if cfg.Seed != 0 && !cmd.Flags().Changed("seed") {
 			seed = cfg.Seed
 		}
+		if cfg.ControlDt > 0 && !cmd.Flags().Changed("control-dt") {
+			controlDt = cfg.ControlDt
+		}
+		if cfg.ControlJitter > 0 && !cmd.Flags().Changed("control-jitter") {
+			controlJitter = cfg.ControlJitter
+		}
+		if cfg.Delay > 0 && !cmd.Flags().Changed("delay") {
+			delay = cfg.Delay
+		}
+		events = cfg.Events
+		if cfg.Actuators != nil {
+			actuators = cfg.Actuators
+		}
+		if cfg.References != nil {
+			references = cfg.References
+		}
 	}
 
 	st := storage.New(dataDir)
@@ -306,11 +472,24 @@ func runSimulation(cmd *cobra.Command, args []string) error {
 	}
 
 	registry := experiment.NewRegistry()
+	if modelFile != "" {
+		name, err := registry.LoadModelFile(modelFile)
+		if err != nil {
+			return err
+		}
+		if model != "" && model != name {
+			return fmt.Errorf("%s defines model %s, not %s", modelFile, name, model)
+		}
+		model = name
+	}
 
 	dyn, err := registry.GetModel(model)
 	if err != nil {
 		return err
 	}
+	if err := setModelParams(dyn, modelParams); err != nil {
+		return err
+	}
 
 	integ, err := registry.GetIntegrator(integrator)
 	if err != nil {
@@ -318,33 +497,32 @@ func runSimulation(cmd *cobra.Command, args []string) error {
 	}
 
 	controllerParams := map[string]float64{
-		"dim":    float64(dyn.ControlDim()),
-		"kp":     kp,
-		"ki":     ki,
-		"kd":     kd,
-		"target": target,
+		"dim":     float64(dyn.ControlDim()),
+		"kp":      kp,
+		"ki":      ki,
+		"kd":      kd,
+		"target":  target,
+		"horizon": float64(horizon),
+		"mpc_dt":  mpcDt,
+	}
+	if linearMPC {
+		controllerParams["linear"] = 1
+	}
+	design, err := controllerDesign(dyn)
+	if err != nil {
+		return err
 	}
-	ctrl, err := registry.GetController(controller, controllerParams)
+	ctrl, err := registry.GetControllerFor(controller, dyn, controllerParams, design)
 	if err != nil {
 		return err
 	}
+	if delay > 0 {
+		ctrl = control.NewDelayed(ctrl, delay)
+	}
 
-	var initState []float64
-	switch model {
-	case "cartpole":
-		initState = []float64{pos, vel, theta, omega}
-	case "nbody":
-		initState = makeNBodyInitialState(numBodies)
-	case "double_pendulum":
-		initState = []float64{theta, theta2, omega, omega2}
-	case "spring_mass":
-		initState = []float64{pos, vel}
-	case "spring_chain":
-		initState = []float64{pos, 0, 0, vel, 0, 0} // 3 masses
-	case "drone":
-		initState = []float64{0, 5, theta, 0, 0, omega} // x, y, theta, vx, vy, omega
-	default:
-		initState = []float64{theta, omega}
+	initState, err := initialState(registry, model, dyn)
+	if err != nil {
+		return err
 	}
 
 	cfg := experiment.Config{
@@ -356,32 +534,125 @@ func runSimulation(cmd *cobra.Command, args []string) error {
 		Duration:   duration,
 		Seed:       seed,
 		Params:     controllerParams,
+
+		SampleEvery:    sampleEvery,
+		SampleInterval: sampleDt,
+		OutputDt:       outputDt,
+
+		Adaptive:  adaptive,
+		Tolerance: tolerance,
+		MinDt:     minDt,
+		MaxDt:     maxDt,
+
+		ControlPeriod: controlDt,
+		ControlJitter: controlJitter,
 	}
 
 	exp := experiment.New(cfg)
-	metrics := registry.DefaultMetrics(model)
+	metrics := registry.MetricsFor(model, dyn, ctrl)
 	if err := exp.Setup(dyn, integ, ctrl, metrics); err != nil {
 		return err
 	}
+	chain, err := config.BuildActuators(dyn, actuators)
+	if err != nil {
+		return err
+	}
+	exp.SetActuators(chain)
+	estCfg := estimatorConfig()
+	if estCfg.Type != "" {
+		sensor, err := estCfg.Sensor(dyn)
+		if err != nil {
+			return err
+		}
+		est, err := registry.GetEstimator(estCfg.Type, dyn, sensor, estCfg.ProcessNoise, setpoint)
+		if err != nil {
+			return err
+		}
+		if est != nil {
+			exp.SetEstimator(sensor, est)
+		}
+	}
+	evs, err := config.BuildEvents(dyn, events)
+	if err != nil {
+		return err
+	}
+	for _, ev := range evs {
+		exp.GetSimulator().AddEvent(ev)
+	}
+	refs, err := config.BuildReferences(dyn, references)
+	if err != nil {
+		return err
+	}
+	if referenceFile != "" {
+		fromFile, err := config.ReferencesFromCSV(dyn, referenceFile)
+		if err != nil {
+			return err
+		}
+		for i, ref := range fromFile {
+			refs[i] = ref
+		}
+	}
+	if len(refs) > 0 {
+		if err := exp.Track(refs); err != nil {
+			return err
+		}
+	}
 
 	fmt.Printf("running %s simulation...\n", model)
 	start := time.Now()
 
-	result, err := exp.Run(context.Background())
-	if err != nil {
-		return err
+	var result *dynamo.Result
+	var runID string
+	if stream {
+		sink, err := st.NewRunSink(model, dt, duration, seed, integrator, controller)
+		if err != nil {
+			return err
+		}
+		if estCfg.Type != "" && estCfg.Type != "none" {
+			sink.RecordEstimates()
+		}
+		result, err = exp.Stream(context.Background(), sink)
+		if err != nil {
+			return err
+		}
+		if err := sink.Finish(result); err != nil {
+			return err
+		}
+		runID = sink.ID()
+	} else {
+		result, err = exp.Run(context.Background())
+		if err != nil {
+			return err
+		}
+		runID, err = st.Save(model, dt, duration, seed, integrator, controller, result)
+		if err != nil {
+			return err
+		}
 	}
 
 	elapsed := time.Since(start)
 
-	runID, err := st.Save(model, dt, duration, seed, integrator, controller, result)
-	if err != nil {
-		return err
-	}
-
 	fmt.Printf("completed in %v\n", elapsed)
 	fmt.Printf("run id: %s\n", runID)
-	fmt.Printf("steps: %d\n", len(result.States))
+	fmt.Printf("steps: %d\n", result.StepsTaken)
+	if adaptive {
+		fmt.Printf("rejected: %d\n", result.RejectedSteps)
+		fmt.Printf("dt range: [%g, %g]\n", result.MinStep, result.MaxStep)
+	}
+	fmt.Printf("derivative evals: %d\n", result.FuncEvals)
+	if len(result.Events) > 0 {
+		fmt.Println("\nevents:")
+		for _, ev := range result.Events {
+			suffix := ""
+			switch {
+			case ev.Terminal:
+				suffix = " (terminal)"
+			case ev.Reset:
+				suffix = " (reset)"
+			}
+			fmt.Printf("  %s at t=%.6f%s\n", ev.Name, ev.Time, suffix)
+		}
+	}
 	fmt.Println("\nmetrics:")
 	for name, val := range result.Metrics {
 		fmt.Printf("  %s: %.6f\n", name, val)
@@ -390,6 +661,141 @@ func runSimulation(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// initialState builds the starting state for a model. Models with angle or
+// position flags take them from the command line; all others start from the
+// catalog default.
+func initialState(registry *experiment.Registry, model string, dyn dynamo.System) ([]float64, error) {
+	switch model {
+	case "pendulum":
+		return []float64{theta, omega}, nil
+	case "cartpole":
+		return []float64{pos, vel, theta, omega}, nil
+	case "nbody":
+		return makeNBodyInitialState(numBodies), nil
+	case "double_pendulum":
+		return []float64{theta, theta2, omega, omega2}, nil
+	case "spring_mass":
+		return []float64{pos, vel}, nil
+	case "spring_chain":
+		return []float64{pos, 0, 0, vel, 0, 0}, nil // 3 masses
+	case "drone":
+		return []float64{0, 5, theta, 0, 0, omega}, nil // x, y, theta, vx, vy, omega
+	}
+	return registry.DefaultState(model, dyn)
+}
+
+// applyDesign takes the LQR, MPC and PID loop settings of a preset or
+// config file, except those given on the command line.
+func applyDesign(cmd *cobra.Command, cc config.ControllerConfig) {
+	vectors := []struct {
+		flag string
+		dst  *[]float64
+		v    []float64
+	}{
+		{"q", &lqrQ, cc.Q}, {"r", &lqrR, cc.R}, {"setpoint", &setpoint, cc.Setpoint},
+		{"u-min", &uMin, cc.UMin}, {"u-max", &uMax, cc.UMax},
+		{"x-min", &xMin, cc.XMin}, {"x-max", &xMax, cc.XMax},
+	}
+	for _, v := range vectors {
+		if v.v != nil && !cmd.Flags().Changed(v.flag) {
+			*v.dst = v.v
+		}
+	}
+	if cc.Horizon > 0 && !cmd.Flags().Changed("horizon") {
+		horizon = cc.Horizon
+	}
+	if cc.MPCDt > 0 && !cmd.Flags().Changed("mpc-dt") {
+		mpcDt = cc.MPCDt
+	}
+	if cc.Loops != nil {
+		pidLoops = cc.Loops
+	}
+}
+
+// applyEstimator takes the estimator settings of a config file, except
+// those given on the command line.
+func applyEstimator(cmd *cobra.Command, ec config.EstimatorConfig) {
+	if ec.Type != "" && !cmd.Flags().Changed("estimator") {
+		estimatorType = ec.Type
+	}
+	if ec.Observe != nil && !cmd.Flags().Changed("observe") {
+		observedVars = ec.Observe
+	}
+	if ec.Noise != nil && !cmd.Flags().Changed("sensor-noise") {
+		sensorNoise = ec.Noise
+	}
+	if ec.Bias != nil && !cmd.Flags().Changed("sensor-bias") {
+		sensorBias = ec.Bias
+	}
+	if ec.Dt > 0 && !cmd.Flags().Changed("sensor-dt") {
+		sensorDt = ec.Dt
+	}
+	if ec.ProcessNoise != nil && !cmd.Flags().Changed("process-noise") {
+		processNoise = ec.ProcessNoise
+	}
+}
+
+func estimatorConfig() config.EstimatorConfig {
+	return config.EstimatorConfig{
+		Type:         estimatorType,
+		Observe:      observedVars,
+		Noise:        sensorNoise,
+		Bias:         sensorBias,
+		Dt:           sensorDt,
+		ProcessNoise: processNoise,
+	}
+}
+
+func controllerDesign(dyn dynamo.System) (experiment.ControllerDesign, error) {
+	loops, err := config.BuildLoops(dyn, pidLoops)
+	if err != nil {
+		return experiment.ControllerDesign{}, err
+	}
+	return experiment.ControllerDesign{
+		Q: lqrQ, R: lqrR, Setpoint: setpoint,
+		UMin: uMin, UMax: uMax, XMin: xMin, XMax: xMax,
+		Loops: loops,
+	}, nil
+}
+
+// parseNamedValues parses the name=value entries of a repeatable flag.
+func parseNamedValues(flag string, kvs []string) (map[string]float64, error) {
+	values := make(map[string]float64, len(kvs))
+	for _, kv := range kvs {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --%s %q, want name=value", flag, kv)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s %q: %w", flag, kv, err)
+		}
+		values[name] = v
+	}
+	return values, nil
+}
+
+// setModelParams applies --param overrides to a freshly built model.
+func setModelParams(dyn dynamo.System, kvs []string) error {
+	if len(kvs) == 0 {
+		return nil
+	}
+	values, err := parseNamedValues("param", kvs)
+	if err != nil {
+		return err
+	}
+	conf, ok := dyn.(dynamo.Configurable)
+	if !ok {
+		return fmt.Errorf("model has no tunable parameters")
+	}
+	for name, v := range values {
+		if err := conf.SetParam(name, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func makeNBodyInitialState(n int) []float64 {
 	state := make([]float64, n*4)
 
@@ -456,6 +862,249 @@ func listRuns(cmd *cobra.Command, args []string) error {
 	return w.Flush()
 }
 
+func runEnsemble(cmd *cobra.Command, args []string) error {
+	registry := experiment.NewRegistry()
+	var model string
+	if len(args) > 0 {
+		model = args[0]
+	}
+	if modelFile != "" {
+		name, err := registry.LoadModelFile(modelFile)
+		if err != nil {
+			return err
+		}
+		if model != "" && model != name {
+			return fmt.Errorf("%s defines model %s, not %s", modelFile, name, model)
+		}
+		model = name
+	}
+	if model == "" {
+		return fmt.Errorf("ensemble needs a model name or --model-file")
+	}
+
+	dyn, err := registry.GetModel(model)
+	if err != nil {
+		return err
+	}
+	x0, err := initialState(registry, model, dyn)
+	if err != nil {
+		return err
+	}
+
+	controllerParams := map[string]float64{
+		"dim":     float64(dyn.ControlDim()),
+		"kp":      kp,
+		"ki":      ki,
+		"kd":      kd,
+		"target":  target,
+		"horizon": float64(horizon),
+		"mpc_dt":  mpcDt,
+	}
+	if linearMPC {
+		controllerParams["linear"] = 1
+	}
+	design, err := controllerDesign(dyn)
+	if err != nil {
+		return err
+	}
+	factory, err := registry.Factory(model, integrator, controller, controllerParams, design)
+	if err != nil {
+		return err
+	}
+
+	ens := dynamo.NewEnsemble(factory, numRuns)
+	ens.Workers = workers
+	ens.Perturb.StateStd = []float64{stateStd}
+	if len(paramStd) > 0 {
+		ens.Perturb.ParamStd, err = parseNamedValues("param-std", paramStd)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("running %d %s members...\n", numRuns, model)
+	start := time.Now()
+	result, err := ens.Run(context.Background(), dynamo.State(x0), dynamo.Config{Dt: dt, Duration: duration, Seed: ensembleSeed})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("completed in %v\n\n", time.Since(start))
+
+	stats := result.Stats
+	if stats == nil || len(stats.Times) == 0 {
+		return fmt.Errorf("members were recorded at different times, no statistics")
+	}
+	last := len(stats.Times) - 1
+	fmt.Printf("final state at t=%.3f:\n", stats.Times[last])
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "variable\tmean\tstd")
+	for _, p := range stats.Percentiles {
+		fmt.Fprintf(w, "\tp%g", p)
+	}
+	fmt.Fprintln(w)
+	for j, v := range dynamo.DescribeState(dyn) {
+		fmt.Fprintf(w, "%s\t%.6f\t%.6f", v.Name, stats.Mean[last][j], stats.Std[last][j])
+		for k := range stats.Percentiles {
+			fmt.Fprintf(w, "\t%.6f", stats.Envelopes[k][last][j])
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
+
+func listParams(cmd *cobra.Command, args []string) error {
+	registry := experiment.NewRegistry()
+	dyn, err := registry.GetModel(args[0])
+	if err != nil {
+		return err
+	}
+
+	p, ok := dyn.(dynamo.Parameterized)
+	if !ok || len(p.Params()) == 0 {
+		fmt.Printf("no parameters for model: %s\n", args[0])
+		return nil
+	}
+
+	fmt.Printf("%-12s  %10s  %10s  %10s  %-8s  %s\n", "name", "default", "min", "max", "unit", "description")
+	fmt.Println(strings.Repeat("-", 72))
+	for _, param := range p.Params() {
+		fmt.Printf("%-12s  %10g  %10g  %10g  %-8s  %s\n", param.Name, param.Default, param.Min, param.Max, param.Unit, param.Description)
+	}
+	return nil
+}
+
+func runLinearize(cmd *cobra.Command, args []string) error {
+	registry := experiment.NewRegistry()
+	var model string
+	if len(args) > 0 {
+		model = args[0]
+	}
+	if modelFile != "" {
+		name, err := registry.LoadModelFile(modelFile)
+		if err != nil {
+			return err
+		}
+		model = name
+	}
+	if model == "" {
+		return fmt.Errorf("linearize needs a model name or --model-file")
+	}
+
+	dyn, err := registry.GetModel(model)
+	if err != nil {
+		return err
+	}
+	if err := setModelParams(dyn, modelParams); err != nil {
+		return err
+	}
+	stateVars := dynamo.DescribeState(dyn)
+	n := len(stateVars)
+
+	var observed []int
+	for _, name := range observedVars {
+		idx := -1
+		for i, v := range stateVars {
+			if v.Name == name {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return fmt.Errorf("unknown state variable: %s (have %s)", name, strings.Join(dynamo.VarNames(stateVars), ", "))
+		}
+		observed = append(observed, idx)
+	}
+
+	var seeds []dynamo.State
+	for _, g := range guesses {
+		x, err := parseVector(g)
+		if err != nil {
+			return fmt.Errorf("invalid --guess %q: %w", g, err)
+		}
+		if len(x) != n {
+			return fmt.Errorf("--guess %q has %d values, model state has %d", g, len(x), n)
+		}
+		seeds = append(seeds, x)
+	}
+	if len(seeds) == 0 {
+		x0, err := registry.DefaultState(model, dyn)
+		if err != nil {
+			return err
+		}
+		seeds = analysis.SampleSeeds(x0, seedSpread, numSeeds, rand.New(rand.NewSource(linearizeSeed)))
+	}
+
+	u := dynamo.Control(operatingU)
+	equilibria := analysis.Equilibria(dyn, seeds, u, 0)
+	if len(equilibria) == 0 {
+		return fmt.Errorf("no equilibrium found from %d starting states", len(seeds))
+	}
+
+	names := dynamo.VarNames(stateVars)
+	for k, x := range equilibria {
+		lin, err := analysis.Linearize(dyn, x, u, 0, observed)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("equilibrium %d: %s\n", k+1, lin.Stability)
+		printVector("x", names, lin.X)
+		fmt.Println("\nA =")
+		printMatrix(names, names, lin.A)
+		if dyn.ControlDim() > 0 {
+			fmt.Println("\nB =")
+			printMatrix(names, dynamo.VarNames(dynamo.DescribeControl(dyn)), lin.B)
+		}
+		fmt.Println("\neigenvalues:")
+		for _, l := range lin.Eigenvalues {
+			if imag(l) == 0 {
+				fmt.Printf("  %12.6g\n", real(l))
+			} else {
+				fmt.Printf("  %12.6g %+.6gi\n", real(l), imag(l))
+			}
+		}
+		fmt.Printf("\ncontrollability rank: %d/%d\n", lin.Controllability, n)
+		fmt.Printf("observability rank:   %d/%d\n\n", lin.Observability, n)
+	}
+	return nil
+}
+
+// parseVector parses a comma separated list of numbers.
+func parseVector(s string) ([]float64, error) {
+	fields := strings.Split(s, ",")
+	v := make([]float64, len(fields))
+	for i, f := range fields {
+		x, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = x
+	}
+	return v, nil
+}
+
+func printVector(label string, names []string, v []float64) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "  %s\t%s\t\n", label, strings.Join(names, "\t"))
+	row := make([]string, len(v))
+	for i, x := range v {
+		row[i] = fmt.Sprintf("%.6g", x)
+	}
+	fmt.Fprintf(w, "\t%s\t\n", strings.Join(row, "\t"))
+	w.Flush()
+}
+
+func printMatrix(rows, cols []string, m [][]float64) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "\t%s\t\n", strings.Join(cols, "\t"))
+	for i, r := range m {
+		row := make([]string, len(r))
+		for j, x := range r {
+			row[j] = fmt.Sprintf("%.6g", x)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t\n", rows[i], strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
 func plotRun(cmd *cobra.Command, args []string) error {
 	runID := args[0]
 
@@ -478,6 +1127,7 @@ func plotRun(cmd *cobra.Command, args []string) error {
 	fmt.Printf("model: %s\n", meta.Model)
 	fmt.Printf("samples: %d\n\n", len(states))
 
+	vars := runVars(st, meta, runID, len(states[0]))
 	numVars := len(states[0])
 	maxPlots := 6
 	if numVars > maxPlots {
@@ -492,24 +1142,7 @@ func plotRun(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		caption := fmt.Sprintf("x%d vs time", varIdx)
-		if meta.Model == "pendulum" {
-			if varIdx == 0 {
-				caption = "theta (angle)"
-			} else if varIdx == 1 {
-				caption = "omega (angular velocity)"
-			}
-		} else if meta.Model == "cartpole" {
-			if varIdx == 0 {
-				caption = "cart position"
-			} else if varIdx == 1 {
-				caption = "cart velocity"
-			} else if varIdx == 2 {
-				caption = "pole angle"
-			} else if varIdx == 3 {
-				caption = "pole angular velocity"
-			}
-		}
+		caption := fmt.Sprintf("%s vs time", vars[varIdx].Label())
 
 		graph := asciigraph.Plot(data,
 			asciigraph.Height(10),
@@ -561,14 +1194,9 @@ func benchModel(cmd *cobra.Command, args []string) error {
 	durations := []float64{1.0, 5.0, 10.0}
 	dts := []float64{0.001, 0.01, 0.1}
 
-	var initState []float64
-	switch model {
-	case "cartpole":
-		initState = []float64{0.0, 0.0, 0.1, 0.0}
-	case "nbody":
-		initState = makeNBodyInitialState(3)
-	default:
-		initState = []float64{0.5, 0.0}
+	initState, err := registry.DefaultState(model, dyn)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("benchmarking %s\n\n", model)
@@ -619,21 +1247,32 @@ func analyzeRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	states, _, err := st.LoadStates(runID)
+	states, times, err := st.LoadStates(runID)
 	if err != nil {
 		return err
 	}
 
-	if len(states) == 0 || len(states[0]) == 0 {
+	if len(states) < 2 || len(states[0]) == 0 {
 		return fmt.Errorf("no data")
 	}
 
 	fmt.Printf("frequency analysis: %s\n", meta.ID)
 	fmt.Printf("model: %s\n\n", meta.Model)
 
-	data := make([]float64, len(states))
+	// the fft needs uniform samples; adaptive or event-terminated runs are
+	// resampled onto their mean spacing first
+	spacing := (times[len(times)-1] - times[0]) / float64(len(times)-1)
+	if spacing <= 0 {
+		return fmt.Errorf("no data")
+	}
+	series := make([]dynamo.State, len(states))
 	for i := range states {
-		data[i] = states[i][0]
+		series[i] = dynamo.State{states[i][0]}
+	}
+	_, series = dynamo.Resample(times, series, spacing)
+	data := make([]float64, len(series))
+	for i := range series {
+		data[i] = series[i][0]
 	}
 
 	n := 1
@@ -650,7 +1289,7 @@ func analyzeRun(cmd *cobra.Command, args []string) error {
 	graph := asciigraph.Plot(plotData,
 		asciigraph.Height(15),
 		asciigraph.Width(80),
-		asciigraph.Caption("power spectrum (x0)"),
+		asciigraph.Caption(fmt.Sprintf("power spectrum (%s)", runVars(st, meta, runID, len(states[0]))[0].Name)),
 	)
 	fmt.Println(graph)
 	fmt.Println()
@@ -664,7 +1303,7 @@ func analyzeRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	freq := float64(maxIdx) / (meta.Duration)
+	freq := float64(maxIdx) / (float64(n) * spacing)
 	fmt.Printf("dominant frequency: %.3f hz\n", freq)
 	if freq > 0 {
 		fmt.Printf("period: %.3f s\n", 1.0/freq)
@@ -687,6 +1326,9 @@ func runLive(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := dynamo.CheckIntegrator(dyn, integ); err != nil {
+		return err
+	}
 
 	controllerParams := map[string]float64{
 		"dim":    float64(dyn.ControlDim()),
@@ -695,27 +1337,18 @@ func runLive(cmd *cobra.Command, args []string) error {
 		"kd":     kd,
 		"target": target,
 	}
-	ctrl, err := registry.GetController(controller, controllerParams)
+	design, err := controllerDesign(dyn)
+	if err != nil {
+		return err
+	}
+	ctrl, err := registry.GetControllerFor(controller, dyn, controllerParams, design)
 	if err != nil {
 		return err
 	}
 
-	var initState []float64
-	switch model {
-	case "cartpole":
-		initState = []float64{pos, vel, theta, omega}
-	case "double_pendulum":
-		initState = []float64{theta, theta2, omega, omega2}
-	case "spring_mass":
-		initState = []float64{pos, vel}
-	case "spring_chain":
-		initState = []float64{pos, 0, 0, vel, 0, 0}
-	case "drone":
-		initState = []float64{0, 5, theta, 0, 0, omega}
-	case "nbody":
-		initState = makeNBodyInitialState(numBodies)
-	default:
-		initState = []float64{theta, omega}
+	initState, err := initialState(registry, model, dyn)
+	if err != nil {
+		return err
 	}
 
 	// Initialize TUI Model
@@ -747,20 +1380,26 @@ func phasePlot(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no data to plot")
 	}
 
-	if len(states[0]) <= xAxis || len(states[0]) <= yAxis {
-		return fmt.Errorf("state dimension too small for selected axes")
+	vars := runVars(st, meta, runID, len(states[0]))
+	xIdx, err := resolveAxis(vars, xAxis)
+	if err != nil {
+		return err
+	}
+	yIdx, err := resolveAxis(vars, yAxis)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("phase space plot: %s\n", meta.ID)
 	fmt.Printf("model: %s\n", meta.Model)
-	fmt.Printf("x-axis: x%d, y-axis: x%d\n\n", xAxis, yAxis)
+	fmt.Printf("x-axis: %s, y-axis: %s\n\n", vars[xIdx].Label(), vars[yIdx].Label())
 
 	// Extract data for phase plot
 	xData := make([]float64, len(states))
 	yData := make([]float64, len(states))
 	for i := range states {
-		xData[i] = states[i][xAxis]
-		yData[i] = states[i][yAxis]
+		xData[i] = states[i][xIdx]
+		yData[i] = states[i][yIdx]
 	}
 
 	// Find bounds
@@ -854,6 +1493,39 @@ func phasePlot(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runVars labels the n columns loaded from a stored run, taking names from
+// the CSV header and units from the run metadata.
+func runVars(st *storage.Store, meta *storage.RunMetadata, runID string, n int) []dynamo.Variable {
+	vars := dynamo.GenericVars("x", n)
+	if names, err := st.LoadColumns(runID); err == nil {
+		for i := 0; i < n && i < len(names); i++ {
+			vars[i].Name = names[i]
+		}
+	}
+	units := append(append([]string{}, meta.StateUnits...), meta.ControlUnits...)
+	for i := 0; i < n && i < len(units); i++ {
+		vars[i].Unit = units[i]
+	}
+	return vars
+}
+
+// resolveAxis maps a phase plot axis, given as a column index or variable
+// name, to a column index.
+func resolveAxis(vars []dynamo.Variable, axis string) (int, error) {
+	if idx, err := strconv.Atoi(axis); err == nil {
+		if idx < 0 || idx >= len(vars) {
+			return 0, fmt.Errorf("axis %d out of range: run has %d columns", idx, len(vars))
+		}
+		return idx, nil
+	}
+	for i, v := range vars {
+		if v.Name == axis {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown variable: %s", axis)
+}
+
 func exportCSV(cmd *cobra.Command, args []string) error {
 	runID := args[0]
 
@@ -872,9 +1544,11 @@ func exportCSV(cmd *cobra.Command, args []string) error {
 
 	// Header
 	header := []string{"time"}
-	for i := range states[0] {
-		header = append(header, fmt.Sprintf("x%d", i))
+	columns, err := st.LoadColumns(runID)
+	if err != nil || len(columns) != len(states[0]) {
+		columns = dynamo.VarNames(dynamo.GenericVars("x", len(states[0])))
 	}
+	header = append(header, columns...)
 	if err := w.Write(header); err != nil {
 		return err
 	}
@@ -903,8 +1577,10 @@ func compareIntegrators(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	initState := []float64{theta, 0}
+	var initState []float64
 	switch model {
+	case "pendulum":
+		initState = []float64{theta, 0}
 	case "double_pendulum":
 		initState = []float64{theta, theta, 0, 0}
 	case "cartpole":
@@ -923,10 +1599,15 @@ func compareIntegrators(cmd *cobra.Command, args []string) error {
 		initState = []float64{0, 5, theta, 0, 0, 0}
 	case "spring_mass":
 		initState = []float64{1.0, 0}
+	default:
+		initState, err = registry.DefaultState(model, dyn)
+		if err != nil {
+			return err
+		}
 	}
 
 	fmt.Printf("comparing integrators for %s (dt=%.4f, duration=%.1fs)\n\n", model, dt, duration)
-	fmt.Printf("%-12s  %-12s  %-12s  %-12s\n", "integrator", "final_x0", "energy_drift", "time_ms")
+	fmt.Printf("%-12s  %-12s  %-12s  %-12s\n", "integrator", "final_"+dynamo.DescribeState(dyn)[0].Name, "energy_drift", "time_ms")
 	fmt.Println(strings.Repeat("-", 52))
 
 	for _, intName := range integrators {
@@ -980,9 +1661,30 @@ func exportJSON(cmd *cobra.Command, args []string) error {
 		Times:   times,
 		Metrics: meta.Metrics,
 	}
+	// states.csv stores controls after the state columns; split them back
+	// out when the metadata says where the state ends.
+	nx := len(meta.StateNames)
 	for i, s := range states {
+		if nx > 0 && nx < len(s) {
+			result.States[i] = s[:nx]
+			result.Controls = append(result.Controls, s[nx:])
+			continue
+		}
 		result.States[i] = s
 	}
+	for i, name := range meta.StateNames {
+		result.StateVars = append(result.StateVars, dynamo.Variable{Name: name, Unit: unitAt(meta.StateUnits, i)})
+	}
+	for i, name := range meta.ControlNames {
+		result.ControlVars = append(result.ControlVars, dynamo.Variable{Name: name, Unit: unitAt(meta.ControlUnits, i)})
+	}
 
 	return storage.ExportJSONStdout(meta.ID, meta.Model, meta.Integrator, meta.Controller, meta.Dt, meta.Duration, result)
 }
+
+func unitAt(units []string, i int) string {
+	if i < len(units) {
+		return units[i]
+	}
+	return ""
+}
@@ -17,7 +17,7 @@ type BifurcationPoint struct {
 // - dyn: dynamics with Configurable interface
 // - integ: integrator to use
 // - paramName: name of parameter to sweep
-// - paramMin, paramMax: range to sweep
+// - paramMin, paramMax: range to sweep; values the model rejects are skipped
 // - paramSteps: number of parameter values to test
 // - stateIndex: which state variable to record
 // - dt, transient, record: timing parameters
@@ -36,6 +36,8 @@ func BifurcationDiagram(
 		return nil
 	}
 
+	original, hasOriginal := tunable.GetParams()[paramName]
+
 	results := make([]BifurcationPoint, 0, paramSteps)
 	if paramSteps <= 1 {
 		paramSteps = 2 // Prevent division by zero
@@ -46,7 +48,9 @@ func BifurcationDiagram(
 
 	for i := 0; i < paramSteps; i++ {
 		param := paramMin + float64(i)*paramStep
-		tunable.SetParam(paramName, param)
+		if err := tunable.SetParam(paramName, param); err != nil {
+			continue
+		}
 
 		// Reset state
 		x := make(dynamo.State, len(x0))
@@ -85,8 +89,8 @@ func BifurcationDiagram(
 	}
 
 	// Restore original parameter
-	if len(results) > 0 {
-		tunable.SetParam(paramName, paramMin)
+	if hasOriginal {
+		tunable.SetParam(paramName, original)
 	}
 
 	return results
@@ -4,6 +4,10 @@
 //
 //   - [LyapunovExponent]: largest Lyapunov exponent via trajectory separation
 //   - [LyapunovSpectrum]: full Lyapunov spectrum for multi-dimensional systems
+//   - [LyapunovSpectrumQR]: Lyapunov spectrum from the tangent dynamics
+//   - [FindEquilibrium], [Equilibria]: rest points by damped Newton iteration
+//   - [Linearize]: A/B matrices, eigenvalues, stability and
+//     controllability/observability ranks about an operating point
 //   - [BifurcationDiagram]: parameter sweep for bifurcation analysis
 //   - [GeneratePhasePortrait]: 2D phase space trajectories
 //   - [GeneratePoincareSection]: stroboscopic section of phase space
@@ -0,0 +1,169 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// EquilibriumTol is the residual |f(x, u, t)| below which a state counts as
+// an equilibrium.
+const EquilibriumTol = 1e-10
+
+const equilibriumMaxIter = 100
+
+// FindEquilibrium solves f(x, u, t) = 0 starting from seed. The Newton step
+// is damped in the Levenberg-Marquardt way, so it also converges for
+// models whose Jacobian is singular at rest, such as a cart with no
+// restoring force on its position.
+func FindEquilibrium(dyn dynamo.System, seed dynamo.State, u dynamo.Control, t float64) (dynamo.State, error) {
+	x := seed.Clone()
+	n := len(x)
+	u = padControl(dyn, u)
+	f := dyn.Derive(x, u, t)
+	res := norm(f)
+	lambda := 1e-6
+	for iter := 0; iter < equilibriumMaxIter; iter++ {
+		if res < EquilibriumTol {
+			return x, nil
+		}
+		jac := linalg.Matrix(dynamo.StateJacobian(dyn, x, u, t))
+		jt := jac.T()
+		g := jt.MulVec(f)
+		improved := false
+		for try := 0; try < 20 && !improved; try++ {
+			// (JᵀJ + λ diag(JᵀJ) + λI) dx = -Jᵀf
+			a := jt.Mul(jac)
+			for i := 0; i < n; i++ {
+				a[i][i] += lambda * (a[i][i] + 1)
+			}
+			lu, err := linalg.Factor(a)
+			if err != nil {
+				lambda *= 10
+				continue
+			}
+			dx := lu.Solve(g)
+			xn := x.Clone()
+			for i := range xn {
+				xn[i] -= dx[i]
+			}
+			fn := dyn.Derive(xn, u, t)
+			if rn := norm(fn); rn < res {
+				x, f, res = xn, fn, rn
+				lambda = math.Max(lambda/10, 1e-12)
+				improved = true
+			} else {
+				lambda *= 10
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	if res < EquilibriumTol {
+		return x, nil
+	}
+	return nil, fmt.Errorf("no equilibrium near %v: residual %.3g", []float64(seed), res)
+}
+
+// Equilibria runs FindEquilibrium from every seed and returns the distinct
+// equilibria found, in the order first reached. Components the dynamics do
+// not depend on, such as the position of a free cart, are ignored when
+// comparing, so a continuum of equilibria is reported once. Angles (unit
+// "rad") are compared modulo 2π.
+func Equilibria(dyn dynamo.System, seeds []dynamo.State, u dynamo.Control, t float64) []dynamo.State {
+	var found []dynamo.State
+	angles := angleComponents(dyn)
+	for _, seed := range seeds {
+		x, err := FindEquilibrium(dyn, seed, u, t)
+		if err != nil {
+			continue
+		}
+		relevant := dependentComponents(dynamo.StateJacobian(dyn, x, padControl(dyn, u), t))
+		duplicate := false
+		for _, y := range found {
+			if distance(x, y, relevant, angles) < 1e-6*math.Max(1, norm(y)) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			found = append(found, x)
+		}
+	}
+	return found
+}
+
+// SampleSeeds returns center followed by count-1 states drawn uniformly
+// from the box center ± scale.
+func SampleSeeds(center dynamo.State, scale float64, count int, rng *rand.Rand) []dynamo.State {
+	seeds := []dynamo.State{center.Clone()}
+	for len(seeds) < count {
+		x := center.Clone()
+		for i := range x {
+			x[i] += scale * (2*rng.Float64() - 1)
+		}
+		seeds = append(seeds, x)
+	}
+	return seeds
+}
+
+// padControl extends u with zeros to the model's control dimension.
+func padControl(dyn dynamo.System, u dynamo.Control) dynamo.Control {
+	uf := make(dynamo.Control, max(dyn.ControlDim(), len(u)))
+	copy(uf, u)
+	return uf
+}
+
+func norm(v []float64) float64 {
+	s := 0.0
+	for _, x := range v {
+		s += x * x
+	}
+	return math.Sqrt(s)
+}
+
+// angleComponents marks the state components measured in radians.
+func angleComponents(dyn dynamo.System) []bool {
+	vars := dynamo.DescribeState(dyn)
+	angle := make([]bool, len(vars))
+	for i, v := range vars {
+		angle[i] = v.Unit == "rad"
+	}
+	return angle
+}
+
+// dependentComponents marks the state components with a nonzero column in
+// the Jacobian.
+func dependentComponents(jac [][]float64) []bool {
+	var dep []bool
+	for _, row := range jac {
+		if dep == nil {
+			dep = make([]bool, len(row))
+		}
+		for j, v := range row {
+			dep[j] = dep[j] || math.Abs(v) > 1e-12
+		}
+	}
+	return dep
+}
+
+// distance is the Euclidean distance between a and b over the components
+// marked in mask.
+func distance(a, b []float64, mask, angle []bool) float64 {
+	s := 0.0
+	for i := range a {
+		if !mask[i] {
+			continue
+		}
+		d := a[i] - b[i]
+		if angle[i] {
+			d = math.Remainder(d, 2*math.Pi)
+		}
+		s += d * d
+	}
+	return math.Sqrt(s)
+}
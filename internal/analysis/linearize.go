@@ -0,0 +1,135 @@
+package analysis
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// Stability classifies an equilibrium by the eigenvalues of its
+// linearization.
+type Stability string
+
+const (
+	StableNode    Stability = "stable node"
+	StableFocus   Stability = "stable focus"
+	UnstableNode  Stability = "unstable node"
+	UnstableFocus Stability = "unstable focus"
+	Saddle        Stability = "saddle"
+	Center        Stability = "center"
+	// Marginal covers the remaining cases with eigenvalues on the imaginary
+	// axis, where the linearization alone cannot decide stability.
+	Marginal Stability = "marginal"
+)
+
+// Stable reports whether the equilibrium is asymptotically stable.
+func (s Stability) Stable() bool { return s == StableNode || s == StableFocus }
+
+// Linearization is the model x' = A δx + B δu about an operating point,
+// observed through y = C δx.
+type Linearization struct {
+	X dynamo.State
+	U dynamo.Control
+
+	A, B, C     linalg.Matrix
+	Eigenvalues []complex128
+	Stability   Stability
+
+	// Ranks of the controllability matrix [B AB … Aⁿ⁻¹B] and the
+	// observability matrix [C; CA; …; CAⁿ⁻¹]. Full rank is len(X).
+	Controllability int
+	Observability   int
+}
+
+// Linearize computes A = ∂f/∂x and B = ∂f/∂u at (x, u, t) and analyses
+// them. observed lists the state indices measured by C; nil means the
+// whole state.
+func Linearize(dyn dynamo.System, x dynamo.State, u dynamo.Control, t float64, observed []int) (*Linearization, error) {
+	n := len(x)
+	uf := padControl(dyn, u)
+
+	lin := &Linearization{
+		X: x.Clone(),
+		U: uf,
+		A: linalg.Matrix(dynamo.StateJacobian(dyn, x, uf, t)),
+		B: linalg.Matrix(dynamo.ControlJacobian(dyn, x, uf, t)),
+	}
+	if observed == nil {
+		lin.C = linalg.Identity(n)
+	} else {
+		lin.C = linalg.New(len(observed), n)
+		for i, idx := range observed {
+			lin.C[i][idx] = 1
+		}
+	}
+
+	eig, err := linalg.Eigenvalues(lin.A)
+	if err != nil {
+		return nil, err
+	}
+	lin.Eigenvalues = eig
+	lin.Stability = Classify(eig)
+	lin.Controllability = linalg.Rank(controllabilityMatrix(lin.A, lin.B))
+	lin.Observability = linalg.Rank(controllabilityMatrix(lin.A.T(), lin.C.T()))
+	return lin, nil
+}
+
+// Classify names the equilibrium type from the eigenvalues of A. Real parts
+// within a small relative tolerance of zero count as zero.
+func Classify(eig []complex128) Stability {
+	scale := 1.0
+	for _, l := range eig {
+		scale = math.Max(scale, cmplx.Abs(l))
+	}
+	tol := 1e-9 * scale
+
+	var neg, pos, zero int
+	oscillatory := false
+	for _, l := range eig {
+		switch {
+		case real(l) < -tol:
+			neg++
+		case real(l) > tol:
+			pos++
+		default:
+			zero++
+		}
+		if math.Abs(imag(l)) > tol {
+			oscillatory = true
+		}
+	}
+	// Any growing mode makes the equilibrium unstable, whatever the zero
+	// eigenvalues do.
+	switch {
+	case pos > 0 && neg > 0:
+		return Saddle
+	case pos > 0 && oscillatory:
+		return UnstableFocus
+	case pos > 0:
+		return UnstableNode
+	case zero > 0 && neg == 0 && oscillatory:
+		return Center
+	case zero > 0:
+		return Marginal
+	case oscillatory:
+		return StableFocus
+	}
+	return StableNode
+}
+
+// controllabilityMatrix returns [B AB … Aⁿ⁻¹B]. The observability matrix
+// is the same construction on Aᵀ and Cᵀ.
+func controllabilityMatrix(a, b linalg.Matrix) linalg.Matrix {
+	n, m := a.Rows(), b.Cols()
+	out := linalg.New(n, n*m)
+	block := b
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			copy(out[i][k*m:(k+1)*m], block[i])
+		}
+		block = a.Mul(block)
+	}
+	return out
+}
@@ -148,3 +148,95 @@ func lyapunovForPerturbation(
 	}
 	return sumLog / (float64(count) * dt)
 }
+
+// LyapunovSpectrumQR computes the full Lyapunov spectrum, largest first, by
+// integrating the variational equation Φ' = J(x)Φ alongside the trajectory
+// and re-orthonormalizing Φ after every step. J comes from
+// dynamo.StateJacobian, so models with analytic or dual-number Jacobians
+// need no perturbation size.
+func LyapunovSpectrumQR(
+	dyn dynamo.System,
+	integ dynamo.Integrator,
+	x0 dynamo.State,
+	dt, duration float64,
+) []float64 {
+	n := len(x0)
+	if n == 0 || duration <= 0 {
+		return nil
+	}
+
+	// Combined state: x followed by the columns of Φ, starting from I.
+	y := make(dynamo.State, n+n*n)
+	copy(y, x0)
+	for i := 0; i < n; i++ {
+		y[n+i*n+i] = 1
+	}
+	sys := &variational{dyn: dyn, n: n}
+	ctrl := make(dynamo.Control, dyn.ControlDim())
+
+	sums := make([]float64, n)
+	t := 0.0
+	for t < duration {
+		y = integ.Step(sys, y, ctrl, t, dt)
+		t += dt
+		// Modified Gram-Schmidt on the columns of Φ.
+		for j := 0; j < n; j++ {
+			cj := y[n+j*n : n+(j+1)*n]
+			for k := 0; k < j; k++ {
+				ck := y[n+k*n : n+(k+1)*n]
+				dot := 0.0
+				for i := range cj {
+					dot += cj[i] * ck[i]
+				}
+				for i := range cj {
+					cj[i] -= dot * ck[i]
+				}
+			}
+			norm := 0.0
+			for _, v := range cj {
+				norm += v * v
+			}
+			norm = math.Sqrt(norm)
+			if norm == 0 {
+				continue
+			}
+			sums[j] += math.Log(norm)
+			for i := range cj {
+				cj[i] /= norm
+			}
+		}
+	}
+
+	for j := range sums {
+		sums[j] /= t
+	}
+	return sums
+}
+
+// variational extends a system with its tangent dynamics.
+type variational struct {
+	dyn dynamo.System
+	n   int
+}
+
+func (v *variational) StateDim() int   { return v.n + v.n*v.n }
+func (v *variational) ControlDim() int { return v.dyn.ControlDim() }
+
+func (v *variational) Derive(y dynamo.State, u dynamo.Control, t float64) dynamo.State {
+	n := v.n
+	x := y[:n]
+	out := make(dynamo.State, len(y))
+	copy(out, v.dyn.Derive(x, u, t))
+	jac := dynamo.StateJacobian(v.dyn, x, u, t)
+	for j := 0; j < n; j++ {
+		col := y[n+j*n : n+(j+1)*n]
+		for i := 0; i < n; i++ {
+			s := 0.0
+			for k := 0; k < n; k++ {
+				s += jac[i][k] * col[k]
+			}
+			out[n+j*n+i] = s
+		}
+	}
+	return out
+}
@@ -0,0 +1,14 @@
+// Package autodiff implements forward-mode automatic differentiation with
+// dual numbers.
+//
+// A [Dual] carries a value and its derivative along one seed direction.
+// Evaluating a function on duals whose Du parts are the seed gives the
+// directional derivative exactly, with no step size to choose:
+//
+//	x := autodiff.Var(0.3)          // d/dx
+//	y := autodiff.Sin(x).Mul(x)     // y.Re = x sin x, y.Du = sin x + x cos x
+//
+// Models implement dynamo.Differentiable by writing their right-hand side
+// once more over duals; dynamo.StateJacobian and dynamo.ControlJacobian then
+// seed one column at a time.
+package autodiff
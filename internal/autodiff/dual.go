@@ -0,0 +1,112 @@
+package autodiff
+
+import "math"
+
+// Dual is a + bε with ε² = 0: a value and its derivative.
+type Dual struct {
+	Re, Du float64
+}
+
+// Const returns a constant, whose derivative is zero.
+func Const(c float64) Dual { return Dual{Re: c} }
+
+// Var returns the independent variable the derivative is taken against.
+func Var(x float64) Dual { return Dual{Re: x, Du: 1} }
+
+// Consts lifts a vector of constants.
+func Consts(xs []float64) []Dual {
+	ds := make([]Dual, len(xs))
+	for i, x := range xs {
+		ds[i] = Const(x)
+	}
+	return ds
+}
+
+// Values returns the Re parts of ds.
+func Values(ds []Dual) []float64 {
+	xs := make([]float64, len(ds))
+	for i, d := range ds {
+		xs[i] = d.Re
+	}
+	return xs
+}
+
+// Derivatives returns the Du parts of ds.
+func Derivatives(ds []Dual) []float64 {
+	xs := make([]float64, len(ds))
+	for i, d := range ds {
+		xs[i] = d.Du
+	}
+	return xs
+}
+
+func (a Dual) Add(b Dual) Dual { return Dual{a.Re + b.Re, a.Du + b.Du} }
+func (a Dual) Sub(b Dual) Dual { return Dual{a.Re - b.Re, a.Du - b.Du} }
+func (a Dual) Mul(b Dual) Dual { return Dual{a.Re * b.Re, a.Du*b.Re + a.Re*b.Du} }
+func (a Dual) Neg() Dual       { return Dual{-a.Re, -a.Du} }
+
+func (a Dual) Div(b Dual) Dual {
+	return Dual{a.Re / b.Re, (a.Du*b.Re - a.Re*b.Du) / (b.Re * b.Re)}
+}
+
+// AddC returns a + c for a constant c.
+func (a Dual) AddC(c float64) Dual { return Dual{a.Re + c, a.Du} }
+
+// Scale returns c·a for a constant c.
+func (a Dual) Scale(c float64) Dual { return Dual{c * a.Re, c * a.Du} }
+
+// chain applies f with derivative df at a.Re.
+func chain(a Dual, f, df float64) Dual { return Dual{f, df * a.Du} }
+
+func Sin(a Dual) Dual  { return chain(a, math.Sin(a.Re), math.Cos(a.Re)) }
+func Cos(a Dual) Dual  { return chain(a, math.Cos(a.Re), -math.Sin(a.Re)) }
+func Tan(a Dual) Dual  { c := math.Cos(a.Re); return chain(a, math.Tan(a.Re), 1/(c*c)) }
+func Exp(a Dual) Dual  { e := math.Exp(a.Re); return chain(a, e, e) }
+func Log(a Dual) Dual  { return chain(a, math.Log(a.Re), 1/a.Re) }
+func Sqrt(a Dual) Dual { s := math.Sqrt(a.Re); return chain(a, s, 0.5/s) }
+func Tanh(a Dual) Dual { th := math.Tanh(a.Re); return chain(a, th, 1-th*th) }
+func Atan(a Dual) Dual { return chain(a, math.Atan(a.Re), 1/(1+a.Re*a.Re)) }
+
+// Pow returns a^p for a constant exponent p.
+func Pow(a Dual, p float64) Dual {
+	return chain(a, math.Pow(a.Re, p), p*math.Pow(a.Re, p-1))
+}
+
+// Abs returns |a|, taking the derivative at zero as zero.
+func Abs(a Dual) Dual {
+	switch {
+	case a.Re > 0:
+		return a
+	case a.Re < 0:
+		return a.Neg()
+	}
+	return Dual{}
+}
+
+// Max returns the larger of a and b together with its derivative.
+func Max(a, b Dual) Dual {
+	if a.Re >= b.Re {
+		return a
+	}
+	return b
+}
+
+// Min returns the smaller of a and b together with its derivative.
+func Min(a, b Dual) Dual {
+	if a.Re <= b.Re {
+		return a
+	}
+	return b
+}
+
+// Atan2 returns the angle of the point (x, y).
+func Atan2(y, x Dual) Dual {
+	r2 := x.Re*x.Re + y.Re*y.Re
+	return Dual{math.Atan2(y.Re, x.Re), (x.Re*y.Du - y.Re*x.Du) / r2}
+}
+
+// Hypot returns sqrt(a² + b²).
+func Hypot(a, b Dual) Dual {
+	h := math.Hypot(a.Re, b.Re)
+	return Dual{h, (a.Re*a.Du + b.Re*b.Du) / h}
+}
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"time"
 
-	"github.com/san-kum/dynsim/internal/experiment"
+	"github.com/san-kum/dynsim/internal/config"
+	"github.com/san-kum/dynsim/internal/control"
 	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/experiment"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,14 +24,46 @@ type Scenario struct {
 
 // ScenarioStep is a single step in a scenario
 type ScenarioStep struct {
-	Model      string             `yaml:"model"`
-	Integrator string             `yaml:"integrator"`
-	Controller string             `yaml:"controller"`
-	Duration   float64            `yaml:"duration"`
-	Dt         float64            `yaml:"dt"`
-	InitState  []float64          `yaml:"init_state"`
-	Params     map[string]float64 `yaml:"params"`
-	SaveAs     string             `yaml:"save_as"`
+	Model string `yaml:"model"`
+	// ModelFile loads the model from a YAML definition, relative to the
+	// scenario file. Model may then be left empty.
+	ModelFile  string  `yaml:"model_file"`
+	Integrator string  `yaml:"integrator"`
+	Controller string  `yaml:"controller"`
+	Duration   float64 `yaml:"duration"`
+	Dt         float64 `yaml:"dt"`
+	// ControlDt and ControlJitter sample the controller, see
+	// dynamo.Config.ControlPeriod.
+	ControlDt     float64 `yaml:"control_dt"`
+	ControlJitter float64 `yaml:"control_jitter"`
+	// Delay feeds the controller measurements this many seconds old.
+	Delay     float64            `yaml:"delay"`
+	InitState []float64          `yaml:"init_state"`
+	Params    map[string]float64 `yaml:"params"`
+	// Q and R are LQR and MPC weights: with either set, an lqr controller
+	// is designed for the model about Setpoint (default: the origin). The
+	// bounds apply to mpc, which reads horizon, mpc_dt, max_iter and
+	// linear from Params.
+	Q        []float64 `yaml:"q"`
+	R        []float64 `yaml:"r"`
+	Setpoint []float64 `yaml:"setpoint"`
+	UMin     []float64 `yaml:"u_min"`
+	UMax     []float64 `yaml:"u_max"`
+	XMin     []float64 `yaml:"x_min"`
+	XMax     []float64 `yaml:"x_max"`
+	// Loops replace the single pid loop on the first state.
+	Loops []config.PIDLoopConfig `yaml:"loops"`
+	// Actuators shape the controller output before it reaches the model,
+	// in order.
+	Actuators []config.ActuatorConfig `yaml:"actuators"`
+	Events    []config.EventConfig    `yaml:"events"`
+	// Estimator, when set, feeds the controller a state estimate built
+	// from a noisy sensor instead of the true state.
+	Estimator *config.EstimatorConfig `yaml:"estimator"`
+	// References are setpoints the controller tracks over time; csv files
+	// are relative to the scenario.
+	References []config.ReferenceConfig `yaml:"references"`
+	SaveAs     string                   `yaml:"save_as"`
 }
 
 // LoadScenario loads a scenario from a YAML file
@@ -42,6 +77,16 @@ func LoadScenario(path string) (*Scenario, error) {
 	if err := yaml.Unmarshal(data, &scenario); err != nil {
 		return nil, err
 	}
+	for i, step := range scenario.Steps {
+		if step.ModelFile != "" && !filepath.IsAbs(step.ModelFile) {
+			scenario.Steps[i].ModelFile = filepath.Join(filepath.Dir(path), step.ModelFile)
+		}
+		for j, ref := range step.References {
+			if ref.File != "" && !filepath.IsAbs(ref.File) {
+				scenario.Steps[i].References[j].File = filepath.Join(filepath.Dir(path), ref.File)
+			}
+		}
+	}
 
 	return &scenario, nil
 }
@@ -51,6 +96,13 @@ func RunScenario(ctx context.Context, scenario *Scenario, registry *experiment.R
 	results := make([]dynamo.Result, 0, len(scenario.Steps))
 
 	for i, step := range scenario.Steps {
+		if step.ModelFile != "" {
+			name, err := registry.LoadModelFile(step.ModelFile)
+			if err != nil {
+				return results, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			step.Model = name
+		}
 		fmt.Printf("Running step %d/%d: %s\n", i+1, len(scenario.Steps), step.Model)
 
 		dyn, err := registry.GetModel(step.Model)
@@ -58,11 +110,8 @@ func RunScenario(ctx context.Context, scenario *Scenario, registry *experiment.R
 			return results, fmt.Errorf("step %d: %w", i+1, err)
 		}
 
-		// Apply params if tunable
-		if t, ok := dyn.(dynamo.Configurable); ok {
-			for k, v := range step.Params {
-				t.SetParam(k, v)
-			}
+		if err := applyModelParams(dyn, step.Params); err != nil {
+			return results, fmt.Errorf("step %d: %w", i+1, err)
 		}
 
 		integ, err := registry.GetIntegrator(step.Integrator)
@@ -70,24 +119,79 @@ func RunScenario(ctx context.Context, scenario *Scenario, registry *experiment.R
 			return results, fmt.Errorf("step %d: %w", i+1, err)
 		}
 
-		ctrl, err := registry.GetController(step.Controller, step.Params)
+		loops, err := config.BuildLoops(dyn, step.Loops)
 		if err != nil {
 			return results, fmt.Errorf("step %d: %w", i+1, err)
 		}
+		ctrl, err := registry.GetControllerFor(step.Controller, dyn, step.Params, experiment.ControllerDesign{
+			Q: step.Q, R: step.R, Setpoint: step.Setpoint,
+			UMin: step.UMin, UMax: step.UMax, XMin: step.XMin, XMax: step.XMax,
+			Loops: loops,
+		})
+		if err != nil {
+			return results, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if step.Delay > 0 {
+			ctrl = control.NewDelayed(ctrl, step.Delay)
+		}
+
+		initState := step.InitState
+		if len(initState) == 0 {
+			if initState, err = registry.DefaultState(step.Model, dyn); err != nil {
+				return results, fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
 
 		cfg := experiment.Config{
 			Model:      step.Model,
 			Integrator: step.Integrator,
 			Controller: step.Controller,
-			InitState:  step.InitState,
+			InitState:  initState,
 			Dt:         step.Dt,
 			Duration:   step.Duration,
+
+			ControlPeriod: step.ControlDt,
+			ControlJitter: step.ControlJitter,
 		}
 
 		exp := experiment.New(cfg)
-		if err := exp.Setup(dyn, integ, ctrl, nil); err != nil {
+		if err := exp.Setup(dyn, integ, ctrl, registry.MetricsFor(step.Model, dyn, ctrl)); err != nil {
 			return results, fmt.Errorf("step %d setup: %w", i+1, err)
 		}
+		chain, err := config.BuildActuators(dyn, step.Actuators)
+		if err != nil {
+			return results, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		exp.SetActuators(chain)
+		events, err := config.BuildEvents(dyn, step.Events)
+		if err != nil {
+			return results, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		for _, ev := range events {
+			exp.GetSimulator().AddEvent(ev)
+		}
+		if step.Estimator != nil {
+			sensor, err := step.Estimator.Sensor(dyn)
+			if err != nil {
+				return results, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			est, err := registry.GetEstimator(step.Estimator.Type, dyn, sensor, step.Estimator.ProcessNoise, step.Setpoint)
+			if err != nil {
+				return results, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			if est != nil {
+				exp.SetEstimator(sensor, est)
+			}
+		}
+		if len(step.References) > 0 {
+			refs, err := config.BuildReferences(dyn, step.References)
+			if err != nil {
+				return results, fmt.Errorf("step %d: %w", i+1, err)
+			}
+			if err := exp.Track(refs); err != nil {
+				return results, fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
 
 		result, err := exp.Run(ctx)
 		if err != nil {
@@ -100,6 +204,25 @@ func RunScenario(ctx context.Context, scenario *Scenario, registry *experiment.R
 	return results, nil
 }
 
+// applyModelParams sets the entries of params that dyn exposes; the rest
+// are left for the controller.
+func applyModelParams(dyn dynamo.System, params map[string]float64) error {
+	t, ok := dyn.(dynamo.Configurable)
+	if !ok {
+		return nil
+	}
+	known := t.GetParams()
+	for k, v := range params {
+		if _, ok := known[k]; !ok {
+			continue
+		}
+		if err := t.SetParam(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ParameterSweep runs simulations across a range of parameter values
 type ParameterSweep struct {
 	Model      string
@@ -149,7 +272,9 @@ func RunSweep(ctx context.Context, sweep *ParameterSweep, registry *experiment.R
 
 	for i := 0; i < sweep.NumSteps; i++ {
 		paramVal := sweep.ParamMin + float64(i)*paramStep
-		tunable.SetParam(sweep.ParamName, paramVal)
+		if err := tunable.SetParam(sweep.ParamName, paramVal); err != nil {
+			return nil, err
+		}
 
 		cfg := experiment.Config{
 			Model:      sweep.Model,
@@ -260,6 +385,8 @@ func RunMonteCarlo(ctx context.Context, cfg *MonteCarloConfig, registry *experim
 			InitState:  initState,
 			Dt:         cfg.Dt,
 			Duration:   cfg.Duration,
+			// Stochastic integrators draw a distinct noise path per trial.
+			Seed: cfg.Seed + int64(trial),
 		}
 
 		exp := experiment.New(expCfg)
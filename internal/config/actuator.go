@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/san-kum/dynsim/internal/control"
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// ActuatorConfig declares one stage of the actuator chain between the
+// controller and the plant. Every setting takes one value for all control
+// channels or one per channel.
+type ActuatorConfig struct {
+	Type   string    `yaml:"type"` // saturation, rate_limit, lag, dead_zone, quantize or zoh
+	Min    []float64 `yaml:"min"`  // saturation bounds; -.inf and .inf leave a side open
+	Max    []float64 `yaml:"max"`
+	Rate   []float64 `yaml:"rate"`   // slew rate limit, units per second
+	Tau    []float64 `yaml:"tau"`    // lag time constant, seconds
+	Width  []float64 `yaml:"width"`  // dead-zone half width
+	Step   []float64 `yaml:"step"`   // quantization step
+	Period []float64 `yaml:"period"` // zero-order-hold sample period, seconds
+	// Initial is the output a rate_limit or lag starts from (default zero).
+	Initial []float64 `yaml:"initial"`
+}
+
+// Build resolves the declaration against dyn's control channels.
+func (a ActuatorConfig) Build(dyn dynamo.System) (dynamo.Actuator, error) {
+	settings := []struct {
+		name string
+		v    *[]float64
+	}{
+		{"min", &a.Min}, {"max", &a.Max}, {"rate", &a.Rate}, {"tau", &a.Tau},
+		{"width", &a.Width}, {"step", &a.Step}, {"period", &a.Period},
+		{"initial", &a.Initial},
+	}
+	for _, s := range settings {
+		v, err := perChannel(*s.v, dyn.ControlDim())
+		if err != nil {
+			return nil, fmt.Errorf("actuator %s: %s: %w", a.Type, s.name, err)
+		}
+		*s.v = v
+	}
+
+	switch a.Type {
+	case "saturation":
+		return control.NewSaturation(a.Min, a.Max), nil
+	case "rate_limit":
+		return control.NewRateLimit(a.Rate, a.Initial), nil
+	case "lag":
+		return control.NewLag(a.Tau, a.Initial), nil
+	case "dead_zone":
+		return control.NewDeadZone(a.Width), nil
+	case "quantize":
+		return control.NewQuantizer(a.Step), nil
+	case "zoh":
+		return control.NewZeroOrderHold(a.Period), nil
+	}
+	return nil, fmt.Errorf("unknown actuator: %s", a.Type)
+}
+
+// BuildActuators resolves a chain of actuator declarations against dyn.
+func BuildActuators(dyn dynamo.System, cfgs []ActuatorConfig) ([]dynamo.Actuator, error) {
+	chain := make([]dynamo.Actuator, 0, len(cfgs))
+	for _, c := range cfgs {
+		act, err := c.Build(dyn)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, act)
+	}
+	return chain, nil
+}
+
+// perChannel broadcasts a single value to m channels.
+func perChannel(v []float64, m int) ([]float64, error) {
+	switch len(v) {
+	case 0, m:
+		return v, nil
+	case 1:
+		out := make([]float64, m)
+		for j := range out {
+			out[j] = v[0]
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("got %d values for %d channels", len(v), m)
+}
@@ -18,14 +18,21 @@ const (
 )
 
 type Config struct {
-	Model            string           `yaml:"model"`
-	Integrator       string           `yaml:"integrator"`
-	Controller       string           `yaml:"controller"`
-	Dt               float64          `yaml:"dt"`
-	Duration         float64          `yaml:"duration"`
-	Seed             int64            `yaml:"seed"`
-	InitState        InitStateConfig  `yaml:"init_state"`
-	ControllerParams ControllerConfig `yaml:"controller_params"`
+	Model            string            `yaml:"model"`
+	Integrator       string            `yaml:"integrator"`
+	Controller       string            `yaml:"controller"`
+	Dt               float64           `yaml:"dt"`
+	Duration         float64           `yaml:"duration"`
+	Seed             int64             `yaml:"seed"`
+	ControlDt        float64           `yaml:"control_dt"`
+	ControlJitter    float64           `yaml:"control_jitter"`
+	Delay            float64           `yaml:"delay"` // controller measurement latency, seconds
+	InitState        InitStateConfig   `yaml:"init_state"`
+	ControllerParams ControllerConfig  `yaml:"controller_params"`
+	Estimator        EstimatorConfig   `yaml:"estimator"`
+	Actuators        []ActuatorConfig  `yaml:"actuators"`
+	Events           []EventConfig     `yaml:"events"`
+	References       []ReferenceConfig `yaml:"references"`
 }
 
 type InitStateConfig struct {
@@ -47,6 +54,20 @@ type ControllerConfig struct {
 	Ki     float64 `yaml:"ki"`
 	Kd     float64 `yaml:"kd"`
 	Target float64 `yaml:"target"`
+	// LQR and MPC weights and full-state setpoint; when Q or R is set the
+	// lqr controller is designed from the model rather than preset.
+	Q        []float64 `yaml:"q"`
+	R        []float64 `yaml:"r"`
+	Setpoint []float64 `yaml:"setpoint"`
+	// MPC horizon, prediction step and bounds.
+	Horizon int       `yaml:"horizon"`
+	MPCDt   float64   `yaml:"mpc_dt"`
+	UMin    []float64 `yaml:"u_min"`
+	UMax    []float64 `yaml:"u_max"`
+	XMin    []float64 `yaml:"x_min"`
+	XMax    []float64 `yaml:"x_max"`
+	// Loops replace the single pid loop on the first state.
+	Loops []PIDLoopConfig `yaml:"loops"`
 }
 
 func DefaultConfig() *Config {
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/estimation"
+)
+
+// EstimatorConfig puts a state estimator between the plant and the
+// controller, fed by a sensor on some of the states.
+type EstimatorConfig struct {
+	Type         string    `yaml:"type"`    // kf, ekf or ukf; empty for none
+	Observe      []string  `yaml:"observe"` // state names or indices; empty observes all
+	Noise        []float64 `yaml:"noise"`   // sensor noise standard deviation
+	Bias         []float64 `yaml:"bias"`
+	Dt           float64   `yaml:"dt"` // sample period; 0 samples every step
+	ProcessNoise []float64 `yaml:"process_noise"`
+}
+
+// Sensor resolves the sensor declaration against dyn's state variables.
+func (e EstimatorConfig) Sensor(dyn dynamo.System) (*estimation.Measurement, error) {
+	var observed []int
+	for _, name := range e.Observe {
+		idx, err := stateIndex(dyn, name)
+		if err != nil {
+			return nil, err
+		}
+		observed = append(observed, idx)
+	}
+	return estimation.NewMeasurement(dyn.StateDim(), observed, e.Noise, e.Bias, e.Dt)
+}
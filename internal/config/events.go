@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// EventConfig declares a zero-crossing event on one state variable. The
+// event function is x[var] - value, or |x[var]| - value when abs is set.
+type EventConfig struct {
+	Name      string  `yaml:"name"`
+	Var       string  `yaml:"var"` // state variable name or index
+	Value     float64 `yaml:"value"`
+	Abs       bool    `yaml:"abs"`
+	Direction string  `yaml:"direction"` // rising, falling or either (default)
+	Terminal  bool    `yaml:"terminal"`
+}
+
+// Build resolves the declaration against dyn's state variables.
+func (e EventConfig) Build(dyn dynamo.System) (dynamo.Event, error) {
+	idx, err := stateIndex(dyn, e.Var)
+	if err != nil {
+		return dynamo.Event{}, err
+	}
+
+	var dir dynamo.Direction
+	switch e.Direction {
+	case "", "either":
+		dir = dynamo.CrossEither
+	case "rising":
+		dir = dynamo.CrossRising
+	case "falling":
+		dir = dynamo.CrossFalling
+	default:
+		return dynamo.Event{}, fmt.Errorf("unknown event direction: %s", e.Direction)
+	}
+
+	name := e.Name
+	if name == "" {
+		name = e.Var
+	}
+	value, abs := e.Value, e.Abs
+	return dynamo.Event{
+		Name: name,
+		Fn: func(t float64, x dynamo.State) float64 {
+			if abs {
+				return math.Abs(x[idx]) - value
+			}
+			return x[idx] - value
+		},
+		Direction: dir,
+		Terminal:  e.Terminal,
+	}, nil
+}
+
+// stateIndex resolves a state variable name or index against dyn.
+func stateIndex(dyn dynamo.System, name string) (int, error) {
+	return varIndex(dynamo.DescribeState(dyn), "state", name)
+}
+
+// controlIndex resolves a control variable name or index against dyn.
+func controlIndex(dyn dynamo.System, name string) (int, error) {
+	return varIndex(dynamo.DescribeControl(dyn), "control", name)
+}
+
+func varIndex(vars []dynamo.Variable, kind, name string) (int, error) {
+	for i, v := range vars {
+		if v.Name == name {
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(name)
+	if err != nil || n < 0 || n >= len(vars) {
+		return 0, fmt.Errorf("unknown %s variable: %s", kind, name)
+	}
+	return n, nil
+}
+
+// BuildEvents resolves a list of event declarations against dyn.
+func BuildEvents(dyn dynamo.System, cfgs []EventConfig) ([]dynamo.Event, error) {
+	events := make([]dynamo.Event, 0, len(cfgs))
+	for _, c := range cfgs {
+		ev, err := c.Build(dyn)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"github.com/san-kum/dynsim/internal/control"
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// PIDLoopConfig declares one loop of a pid controller. A loop measures a
+// state variable and drives a control, or several through mix, or only
+// sets the target of a later loop that names it in from.
+type PIDLoopConfig struct {
+	Name    string    `yaml:"name"`
+	State   string    `yaml:"state"`   // state variable name or index
+	Control string    `yaml:"control"` // control variable name or index
+	Mix     []float64 `yaml:"mix"`     // weight on every control, instead of control
+	From    string    `yaml:"from"`    // loop whose output is the setpoint
+	Kp      float64   `yaml:"kp"`
+	Ki      float64   `yaml:"ki"`
+	Kd      float64   `yaml:"kd"`
+	Target  float64   `yaml:"target"`
+	// SetpointWeight (default 1) and DerivativeWeight (default 0) weight
+	// the setpoint in the proportional and derivative terms.
+	SetpointWeight   *float64 `yaml:"setpoint_weight"`
+	DerivativeWeight float64  `yaml:"derivative_weight"`
+	Filter           float64  `yaml:"filter"` // derivative low-pass time constant
+	Bias             float64  `yaml:"bias"`
+	// Min and Max saturate the output; unset leaves that side unbounded.
+	Min        *float64 `yaml:"min"`
+	Max        *float64 `yaml:"max"`
+	AntiWindup string   `yaml:"anti_windup"` // clamp, back_calculation or none
+	Tracking   float64  `yaml:"tracking"`    // back-calculation time constant
+}
+
+// Build resolves the declaration against dyn's variables.
+func (c PIDLoopConfig) Build(dyn dynamo.System) (control.Loop, error) {
+	state, err := stateIndex(dyn, c.State)
+	if err != nil {
+		return control.Loop{}, err
+	}
+	ctrl := -1
+	if c.Control != "" {
+		if ctrl, err = controlIndex(dyn, c.Control); err != nil {
+			return control.Loop{}, err
+		}
+	}
+	l := control.NewLoop(state, ctrl, c.Kp, c.Ki, c.Kd, c.Target)
+	if c.Mix != nil {
+		l.Mix = c.Mix
+	}
+	if l.AntiWindup, err = control.ParseAntiWindup(c.AntiWindup); err != nil {
+		return control.Loop{}, err
+	}
+	l.Name, l.From = c.Name, c.From
+	if c.SetpointWeight != nil {
+		l.Beta = *c.SetpointWeight
+	}
+	l.Gamma = c.DerivativeWeight
+	l.Tf, l.Bias, l.Tt = c.Filter, c.Bias, c.Tracking
+	if c.Min != nil {
+		l.Min = *c.Min
+	}
+	if c.Max != nil {
+		l.Max = *c.Max
+	}
+	return l, nil
+}
+
+// BuildLoops resolves a list of loop declarations against dyn.
+func BuildLoops(dyn dynamo.System, cfgs []PIDLoopConfig) ([]control.Loop, error) {
+	loops := make([]control.Loop, 0, len(cfgs))
+	for _, c := range cfgs {
+		l, err := c.Build(dyn)
+		if err != nil {
+			return nil, err
+		}
+		loops = append(loops, l)
+	}
+	return loops, nil
+}
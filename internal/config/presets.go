@@ -1,5 +1,7 @@
 package config
 
+import "math"
+
 var Presets = map[string]map[string]*Config{
 	"pendulum": {
 		"small": {
@@ -42,6 +44,17 @@ var Presets = map[string]map[string]*Config{
 			Model: "cartpole", Integrator: "rk4", Dt: 0.01, Duration: 10.0,
 			InitState: InitStateConfig{Pos: 0.0, Vel: 0.0, Theta: 0.1, Omega: 0.0},
 		},
+		"swingup": {
+			Model: "cartpole", Integrator: "rk4", Controller: "mpc", Dt: 0.01, Duration: 10.0,
+			InitState: InitStateConfig{Pos: 0.0, Vel: 0.0, Theta: math.Pi, Omega: 0.0},
+			ControllerParams: ControllerConfig{
+				Q: []float64{1, 0.1, 10, 0.1}, R: []float64{0.01},
+				Horizon: 60, MPCDt: 0.05,
+				UMin: []float64{-20}, UMax: []float64{20},
+				XMin: []float64{-2.4, math.Inf(-1), math.Inf(-1), math.Inf(-1)},
+				XMax: []float64{2.4, math.Inf(1), math.Inf(1), math.Inf(1)},
+			},
+		},
 	},
 	"spring_mass": {
 		"bounce": {
@@ -66,6 +79,37 @@ var Presets = map[string]map[string]*Config{
 			Model: "drone", Integrator: "rk4", Dt: 0.01, Duration: 5.0,
 			InitState: InitStateConfig{X: 0, Y: 10, Theta: 0.0, VX: 0, VY: 0, Omega: 0},
 		},
+		"climb": {
+			Model: "drone", Integrator: "rk4", Controller: "mpc", Dt: 0.01, Duration: 10.0,
+			InitState: InitStateConfig{X: 0, Y: 5, Theta: 0.0, VX: 0, VY: 0, Omega: 0},
+			ControllerParams: ControllerConfig{
+				Setpoint: []float64{0, 8, 0, 0, 0, 0},
+				Q:        []float64{1, 10, 1, 0.1, 1, 0.1}, R: []float64{0.1},
+				Horizon: 30, MPCDt: 0.05,
+				UMin: []float64{0}, UMax: []float64{15},
+			},
+		},
+		"cascade": {
+			Model: "drone", Integrator: "rk4", Controller: "pid", Dt: 0.01, Duration: 15.0,
+			InitState: InitStateConfig{X: 0, Y: 5, Theta: 0.0, VX: 0, VY: 0, Omega: 0},
+			ControllerParams: ControllerConfig{
+				Loops: []PIDLoopConfig{
+					{
+						Name: "altitude", State: "y", Mix: []float64{0.5, 0.5}, Target: 8,
+						Kp: 4, Ki: 1, Kd: 3, Filter: 0.02, Bias: 9.81,
+						Min: bound(0), Max: bound(20), AntiWindup: "clamp",
+					},
+					{
+						Name: "position", State: "x", Target: 2,
+						Kp: -0.15, Kd: -0.3, Min: bound(-0.4), Max: bound(0.4),
+					},
+					{
+						Name: "pitch", State: "theta", From: "position", Mix: []float64{-1, 1},
+						Kp: 8, Kd: 1.5, Filter: 0.01, Min: bound(-2), Max: bound(2),
+					},
+				},
+			},
+		},
 	},
 	"nbody": {
 		"orbit": {
@@ -79,6 +123,8 @@ var Presets = map[string]map[string]*Config{
 	},
 }
 
+func bound(v float64) *float64 { return &v }
+
 func GetPreset(model, preset string) *Config {
 	modelPresets, ok := Presets[model]
 	if !ok {
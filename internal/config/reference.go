@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/reference"
+)
+
+// ReferenceConfig declares a time-varying setpoint for one state variable.
+// Only the fields of the chosen type are read.
+type ReferenceConfig struct {
+	State string `yaml:"state"` // state variable name or index
+	Type  string `yaml:"type"`  // constant, step, ramp, sine, chirp, waypoints or csv
+
+	Value     float64   `yaml:"value"` // constant
+	Time      float64   `yaml:"time"`  // step
+	Initial   float64   `yaml:"initial"`
+	Final     float64   `yaml:"final"`
+	Start     float64   `yaml:"start"` // ramp
+	Stop      float64   `yaml:"stop"`
+	Slope     float64   `yaml:"slope"`
+	Amplitude float64   `yaml:"amplitude"` // sine and chirp
+	Frequency float64   `yaml:"frequency"` // hertz
+	Phase     float64   `yaml:"phase"`     // radians
+	Offset    float64   `yaml:"offset"`
+	F0        float64   `yaml:"f0"` // chirp sweep, hertz
+	F1        float64   `yaml:"f1"`
+	Duration  float64   `yaml:"duration"`
+	Times     []float64 `yaml:"times"` // waypoints
+	Values    []float64 `yaml:"values"`
+	File      string    `yaml:"file"`   // csv trajectory
+	Column    string    `yaml:"column"` // csv column, default the state name
+}
+
+// Build resolves the declaration against dyn's state variables and returns
+// the index of the state it drives.
+func (r ReferenceConfig) Build(dyn dynamo.System) (int, dynamo.Reference, error) {
+	idx, err := stateIndex(dyn, r.State)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	switch r.Type {
+	case "constant":
+		return idx, reference.Constant(r.Value), nil
+	case "step":
+		return idx, reference.Step{Time: r.Time, Initial: r.Initial, Final: r.Final}, nil
+	case "ramp":
+		return idx, reference.Ramp{Start: r.Start, Stop: r.Stop, Initial: r.Initial, Slope: r.Slope}, nil
+	case "sine":
+		return idx, reference.Sine{Amplitude: r.Amplitude, Frequency: r.Frequency, Phase: r.Phase, Offset: r.Offset}, nil
+	case "chirp":
+		return idx, reference.Chirp{Amplitude: r.Amplitude, F0: r.F0, F1: r.F1, Duration: r.Duration, Offset: r.Offset}, nil
+	case "waypoints":
+		w, err := reference.NewWaypoints(r.Times, r.Values)
+		if err != nil {
+			return 0, nil, fmt.Errorf("reference %s: %w", r.State, err)
+		}
+		return idx, w, nil
+	case "csv":
+		signals, err := reference.LoadCSV(r.File)
+		if err != nil {
+			return 0, nil, err
+		}
+		column := r.Column
+		if column == "" {
+			column = dynamo.DescribeState(dyn)[idx].Name
+		}
+		w, ok := signals[column]
+		if !ok {
+			return 0, nil, fmt.Errorf("reference %s: %s has no column %s", r.State, r.File, column)
+		}
+		return idx, w, nil
+	}
+	return 0, nil, fmt.Errorf("unknown reference type: %s", r.Type)
+}
+
+// BuildReferences resolves a list of reference declarations against dyn,
+// keyed by state index. Two references on one state are an error.
+func BuildReferences(dyn dynamo.System, cfgs []ReferenceConfig) (map[int]dynamo.Reference, error) {
+	refs := make(map[int]dynamo.Reference, len(cfgs))
+	for _, c := range cfgs {
+		idx, ref, err := c.Build(dyn)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := refs[idx]; dup {
+			return nil, fmt.Errorf("two references on state %s", c.State)
+		}
+		refs[idx] = ref
+	}
+	return refs, nil
+}
+
+// ReferencesFromCSV loads a trajectory file and maps each of its columns to
+// the state variable of the same name or index.
+func ReferencesFromCSV(dyn dynamo.System, path string) (map[int]dynamo.Reference, error) {
+	signals, err := reference.LoadCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[int]dynamo.Reference, len(signals))
+	for name, w := range signals {
+		idx, err := stateIndex(dyn, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		refs[idx] = w
+	}
+	return refs, nil
+}
@@ -0,0 +1,192 @@
+package control
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// The actuators below implement dynamo.Actuator. Their settings are per
+// channel; channels past the end of a setting pass through. Actuators with
+// memory start from rest, at their Initial output or zero.
+
+// Saturation clips each channel to [Min[j], Max[j]]; use ±Inf for an open
+// side.
+type Saturation struct {
+	Min, Max []float64
+}
+
+func NewSaturation(lo, hi []float64) *Saturation {
+	return &Saturation{Min: lo, Max: hi}
+}
+
+func (s *Saturation) Apply(u dynamo.Control, _ float64) dynamo.Control {
+	out := u.Clone()
+	for j := range out {
+		if j < len(s.Min) {
+			out[j] = math.Max(s.Min[j], out[j])
+		}
+		if j < len(s.Max) {
+			out[j] = math.Min(s.Max[j], out[j])
+		}
+	}
+	return out
+}
+
+// RateLimit bounds how fast each channel can move, to Rate[j] units per
+// second; +Inf leaves a channel free. The output starts at Initial.
+type RateLimit struct {
+	Rate    []float64
+	Initial []float64
+
+	prev  dynamo.Control
+	prevT float64
+}
+
+func NewRateLimit(rate, initial []float64) *RateLimit {
+	return &RateLimit{Rate: rate, Initial: initial}
+}
+
+func (r *RateLimit) Apply(u dynamo.Control, t float64) dynamo.Control {
+	if r.prev == nil || t < r.prevT || len(r.prev) != len(u) {
+		r.prev, r.prevT = initialOutput(u, r.Initial), t
+	}
+	dt := t - r.prevT
+	out := u.Clone()
+	for j := range out {
+		if j < len(r.Rate) && !math.IsInf(r.Rate[j], 1) {
+			step := r.Rate[j] * dt
+			out[j] = math.Max(r.prev[j]-step, math.Min(r.prev[j]+step, out[j]))
+		}
+	}
+	r.prev, r.prevT = out.Clone(), t
+	return out
+}
+
+// Lag is a first-order actuator response with time constant Tau[j]; 0
+// passes a channel straight through. The output starts at Initial, and the
+// command is taken as held since the previous call, so the response is
+// exact for any step size.
+type Lag struct {
+	Tau     []float64
+	Initial []float64
+
+	y     dynamo.Control
+	prevT float64
+}
+
+func NewLag(tau, initial []float64) *Lag {
+	return &Lag{Tau: tau, Initial: initial}
+}
+
+func (l *Lag) Apply(u dynamo.Control, t float64) dynamo.Control {
+	if l.y == nil || t < l.prevT || len(l.y) != len(u) {
+		l.y, l.prevT = initialOutput(u, l.Initial), t
+	}
+	dt := t - l.prevT
+	for j := range l.y {
+		if j < len(l.Tau) && l.Tau[j] > 0 {
+			l.y[j] += -math.Expm1(-dt/l.Tau[j]) * (u[j] - l.y[j])
+		} else {
+			l.y[j] = u[j]
+		}
+	}
+	l.prevT = t
+	return l.y.Clone()
+}
+
+// initialOutput is the resting output of an actuator with memory for a
+// command shaped like u: initial per channel, zero past its end.
+func initialOutput(u dynamo.Control, initial []float64) dynamo.Control {
+	out := make(dynamo.Control, len(u))
+	copy(out, initial)
+	return out
+}
+
+// DeadZone zeroes commands within Width[j] of zero and shifts the rest
+// toward zero by Width[j], so the response stays continuous.
+type DeadZone struct {
+	Width []float64
+}
+
+func NewDeadZone(width []float64) *DeadZone {
+	return &DeadZone{Width: width}
+}
+
+func (d *DeadZone) Apply(u dynamo.Control, _ float64) dynamo.Control {
+	out := u.Clone()
+	for j := range out {
+		if j >= len(d.Width) {
+			continue
+		}
+		w := d.Width[j]
+		switch {
+		case out[j] > w:
+			out[j] -= w
+		case out[j] < -w:
+			out[j] += w
+		default:
+			out[j] = 0
+		}
+	}
+	return out
+}
+
+// Quantizer rounds each channel to a multiple of Step[j], as a DAC of
+// finite resolution would; 0 leaves a channel continuous.
+type Quantizer struct {
+	Step []float64
+}
+
+func NewQuantizer(step []float64) *Quantizer {
+	return &Quantizer{Step: step}
+}
+
+func (q *Quantizer) Apply(u dynamo.Control, _ float64) dynamo.Control {
+	out := u.Clone()
+	for j := range out {
+		if j < len(q.Step) && q.Step[j] > 0 {
+			out[j] = math.Round(out[j]/q.Step[j]) * q.Step[j]
+		}
+	}
+	return out
+}
+
+// ZeroOrderHold samples each channel every Period[j] seconds, on the grid
+// k·Period[j], and holds the sample in between; 0 samples every call.
+type ZeroOrderHold struct {
+	Period []float64
+
+	held  dynamo.Control
+	next  []float64
+	prevT float64
+}
+
+func NewZeroOrderHold(period []float64) *ZeroOrderHold {
+	return &ZeroOrderHold{Period: period}
+}
+
+func (z *ZeroOrderHold) Apply(u dynamo.Control, t float64) dynamo.Control {
+	if z.held == nil || t < z.prevT || len(z.held) != len(u) {
+		z.held = make(dynamo.Control, len(u))
+		z.next = make([]float64, len(u))
+		for j := range z.next {
+			z.next[j] = math.Inf(-1)
+		}
+	}
+	z.prevT = t
+	for j := range z.held {
+		if j >= len(z.Period) || z.Period[j] <= 0 {
+			z.held[j] = u[j]
+			continue
+		}
+		// Tolerate rounding in t so a sample due on this step is not
+		// deferred to the next one.
+		p := z.Period[j]
+		if t >= z.next[j]-1e-9*p {
+			z.held[j] = u[j]
+			z.next[j] = (math.Floor(t/p+1e-9) + 1) * p
+		}
+	}
+	return z.held.Clone()
+}
@@ -0,0 +1,64 @@
+package control
+
+import "github.com/san-kum/dynsim/internal/dynamo"
+
+// Delayed feeds its controller the state measured Delay seconds earlier,
+// modelling sensor, transport and computation latency in the loop. Before
+// Delay has elapsed the controller sees the initial state.
+type Delayed struct {
+	Inner dynamo.Controller
+	Delay float64
+
+	past *dynamo.History
+}
+
+func NewDelayed(inner dynamo.Controller, delay float64) *Delayed {
+	return &Delayed{Inner: inner, Delay: delay, past: dynamo.NewHistory(delay)}
+}
+
+func (d *Delayed) Compute(x dynamo.State, t float64) dynamo.Control {
+	// Pushing an earlier time drops the newer samples, so a restarted run
+	// starts from a fresh history.
+	d.past.Span = d.Delay
+	d.past.Push(t, x)
+	return d.Inner.Compute(d.past.At(t-d.Delay), t)
+}
+
+var delayedParams = []dynamo.Param{
+	{Name: "delay", Default: 0, Min: 0, Max: 10, Unit: "s", Description: "measurement latency"},
+}
+
+// Params implements dynamo.Parameterized: the delay followed by the inner
+// controller's parameters.
+func (d *Delayed) Params() []dynamo.Param {
+	params := append([]dynamo.Param(nil), delayedParams...)
+	if p, ok := d.Inner.(dynamo.Parameterized); ok {
+		params = append(params, p.Params()...)
+	}
+	return params
+}
+
+func (d *Delayed) GetParams() map[string]float64 {
+	params := map[string]float64{}
+	if c, ok := d.Inner.(dynamo.Configurable); ok {
+		for k, v := range c.GetParams() {
+			params[k] = v
+		}
+	}
+	params["delay"] = d.Delay
+	return params
+}
+
+func (d *Delayed) SetParam(name string, value float64) error {
+	if name == "delay" {
+		if err := dynamo.CheckParam(delayedParams, name, value); err != nil {
+			return err
+		}
+		d.Delay = value
+		return nil
+	}
+	if c, ok := d.Inner.(dynamo.Configurable); ok {
+		return c.SetParam(name, value)
+	}
+	return dynamo.CheckParam(nil, name, value)
+}
@@ -3,9 +3,19 @@
 // Controllers implement the [dynamo.Controller] interface to compute
 // control inputs based on system state:
 //
-//   - [PID]: Proportional-Integral-Derivative controller
-//   - [LQR]: Linear Quadratic Regulator (requires linearized system)
+//   - [PID]: Proportional-Integral-Derivative controller; [NewMultiPID]
+//     runs several [Loop]s with saturation, anti-windup, derivative
+//     filtering and cascading
+//   - [LQR]: Linear Quadratic Regulator; [NewLQRFor] designs the gains
+//     for a model by solving the Riccati equation at a setpoint
+//   - [MPC]: Model predictive control by iterative LQR over a horizon, with
+//     input bounds and soft state bounds; [LinearModel] is a cheaper
+//     prediction model
 //   - [None]: Passthrough controller (zero control)
+//   - [Delayed]: Wraps a controller to see delayed measurements
+//   - Actuators between controller and plant, see [dynamo.Actuator]:
+//     [Saturation], [RateLimit], [Lag], [DeadZone], [Quantizer] and
+//     [ZeroOrderHold]
 //
 // # Usage
 //
@@ -13,5 +23,8 @@
 //	sim := dynamo.New(dyn, integ, pid)
 //	// Controller.Compute is called each timestep
 //
-// Controllers implementing [dynamo.Configurable] support live tuning.
+// Every controller implements [dynamo.Parameterized], publishing a schema of
+// its tunable parameters for live tuning. [PID], [LQR] and [MPC] also
+// implement [dynamo.Tracker], following time-varying references such as
+// those in package reference.
 package control
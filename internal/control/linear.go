@@ -0,0 +1,70 @@
+package control
+
+import (
+	"github.com/san-kum/dynsim/internal/autodiff"
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// LinearModel is the first-order expansion of a model about an operating
+// point, f(x, u) ≈ F0 + A(x - X0) + B(u - U0). It serves as a cheaper
+// prediction model for MPC.
+type LinearModel struct {
+	A, B linalg.Matrix
+	X0   dynamo.State
+	U0   dynamo.Control
+	F0   dynamo.State
+}
+
+// NewLinearModel linearizes dyn about (x0, u0) at t = 0. A nil u0 uses the
+// control that brings x0 closest to an equilibrium.
+func NewLinearModel(dyn dynamo.System, x0 dynamo.State, u0 dynamo.Control) *LinearModel {
+	u := make(dynamo.Control, dyn.ControlDim())
+	copy(u, u0)
+	if u0 == nil {
+		u = holdingControl(dyn, x0)
+	}
+	return &LinearModel{
+		A:  dynamo.StateJacobian(dyn, x0, u, 0),
+		B:  dynamo.ControlJacobian(dyn, x0, u, 0),
+		X0: x0.Clone(),
+		U0: u,
+		F0: dyn.Derive(x0, u, 0),
+	}
+}
+
+func (l *LinearModel) StateDim() int   { return len(l.X0) }
+func (l *LinearModel) ControlDim() int { return len(l.U0) }
+
+func (l *LinearModel) Derive(x dynamo.State, u dynamo.Control, _ float64) dynamo.State {
+	dx := make(dynamo.State, len(l.F0))
+	for i := range dx {
+		dx[i] = l.F0[i]
+		for j, v := range l.A[i] {
+			dx[i] += v * (x[j] - l.X0[j])
+		}
+		for j, v := range l.B[i] {
+			if j < len(u) {
+				dx[i] += v * (u[j] - l.U0[j])
+			}
+		}
+	}
+	return dx
+}
+
+// DeriveDual implements dynamo.Differentiable.
+func (l *LinearModel) DeriveDual(x, u []autodiff.Dual, _ float64) []autodiff.Dual {
+	dx := make([]autodiff.Dual, len(l.F0))
+	for i := range dx {
+		dx[i] = autodiff.Const(l.F0[i])
+		for j, v := range l.A[i] {
+			dx[i] = dx[i].Add(x[j].AddC(-l.X0[j]).Scale(v))
+		}
+		for j, v := range l.B[i] {
+			if j < len(u) {
+				dx[i] = dx[i].Add(u[j].AddC(-l.U0[j]).Scale(v))
+			}
+		}
+	}
+	return dx
+}
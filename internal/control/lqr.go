@@ -1,22 +1,46 @@
 package control
 
-import "github.com/san-kum/dynsim/internal/dynamo"
+import (
+	"fmt"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
 
 type LQR struct {
 	K      [][]float64
 	Target dynamo.State
+	// Feedforward is the control that holds the system at Target, added to
+	// the feedback. Synthesized controllers set it; hand-tuned gains leave
+	// it empty.
+	Feedforward dynamo.Control
+	// References, one per state, replace Target for the states that have
+	// one, see Track.
+	References []dynamo.Reference
+
+	// initial gains and setpoint, reported as parameter defaults
+	k0      [][]float64
+	target0 dynamo.State
 }
 
 func NewLQR(k [][]float64, target dynamo.State) *LQR {
-	return &LQR{K: k, Target: target}
+	k0 := make([][]float64, len(k))
+	for i, row := range k {
+		k0[i] = append([]float64(nil), row...)
+	}
+	return &LQR{K: k, Target: target, k0: k0, target0: append(dynamo.State(nil), target...)}
 }
 
 func (l *LQR) Compute(x dynamo.State, t float64) dynamo.Control {
 	u := make(dynamo.Control, len(l.K))
 	for i := range u {
+		if i < len(l.Feedforward) {
+			u[i] = l.Feedforward[i]
+		}
 		for j := range x {
 			target := 0.0
-			if j < len(l.Target) {
+			if j < len(l.References) && l.References[j] != nil {
+				target = l.References[j].Value(t)
+			} else if j < len(l.Target) {
 				target = l.Target[j]
 			}
 			if j < len(l.K[i]) {
@@ -27,6 +51,18 @@ func (l *LQR) Compute(x dynamo.State, t float64) dynamo.Control {
 	return u
 }
 
+// Track implements dynamo.Tracker.
+func (l *LQR) Track(i int, ref dynamo.Reference) error {
+	if len(l.K) == 0 || i < 0 || i >= len(l.K[0]) {
+		return fmt.Errorf("lqr: no state %d to track", i)
+	}
+	for len(l.References) <= i {
+		l.References = append(l.References, nil)
+	}
+	l.References[i] = ref
+	return nil
+}
+
 var (
 	pendulumGains   = [][]float64{{31.62, 10.0}}
 	cartpoleGains   = [][]float64{{-1.0, -1.73, 35.36, 8.94}}
@@ -57,3 +93,59 @@ func NewDoublePendulumLQR() *LQR {
 func NewSpringMassLQR() *LQR {
 	return NewLQR(springGains, dynamo.State{0, 0})
 }
+
+// Params implements dynamo.Parameterized. Gain K[i][j] is exposed as
+// "k<i>_<j>" and setpoint Target[j] as "target<j>".
+func (l *LQR) Params() []dynamo.Param {
+	params := make([]dynamo.Param, 0)
+	for i, row := range l.K {
+		for j, v := range row {
+			if i < len(l.k0) && j < len(l.k0[i]) {
+				v = l.k0[i][j]
+			}
+			params = append(params, dynamo.Param{
+				Name: fmt.Sprintf("k%d_%d", i, j), Default: v,
+				Min: -dynamo.Unbounded, Max: dynamo.Unbounded,
+				Description: fmt.Sprintf("gain from x[%d] to u[%d]", j, i),
+			})
+		}
+	}
+	for j, v := range l.Target {
+		if j < len(l.target0) {
+			v = l.target0[j]
+		}
+		params = append(params, dynamo.Param{
+			Name: fmt.Sprintf("target%d", j), Default: v,
+			Min: -dynamo.Unbounded, Max: dynamo.Unbounded,
+			Description: fmt.Sprintf("setpoint for x[%d]", j),
+		})
+	}
+	return params
+}
+
+func (l *LQR) GetParams() map[string]float64 {
+	values := make(map[string]float64)
+	for i, row := range l.K {
+		for j, v := range row {
+			values[fmt.Sprintf("k%d_%d", i, j)] = v
+		}
+	}
+	for j, v := range l.Target {
+		values[fmt.Sprintf("target%d", j)] = v
+	}
+	return values
+}
+
+func (l *LQR) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(l.Params(), name, value); err != nil {
+		return err
+	}
+	var i, j int
+	if _, err := fmt.Sscanf(name, "k%d_%d", &i, &j); err == nil {
+		l.K[i][j] = value
+		return nil
+	}
+	fmt.Sscanf(name, "target%d", &j)
+	l.Target[j] = value
+	return nil
+}
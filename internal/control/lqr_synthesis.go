@@ -0,0 +1,178 @@
+package control
+
+import (
+	"fmt"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// LQRGains returns the continuous-time optimal gain K = R⁻¹BᵀP, where P
+// solves the algebraic Riccati equation for x' = Ax + Bu with cost
+// ∫ xᵀQx + uᵀRu dt.
+func LQRGains(a, b, q, r linalg.Matrix) (linalg.Matrix, error) {
+	p, err := linalg.SolveCARE(a, b, q, r)
+	if err != nil {
+		return nil, err
+	}
+	rinv, err := linalg.Inverse(r)
+	if err != nil {
+		return nil, err
+	}
+	return rinv.Mul(b.T()).Mul(p), nil
+}
+
+// DiscreteLQRGains returns the optimal gain K = (R + BᵀPB)⁻¹BᵀPA for
+// x[k+1] = Ax[k] + Bu[k] with cost Σ xᵀQx + uᵀRu.
+func DiscreteLQRGains(a, b, q, r linalg.Matrix) (linalg.Matrix, error) {
+	p, err := linalg.SolveDARE(a, b, q, r)
+	if err != nil {
+		return nil, err
+	}
+	return discreteGain(a, b, r, p)
+}
+
+// discreteGain returns (R + BᵀPB)⁻¹BᵀPA for a solution P of the discrete
+// Riccati equation.
+func discreteGain(a, b, r, p linalg.Matrix) (linalg.Matrix, error) {
+	bt := b.T()
+	inv, err := linalg.Inverse(r.Add(bt.Mul(p).Mul(b)))
+	if err != nil {
+		return nil, err
+	}
+	return inv.Mul(bt).Mul(p).Mul(a), nil
+}
+
+// Weights builds a diagonal weight matrix of size n. An empty w gives the
+// identity and a single value is used for every entry.
+func Weights(w []float64, n int) (linalg.Matrix, error) {
+	d, err := diagonal(w, n)
+	if err != nil {
+		return nil, err
+	}
+	m := linalg.New(n, n)
+	for i, v := range d {
+		m[i][i] = v
+	}
+	return m, nil
+}
+
+// diagonal is Weights without building the matrix.
+func diagonal(w []float64, n int) ([]float64, error) {
+	if len(w) == 0 {
+		w = []float64{1}
+	}
+	d, err := broadcast(w, n)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range d {
+		if v < 0 {
+			return nil, fmt.Errorf("negative weight %g", v)
+		}
+	}
+	return d, nil
+}
+
+// broadcast expands v to n values: nil stays nil and a single value is
+// repeated.
+func broadcast(v []float64, n int) ([]float64, error) {
+	switch len(v) {
+	case 0:
+		return nil, nil
+	case 1:
+		out := make([]float64, n)
+		for i := range out {
+			out[i] = v[0]
+		}
+		return out, nil
+	case n:
+		return append([]float64(nil), v...), nil
+	}
+	return nil, fmt.Errorf("got %d values, want 1 or %d", len(v), n)
+}
+
+// NewLQRFor designs an LQR for dyn about target by linearizing the model's
+// current parameters there and solving the continuous Riccati equation.
+// q and r are the diagonals of Q and R, see Weights. The controller adds
+// the feedforward control that best holds the model at target.
+func NewLQRFor(dyn dynamo.System, target dynamo.State, q, r []float64) (*LQR, error) {
+	return synthesize(dyn, target, q, r, func(a, b, qm, rm linalg.Matrix) (linalg.Matrix, error) {
+		return LQRGains(a, b, qm, rm)
+	})
+}
+
+// NewDiscreteLQRFor is NewLQRFor for a controller sampled every dt with a
+// zero-order hold: it discretizes the linearization and solves the
+// discrete Riccati equation instead.
+func NewDiscreteLQRFor(dyn dynamo.System, target dynamo.State, q, r []float64, dt float64) (*LQR, error) {
+	if dt <= 0 {
+		return nil, fmt.Errorf("lqr: sample period must be positive, got %g", dt)
+	}
+	return synthesize(dyn, target, q, r, func(a, b, qm, rm linalg.Matrix) (linalg.Matrix, error) {
+		ad, bd := linalg.ZOH(a, b, dt)
+		return DiscreteLQRGains(ad, bd, qm, rm)
+	})
+}
+
+func synthesize(dyn dynamo.System, target dynamo.State, q, r []float64, gains func(a, b, q, r linalg.Matrix) (linalg.Matrix, error)) (*LQR, error) {
+	n, m := dyn.StateDim(), dyn.ControlDim()
+	if len(target) == 0 {
+		target = make(dynamo.State, n)
+	}
+	if len(target) != n {
+		return nil, fmt.Errorf("lqr: target has %d values, model state has %d", len(target), n)
+	}
+	if m == 0 {
+		return nil, fmt.Errorf("lqr: model has no control inputs")
+	}
+	qm, err := Weights(q, n)
+	if err != nil {
+		return nil, fmt.Errorf("lqr: q: %w", err)
+	}
+	rm, err := Weights(r, m)
+	if err != nil {
+		return nil, fmt.Errorf("lqr: r: %w", err)
+	}
+
+	u0 := holdingControl(dyn, target)
+	a := linalg.Matrix(dynamo.StateJacobian(dyn, target, u0, 0))
+	b := linalg.Matrix(dynamo.ControlJacobian(dyn, target, u0, 0))
+	k, err := gains(a, b, qm, rm)
+	if err != nil {
+		return nil, fmt.Errorf("lqr: %w", err)
+	}
+	l := NewLQR(k, target.Clone())
+	l.Feedforward = u0
+	return l, nil
+}
+
+// holdingControl returns the u minimizing |f(x, u)| by Gauss-Newton, the
+// input that makes x as close to an equilibrium as the actuators allow.
+// The search starts at u = 1 rather than 0 because actuators that clip
+// negative commands, like rotor thrust, have no gradient at zero.
+func holdingControl(dyn dynamo.System, x dynamo.State) dynamo.Control {
+	u := make(dynamo.Control, dyn.ControlDim())
+	for i := range u {
+		u[i] = 1
+	}
+	for iter := 0; iter < 20; iter++ {
+		f := dyn.Derive(x, u, 0)
+		b := linalg.Matrix(dynamo.ControlJacobian(dyn, x, u, 0))
+		bt := b.T()
+		lu, err := linalg.Factor(bt.Mul(b))
+		if err != nil {
+			break
+		}
+		du := lu.Solve(bt.MulVec(f))
+		step := 0.0
+		for i := range u {
+			u[i] -= du[i]
+			step += du[i] * du[i]
+		}
+		if step < 1e-24 {
+			break
+		}
+	}
+	return u
+}
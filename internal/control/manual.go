@@ -26,3 +26,13 @@ func (c *ManualController) SetControl(u []float64) {
 func (c *ManualController) Compute(state dynamo.State, t float64) dynamo.Control {
 	return c.U
 }
+
+// Params implements dynamo.Parameterized; the control vector is driven
+// interactively, so there are no parameters.
+func (c *ManualController) Params() []dynamo.Param { return nil }
+
+func (c *ManualController) GetParams() map[string]float64 { return map[string]float64{} }
+
+func (c *ManualController) SetParam(name string, value float64) error {
+	return dynamo.CheckParam(nil, name, value)
+}
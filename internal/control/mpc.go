@@ -0,0 +1,566 @@
+package control
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/integrators"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// MPC is a receding-horizon model predictive controller. Every Dt it plans
+// the next Horizon controls by iterative LQR on the prediction model,
+// applies the first one and holds it until the next solve. Each plan is
+// warm started from the previous one shifted by the time that passed.
+//
+// The cost is the sum over the horizon of (x-Target)ᵀQ(x-Target) and
+// (u-u*)ᵀR(u-u*), where u* is the control that holds the model at Target,
+// plus a terminal cost: the infinite-horizon LQR cost-to-go at Target when
+// the Riccati equation has a solution there, Q otherwise. Inputs are kept
+// within UMin and UMax exactly; states outside XMin and XMax are penalized
+// with ConstraintWeight times the squared violation.
+//
+// States with a reference, see Track, are driven along it instead of to
+// their Target: the cost at each prediction step compares against the
+// reference at that step's time, so the plan anticipates it.
+type MPC struct {
+	Model      dynamo.System
+	Horizon    int
+	Dt         float64
+	Target     dynamo.State
+	References []dynamo.Reference // per state; nil entries use Target
+	MaxIter    int
+
+	// Diagonal weights, one per state and input.
+	Q, R []float64
+	// Bounds; nil leaves a side unbounded.
+	UMin, UMax       []float64
+	XMin, XMax       []float64
+	ConstraintWeight float64
+
+	step     dynamo.Integrator
+	plan     []dynamo.Control
+	u        dynamo.Control
+	solveT   float64
+	solved   bool
+	stats    dynamo.SolveStats
+	defaults map[string]float64
+}
+
+// MPC defaults, used by the CLI and scenarios when a value is not given.
+const (
+	DefaultHorizon          = 40
+	DefaultMPCDt            = 0.05
+	DefaultMPCIterations    = 20
+	DefaultConstraintWeight = 1e4
+)
+
+// NewMPC returns an MPC predicting with model. q and r are the diagonals of
+// Q and R, broadcast as in Weights; an empty target is the origin.
+func NewMPC(model dynamo.System, target dynamo.State, q, r []float64, horizon int, dt float64) (*MPC, error) {
+	n, m := model.StateDim(), model.ControlDim()
+	if m == 0 {
+		return nil, fmt.Errorf("mpc: model has no control inputs")
+	}
+	if horizon < 1 {
+		return nil, fmt.Errorf("mpc: horizon must be at least 1, got %d", horizon)
+	}
+	if dt <= 0 {
+		return nil, fmt.Errorf("mpc: prediction step must be positive, got %g", dt)
+	}
+	if len(target) == 0 {
+		target = make(dynamo.State, n)
+	}
+	if len(target) != n {
+		return nil, fmt.Errorf("mpc: target has %d values, model state has %d", len(target), n)
+	}
+	qd, err := diagonal(q, n)
+	if err != nil {
+		return nil, fmt.Errorf("mpc: q: %w", err)
+	}
+	rd, err := diagonal(r, m)
+	if err != nil {
+		return nil, fmt.Errorf("mpc: r: %w", err)
+	}
+	for _, v := range rd {
+		if v <= 0 {
+			return nil, fmt.Errorf("mpc: r weights must be positive")
+		}
+	}
+	c := &MPC{
+		Model:            model,
+		Horizon:          horizon,
+		Dt:               dt,
+		Target:           target.Clone(),
+		MaxIter:          DefaultMPCIterations,
+		Q:                qd,
+		R:                rd,
+		ConstraintWeight: DefaultConstraintWeight,
+		step:             integrators.NewRK4(),
+	}
+	c.defaults = c.GetParams()
+	return c, nil
+}
+
+// SetInputBounds sets UMin and UMax, broadcasting single values.
+func (c *MPC) SetInputBounds(lo, hi []float64) error {
+	m := c.Model.ControlDim()
+	var err error
+	if c.UMin, err = broadcast(lo, m); err != nil {
+		return fmt.Errorf("mpc: input lower bound: %w", err)
+	}
+	if c.UMax, err = broadcast(hi, m); err != nil {
+		return fmt.Errorf("mpc: input upper bound: %w", err)
+	}
+	return nil
+}
+
+// SetStateBounds sets XMin and XMax, broadcasting single values. Use ±Inf
+// for states that are not bounded.
+func (c *MPC) SetStateBounds(lo, hi []float64) error {
+	n := c.Model.StateDim()
+	var err error
+	if c.XMin, err = broadcast(lo, n); err != nil {
+		return fmt.Errorf("mpc: state lower bound: %w", err)
+	}
+	if c.XMax, err = broadcast(hi, n); err != nil {
+		return fmt.Errorf("mpc: state upper bound: %w", err)
+	}
+	return nil
+}
+
+func (c *MPC) Compute(x dynamo.State, t float64) dynamo.Control {
+	// Solve on the first call, once per Dt after that, and afresh when a
+	// restarted run sends time backwards.
+	if !c.solved || t < c.solveT || t >= c.solveT+c.Dt*(1-1e-9) {
+		c.solve(x, t)
+	}
+	return append(dynamo.Control(nil), c.u...)
+}
+
+// SolveStats implements dynamo.Optimizer.
+func (c *MPC) SolveStats() dynamo.SolveStats { return c.stats }
+
+// Plan returns the controls of the most recent solve, one per prediction
+// step.
+func (c *MPC) Plan() []dynamo.Control { return c.plan }
+
+// problem holds what a solve derives from the model at the target.
+type problem struct {
+	t        float64
+	targets  []dynamo.State // target at each prediction step
+	uRef     dynamo.Control
+	terminal linalg.Matrix // weight on the final state error
+	gain     linalg.Matrix // discrete LQR gain at the final target, nil if none
+}
+
+func (c *MPC) solve(x dynamo.State, t float64) {
+	p := c.problem(t)
+
+	// Start from the previous plan, or from LQR about Target when that is
+	// cheaper: the shifted plan can sit in a poor local minimum once the
+	// state has moved on.
+	us := c.coldStart(x, p)
+	xs, cost := c.rollout(x, us, p)
+	if c.solved && t >= c.solveT {
+		warm := c.warmStart(t, p)
+		if wx, wcost := c.rollout(x, warm, p); wcost < cost {
+			us, xs, cost = warm, wx, wcost
+		}
+	}
+
+	mu := 1e-6
+	iter := 0
+	for iter < c.MaxIter && !math.IsInf(cost, 1) {
+		iter++
+		ks, gains, ok := c.backward(xs, us, p, mu)
+		if !ok {
+			if mu *= 10; mu > 1e8 {
+				break
+			}
+			continue
+		}
+		improvement := -1.0
+		for alpha := 1.0; alpha > 1e-3; alpha /= 2 {
+			nu := c.forward(x, xs, us, ks, gains, p, alpha)
+			nx, ncost := c.rollout(x, nu, p)
+			if ncost < cost {
+				improvement = (cost - ncost) / math.Max(cost, 1e-12)
+				xs, us, cost = nx, nu, ncost
+				break
+			}
+		}
+		if improvement < 0 {
+			if mu *= 10; mu > 1e8 {
+				break
+			}
+			continue
+		}
+		if improvement < 1e-6 {
+			break
+		}
+		mu = math.Max(mu/10, 1e-8)
+	}
+
+	c.plan = us
+	c.u = us[0]
+	c.solveT = t
+	c.solved = true
+	c.stats.Solves++
+	c.stats.Iterations += iter
+	c.stats.Cost += cost
+}
+
+// warmStart shifts the previous plan to start at t, padding with the
+// holding control.
+func (c *MPC) warmStart(t float64, p *problem) []dynamo.Control {
+	shift := int(math.Round((t - c.solveT) / c.Dt))
+	us := make([]dynamo.Control, c.Horizon)
+	for k := range us {
+		if k+shift < len(c.plan) {
+			us[k] = append(dynamo.Control(nil), c.plan[k+shift]...)
+		} else {
+			us[k] = append(dynamo.Control(nil), p.uRef...)
+		}
+		c.clamp(us[k])
+	}
+	return us
+}
+
+// coldStart is the plan of the LQR about Target, run on the prediction
+// model from x, or of holding still when there is no LQR.
+func (c *MPC) coldStart(x dynamo.State, p *problem) []dynamo.Control {
+	us := make([]dynamo.Control, c.Horizon)
+	xk := x.Clone()
+	for k := range us {
+		u := append(dynamo.Control(nil), p.uRef...)
+		if p.gain != nil {
+			fb := p.gain.MulVec(xk.Sub(p.targets[k]))
+			for i := range u {
+				u[i] -= fb[i]
+			}
+		}
+		c.clamp(u)
+		us[k] = u
+		xk = c.step.Step(c.Model, xk, u, p.t+float64(k)*c.Dt, c.Dt)
+	}
+	return us
+}
+
+// rollout predicts the states under us from x and returns them with the
+// cost of the trajectory.
+func (c *MPC) rollout(x dynamo.State, us []dynamo.Control, p *problem) ([]dynamo.State, float64) {
+	xs := make([]dynamo.State, len(us)+1)
+	xs[0] = x.Clone()
+	cost := 0.0
+	for k, u := range us {
+		cost += c.stageCost(xs[k], p.targets[k], u, p.uRef)
+		xs[k+1] = c.step.Step(c.Model, xs[k], u, p.t+float64(k)*c.Dt, c.Dt)
+	}
+	e := xs[len(us)].Sub(p.targets[len(us)])
+	cost += 0.5*dot(e, p.terminal.MulVec(e)) + c.penalty(xs[len(us)])
+	if math.IsNaN(cost) {
+		cost = math.Inf(1)
+	}
+	return xs, cost
+}
+
+func (c *MPC) stageCost(x, target dynamo.State, u, uRef dynamo.Control) float64 {
+	cost := c.penalty(x)
+	for i, q := range c.Q {
+		e := x[i] - target[i]
+		cost += 0.5 * q * e * e
+	}
+	for i, r := range c.R {
+		e := u[i] - uRef[i]
+		cost += 0.5 * r * e * e
+	}
+	return cost
+}
+
+// violation returns by how much x[i] lies outside its bounds, signed.
+func (c *MPC) violation(x dynamo.State, i int) float64 {
+	if c.XMin != nil && x[i] < c.XMin[i] {
+		return x[i] - c.XMin[i]
+	}
+	if c.XMax != nil && x[i] > c.XMax[i] {
+		return x[i] - c.XMax[i]
+	}
+	return 0
+}
+
+func (c *MPC) penalty(x dynamo.State) float64 {
+	p := 0.0
+	for i := range x {
+		v := c.violation(x, i)
+		p += 0.5 * c.ConstraintWeight * v * v
+	}
+	return p
+}
+
+// backward is the iterative LQR backward pass: it expands the cost to
+// second order and the dynamics to first order along (xs, us) and returns
+// the feedforward steps and feedback gains of the improved policy. Steps
+// that would cross an input bound are cut at the bound, with no feedback
+// on that input. It fails when the regularized Hessian is not positive
+// definite.
+func (c *MPC) backward(xs []dynamo.State, us []dynamo.Control, p *problem, mu float64) ([][]float64, []linalg.Matrix, bool) {
+	n, m := len(xs[0]), len(us[0])
+	N := len(us)
+
+	vx := p.terminal.MulVec(xs[N].Sub(p.targets[N]))
+	vxx := p.terminal.Clone()
+	c.addPenalty(xs[N], vx, vxx)
+
+	ks := make([][]float64, N)
+	gains := make([]linalg.Matrix, N)
+	for k := N - 1; k >= 0; k-- {
+		x, u, tk := xs[k], us[k], p.t+float64(k)*c.Dt
+		a, b := linalg.ZOH(
+			dynamo.StateJacobian(c.Model, x, u, tk),
+			dynamo.ControlJacobian(c.Model, x, u, tk),
+			c.Dt,
+		)
+		at, bt := a.T(), b.T()
+
+		lx := make([]float64, n)
+		lxx := linalg.New(n, n)
+		for i := 0; i < n; i++ {
+			lx[i] = c.Q[i] * (x[i] - p.targets[k][i])
+			lxx[i][i] = c.Q[i]
+		}
+		c.addPenalty(x, lx, lxx)
+
+		qx := addVec(lx, at.MulVec(vx))
+		qu := bt.MulVec(vx)
+		for i := 0; i < m; i++ {
+			qu[i] += c.R[i] * (u[i] - p.uRef[i])
+		}
+		qxx := lxx.Add(at.Mul(vxx).Mul(a))
+		quu := bt.Mul(vxx).Mul(b)
+		for i := 0; i < m; i++ {
+			quu[i][i] += c.R[i] + mu
+		}
+		qux := bt.Mul(vxx).Mul(a)
+
+		if _, err := linalg.Cholesky(quu); err != nil {
+			return nil, nil, false
+		}
+		inv, err := linalg.Inverse(quu)
+		if err != nil {
+			return nil, nil, false
+		}
+		kff := inv.MulVec(qu)
+		gain := inv.Mul(qux)
+		for i := 0; i < m; i++ {
+			kff[i] = -kff[i]
+			for j := range gain[i] {
+				gain[i][j] = -gain[i][j]
+			}
+			next := u[i] + kff[i]
+			switch {
+			case c.UMax != nil && next > c.UMax[i]:
+				kff[i] = c.UMax[i] - u[i]
+				gain[i] = make([]float64, n)
+			case c.UMin != nil && next < c.UMin[i]:
+				kff[i] = c.UMin[i] - u[i]
+				gain[i] = make([]float64, n)
+			}
+		}
+		ks[k], gains[k] = kff, gain
+
+		// Vx = Qx + KᵀQuu k + KᵀQu + Quxᵀk, Vxx = Qxx + KᵀQuu K + KᵀQux + QuxᵀK
+		gt, quxT := gain.T(), qux.T()
+		vx = addVec(addVec(qx, gt.MulVec(addVec(quu.MulVec(kff), qu))), quxT.MulVec(kff))
+		vxx = qxx.Add(gt.Mul(quu).Mul(gain)).Add(gt.Mul(qux)).Add(quxT.Mul(gain))
+		for i := 0; i < n; i++ {
+			for j := 0; j < i; j++ {
+				s := 0.5 * (vxx[i][j] + vxx[j][i])
+				vxx[i][j], vxx[j][i] = s, s
+			}
+		}
+	}
+	return ks, gains, true
+}
+
+// addPenalty adds the gradient and Gauss-Newton Hessian of the state
+// constraint penalty at x.
+func (c *MPC) addPenalty(x dynamo.State, gx []float64, hxx linalg.Matrix) {
+	for i := range x {
+		if v := c.violation(x, i); v != 0 {
+			gx[i] += c.ConstraintWeight * v
+			hxx[i][i] += c.ConstraintWeight
+		}
+	}
+}
+
+// forward applies the policy from the backward pass with step length alpha,
+// feeding back the deviation of the new trajectory from the old one.
+func (c *MPC) forward(x dynamo.State, xs []dynamo.State, us []dynamo.Control, ks [][]float64, gains []linalg.Matrix, p *problem, alpha float64) []dynamo.Control {
+	nu := make([]dynamo.Control, len(us))
+	xk := x.Clone()
+	for k := range us {
+		dx := xk.Sub(xs[k])
+		fb := gains[k].MulVec(dx)
+		u := make(dynamo.Control, len(us[k]))
+		for i := range u {
+			u[i] = us[k][i] + alpha*ks[k][i] + fb[i]
+		}
+		c.clamp(u)
+		nu[k] = u
+		xk = c.step.Step(c.Model, xk, u, p.t+float64(k)*c.Dt, c.Dt)
+	}
+	return nu
+}
+
+// problem linearizes the model at the target at the end of the horizon to
+// find the holding control and the discrete LQR there, whose cost-to-go
+// becomes the terminal weight. Without a stabilizing Riccati solution the
+// terminal weight is Q.
+func (c *MPC) problem(t float64) *problem {
+	n, m := len(c.Q), len(c.R)
+	q, r := linalg.New(n, n), linalg.New(m, m)
+	for i, v := range c.Q {
+		q[i][i] = v
+	}
+	for i, v := range c.R {
+		r[i][i] = v
+	}
+	p := &problem{t: t, targets: make([]dynamo.State, c.Horizon+1), terminal: q}
+	for k := range p.targets {
+		p.targets[k] = c.targetAt(t + float64(k)*c.Dt)
+	}
+	final := p.targets[c.Horizon]
+	p.uRef = holdingControl(c.Model, final)
+	a, b := linalg.ZOH(
+		dynamo.StateJacobian(c.Model, final, p.uRef, t),
+		dynamo.ControlJacobian(c.Model, final, p.uRef, t),
+		c.Dt,
+	)
+	// The stage cost is summed per step, so the weights apply unscaled to
+	// the discrete problem.
+	if pn, err := linalg.SolveDARE(a, b, q, r); err == nil {
+		if gain, err := discreteGain(a, b, r, pn); err == nil {
+			p.terminal, p.gain = pn, gain
+		}
+	}
+	return p
+}
+
+// targetAt is Target with the referenced states replaced by their
+// references at t.
+func (c *MPC) targetAt(t float64) dynamo.State {
+	target := c.Target.Clone()
+	for i, ref := range c.References {
+		if ref != nil {
+			target[i] = ref.Value(t)
+		}
+	}
+	return target
+}
+
+// Track implements dynamo.Tracker.
+func (c *MPC) Track(i int, ref dynamo.Reference) error {
+	if i < 0 || i >= len(c.Target) {
+		return fmt.Errorf("mpc: no state %d to track", i)
+	}
+	if c.References == nil {
+		c.References = make([]dynamo.Reference, len(c.Target))
+	}
+	c.References[i] = ref
+	return nil
+}
+
+func (c *MPC) clamp(u dynamo.Control) {
+	for i := range u {
+		if c.UMin != nil {
+			u[i] = math.Max(u[i], c.UMin[i])
+		}
+		if c.UMax != nil {
+			u[i] = math.Min(u[i], c.UMax[i])
+		}
+	}
+}
+
+func dot(a, b []float64) float64 {
+	s := 0.0
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func addVec(a, b []float64) []float64 {
+	s := make([]float64, len(a))
+	for i := range a {
+		s[i] = a[i] + b[i]
+	}
+	return s
+}
+
+var mpcParams = []dynamo.Param{
+	{Name: "horizon", Default: DefaultHorizon, Min: 1, Max: 1000, Description: "prediction steps"},
+	{Name: "dt", Default: DefaultMPCDt, Min: 1e-4, Max: 10, Unit: "s", Description: "prediction step and replanning period"},
+	{Name: "max_iter", Default: DefaultMPCIterations, Min: 1, Max: 1000, Description: "solver iterations per plan"},
+	{Name: "constraint_weight", Default: DefaultConstraintWeight, Min: 0, Max: dynamo.Unbounded, Description: "penalty on state bound violations"},
+}
+
+// Params implements dynamo.Parameterized: the solver settings and the
+// setpoint Target[j] as "target<j>".
+func (c *MPC) Params() []dynamo.Param {
+	params := make([]dynamo.Param, 0, len(mpcParams)+len(c.Target))
+	for _, p := range mpcParams {
+		if v, ok := c.defaults[p.Name]; ok {
+			p.Default = v
+		}
+		params = append(params, p)
+	}
+	for j, v := range c.Target {
+		name := fmt.Sprintf("target%d", j)
+		if d, ok := c.defaults[name]; ok {
+			v = d
+		}
+		params = append(params, dynamo.Param{
+			Name: name, Default: v,
+			Min: -dynamo.Unbounded, Max: dynamo.Unbounded,
+			Description: fmt.Sprintf("setpoint for x[%d]", j),
+		})
+	}
+	return params
+}
+
+func (c *MPC) GetParams() map[string]float64 {
+	values := map[string]float64{
+		"horizon":           float64(c.Horizon),
+		"dt":                c.Dt,
+		"max_iter":          float64(c.MaxIter),
+		"constraint_weight": c.ConstraintWeight,
+	}
+	for j, v := range c.Target {
+		values[fmt.Sprintf("target%d", j)] = v
+	}
+	return values
+}
+
+func (c *MPC) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(c.Params(), name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "horizon":
+		c.Horizon = int(value)
+	case "dt":
+		c.Dt = value
+		c.solved = false
+	case "max_iter":
+		c.MaxIter = int(value)
+	case "constraint_weight":
+		c.ConstraintWeight = value
+	default:
+		var j int
+		fmt.Sscanf(name, "target%d", &j)
+		c.Target[j] = value
+	}
+	return nil
+}
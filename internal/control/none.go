@@ -15,3 +15,12 @@ func NewNone(dim int) *None {
 func (n *None) Compute(x dynamo.State, t float64) dynamo.Control {
 	return make(dynamo.Control, n.dim)
 }
+
+// Params implements dynamo.Parameterized; None has no parameters.
+func (n *None) Params() []dynamo.Param { return nil }
+
+func (n *None) GetParams() map[string]float64 { return map[string]float64{} }
+
+func (n *None) SetParam(name string, value float64) error {
+	return dynamo.CheckParam(nil, name, value)
+}
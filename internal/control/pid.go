@@ -1,84 +1,328 @@
 package control
 
-import "github.com/san-kum/dynsim/internal/dynamo"
+import (
+	"fmt"
+	"math"
+	"strings"
 
-type PID struct {
-	Kp       float64
-	Ki       float64
-	Kd       float64
-	Target   float64
-	integral float64
-	prevErr  float64
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// AntiWindup selects how a loop keeps its integral from winding up while
+// the output is saturated.
+type AntiWindup int
+
+const (
+	// WindupNone integrates regardless of saturation.
+	WindupNone AntiWindup = iota
+	// WindupClamp stops integrating while the error drives the output
+	// further into saturation.
+	WindupClamp
+	// WindupBackCalc bleeds the integral by the saturation excess over the
+	// tracking time constant Tt.
+	WindupBackCalc
+)
+
+// ParseAntiWindup maps "none", "clamp" and "back_calculation" to their
+// modes. An empty name is none.
+func ParseAntiWindup(name string) (AntiWindup, error) {
+	switch name {
+	case "", "none":
+		return WindupNone, nil
+	case "clamp":
+		return WindupClamp, nil
+	case "back_calculation", "backcalc":
+		return WindupBackCalc, nil
+	}
+	return 0, fmt.Errorf("unknown anti-windup: %s", name)
+}
+
+// Loop is one PID channel. It measures state State and computes
+//
+//	v = Bias + Kp(Beta·r - y) + Ki∫(r - y) + Kd·d/dt(Gamma·r - y)
+//
+// saturated to [Min, Max], where r is Target, Ref when set, or, with From
+// set, the output of that earlier loop. The derivative passes through a
+// first-order filter with time constant Tf. The output is added to the
+// controls weighted by Mix; a loop with no Mix only feeds other loops. Use
+// NewLoop for the defaults.
+type Loop struct {
+	Name  string
+	State int
+	Mix   []float64
+	From  string
+
+	Kp, Ki, Kd float64
+	Target     float64
+	Ref        dynamo.Reference
+	// Beta and Gamma weight the setpoint in the proportional and derivative
+	// terms. Gamma = 0 differentiates the measurement alone, so setpoint
+	// steps do not kick the output.
+	Beta, Gamma float64
+	Tf          float64
+	Bias        float64
+	Min, Max    float64
+	AntiWindup  AntiWindup
+	// Tt is the back-calculation tracking time constant; 0 uses
+	// sqrt(Kd/Ki), or Kp/Ki without derivative action.
+	Tt float64
+
+	from     int
+	started  bool
 	prevT    float64
-	first    bool
+	prevE    float64 // previous derivative error
+	integral float64 // Ki∫e, in output units
+	deriv    float64 // filtered derivative
+	raw, out float64 // last output before and after saturation
 }
 
-func NewPID(kp, ki, kd, target float64) *PID {
-	return &PID{
-		Kp:     kp,
-		Ki:     ki,
-		Kd:     kd,
+// NewLoop returns a loop from state to the single control ctrl with
+// unit proportional setpoint weight, derivative on measurement, no
+// filter and no saturation. A negative ctrl leaves Mix empty.
+func NewLoop(state, ctrl int, kp, ki, kd, target float64) Loop {
+	l := Loop{
+		State: state,
+		Kp:    kp, Ki: ki, Kd: kd,
 		Target: target,
-		first:  true,
+		Beta:   1,
+		Min:    math.Inf(-1),
+		Max:    math.Inf(1),
+	}
+	if ctrl >= 0 {
+		l.Mix = make([]float64, ctrl+1)
+		l.Mix[ctrl] = 1
 	}
+	return l
 }
 
-func (p *PID) Compute(x dynamo.State, t float64) dynamo.Control {
-	if len(x) < 2 {
-		return dynamo.Control{0}
+// reset clears the integral and derivative state.
+func (l *Loop) reset() {
+	l.started = false
+	l.integral, l.deriv = 0, 0
+	l.raw, l.out = 0, 0
+}
+
+// update advances the loop to time t with setpoint r and measurement y and
+// returns the saturated output.
+func (l *Loop) update(r, y, t float64) float64 {
+	if l.started && t < l.prevT {
+		l.reset() // a new run
+	}
+	dt := 0.0
+	if l.started {
+		dt = t - l.prevT
+	}
+	e := r - y
+	ed := l.Gamma*r - y
+	if dt > 0 {
+		raw := (ed - l.prevE) / dt
+		if l.Tf > 0 {
+			l.deriv += dt / (l.Tf + dt) * (raw - l.deriv)
+		} else {
+			l.deriv = raw
+		}
 	}
+	l.started = true
+	l.prevE, l.prevT = ed, t
 
-	err := p.Target - x[0]
+	di := 0.0
+	if dt > 0 && l.Ki != 0 {
+		di = l.Ki * e * dt
+		if l.AntiWindup == WindupBackCalc {
+			di += math.Min(1, dt/l.tracking()) * (l.out - l.raw)
+		}
+		l.integral += di
+	}
 
-	if p.first {
-		p.prevErr = err
-		p.prevT = t
-		p.first = false
-		return dynamo.Control{p.Kp * err}
+	v := l.Bias + l.Kp*(l.Beta*r-y) + l.integral + l.Kd*l.deriv
+	sat := math.Max(l.Min, math.Min(l.Max, v))
+	if l.AntiWindup == WindupClamp && sat != v && (v-sat)*di > 0 {
+		l.integral -= di
+		v -= di
+		sat = math.Max(l.Min, math.Min(l.Max, v))
 	}
+	l.raw, l.out = v, sat
+	return sat
+}
 
-	dt := t - p.prevT
-	if dt > 0 {
-		p.integral += err * dt
-		derivative := (err - p.prevErr) / dt
+func (l *Loop) tracking() float64 {
+	switch {
+	case l.Tt > 0:
+		return l.Tt
+	case l.Kd != 0:
+		return math.Sqrt(math.Abs(l.Kd / l.Ki))
+	case l.Kp != 0:
+		return math.Abs(l.Kp / l.Ki)
+	}
+	return 1
+}
 
-		u := p.Kp*err + p.Ki*p.integral + p.Kd*derivative
+// PID runs a set of PID loops in order, so a loop can take its setpoint
+// from the output of an earlier one and loops cascade, e.g. position to
+// pitch to thrust. The control is the Mix-weighted sum of loop outputs.
+type PID struct {
+	Loops    []*Loop
+	dim      int
+	defaults map[string]float64
+}
+
+// NewPID returns a single loop from x[0] to the only control.
+func NewPID(kp, ki, kd, target float64) *PID {
+	p, _ := NewMultiPID(1, []Loop{NewLoop(0, 0, kp, ki, kd, target)})
+	return p
+}
+
+// NewMultiPID returns a PID with dim controls running loops. Loop names
+// must be unique, and From must name an earlier loop.
+func NewMultiPID(dim int, loops []Loop) (*PID, error) {
+	p := &PID{dim: dim}
+	names := make(map[string]int, len(loops))
+	for i := range loops {
+		l := loops[i]
+		if l.Name == "" && len(loops) > 1 {
+			l.Name = fmt.Sprintf("loop%d", i)
+		}
+		if _, dup := names[l.Name]; dup {
+			return nil, fmt.Errorf("pid: duplicate loop %q", l.Name)
+		}
+		names[l.Name] = i
+		if l.State < 0 {
+			return nil, fmt.Errorf("pid: loop %q: negative state index", l.Name)
+		}
+		if len(l.Mix) > dim {
+			return nil, fmt.Errorf("pid: loop %q: mix has %d weights for %d controls", l.Name, len(l.Mix), dim)
+		}
+		if l.Min > l.Max {
+			return nil, fmt.Errorf("pid: loop %q: min %g above max %g", l.Name, l.Min, l.Max)
+		}
+		l.from = -1
+		if l.From != "" {
+			j, ok := names[l.From]
+			if !ok || j == i {
+				return nil, fmt.Errorf("pid: loop %q: from %q is not an earlier loop", l.Name, l.From)
+			}
+			l.from = j
+		}
+		l.reset()
+		p.Loops = append(p.Loops, &l)
+	}
+	p.defaults = p.GetParams()
+	return p, nil
+}
 
-		p.prevErr = err
-		p.prevT = t
+func (p *PID) Compute(x dynamo.State, t float64) dynamo.Control {
+	u := make(dynamo.Control, p.dim)
+	for _, l := range p.Loops {
+		if l.State >= len(x) {
+			continue
+		}
+		r := l.Target
+		if l.Ref != nil {
+			r = l.Ref.Value(t)
+		}
+		if l.from >= 0 {
+			r = p.Loops[l.from].out
+		}
+		v := l.update(r, x[l.State], t)
+		for j, w := range l.Mix {
+			u[j] += w * v
+		}
+	}
+	return u
+}
 
-		return dynamo.Control{u}
+// Track implements dynamo.Tracker: every loop on state i that does not
+// cascade follows ref.
+func (p *PID) Track(i int, ref dynamo.Reference) error {
+	found := false
+	for _, l := range p.Loops {
+		if l.State == i && l.from < 0 {
+			l.Ref, found = ref, true
+		}
+	}
+	if !found {
+		return fmt.Errorf("pid: no loop regulates state %d", i)
 	}
-	return dynamo.Control{p.Kp * err}
+	return nil
 }
 
 // Reset clears integral and derivative state
 func (p *PID) Reset() {
-	p.integral = 0
-	p.prevErr = 0
-	p.first = true
+	for _, l := range p.Loops {
+		l.reset()
+	}
+}
+
+var pidParams = []dynamo.Param{
+	{Name: "Kp", Default: 10.0, Min: -1e4, Max: 1e4, Description: "proportional gain (negative for reverse action)"},
+	{Name: "Ki", Default: 0.1, Min: -1e4, Max: 1e4, Description: "integral gain"},
+	{Name: "Kd", Default: 5.0, Min: -1e4, Max: 1e4, Description: "derivative gain"},
+	{Name: "Target", Default: 0, Min: -dynamo.Unbounded, Max: dynamo.Unbounded, Description: "setpoint"},
+}
+
+// paramName qualifies a parameter with the loop name; a single unnamed
+// loop keeps the plain names.
+func paramName(l *Loop, name string) string {
+	if l.Name == "" {
+		return name
+	}
+	return l.Name + "." + name
+}
+
+// Params implements dynamo.Parameterized: the gains of every loop, and the
+// target of loops that do not cascade, as "<loop>.Kp" and so on.
+func (p *PID) Params() []dynamo.Param {
+	var params []dynamo.Param
+	for _, l := range p.Loops {
+		for _, param := range pidParams {
+			if param.Name == "Target" && l.from >= 0 {
+				continue
+			}
+			param.Name = paramName(l, param.Name)
+			if v, ok := p.defaults[param.Name]; ok {
+				param.Default = v
+			}
+			params = append(params, param)
+		}
+	}
+	return params
 }
 
 // GetParams returns tunable parameters for live adjustment
 func (p *PID) GetParams() map[string]float64 {
-	return map[string]float64{
-		"Kp":     p.Kp,
-		"Ki":     p.Ki,
-		"Kd":     p.Kd,
-		"Target": p.Target,
+	values := make(map[string]float64, 4*len(p.Loops))
+	for _, l := range p.Loops {
+		values[paramName(l, "Kp")] = l.Kp
+		values[paramName(l, "Ki")] = l.Ki
+		values[paramName(l, "Kd")] = l.Kd
+		if l.from < 0 {
+			values[paramName(l, "Target")] = l.Target
+		}
 	}
+	return values
 }
 
 // SetParam adjusts a PID parameter
-func (p *PID) SetParam(name string, value float64) {
-	switch name {
-	case "Kp":
-		p.Kp = value
-	case "Ki":
-		p.Ki = value
-	case "Kd":
-		p.Kd = value
-	case "Target":
-		p.Target = value
+func (p *PID) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(p.Params(), name, value); err != nil {
+		return err
+	}
+	for _, l := range p.Loops {
+		field, ok := strings.CutPrefix(name, paramName(l, ""))
+		if !ok || strings.Contains(field, ".") {
+			continue
+		}
+		switch field {
+		case "Kp":
+			l.Kp = value
+		case "Ki":
+			l.Ki = value
+		case "Kd":
+			l.Kd = value
+		case "Target":
+			l.Target = value
+		}
+		return nil
 	}
+	return nil
 }
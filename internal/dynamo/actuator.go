@@ -0,0 +1,10 @@
+package dynamo
+
+// Actuator shapes a control signal on its way to the plant. Apply maps the
+// input commanded at time t to the one delivered until the next call; the
+// simulator calls it every step, see Simulator.SetActuators, so actuator
+// dynamics advance with the plant even when the controller is sampled.
+// Actuators with memory restart when t goes backwards.
+type Actuator interface {
+	Apply(u Control, t float64) Control
+}
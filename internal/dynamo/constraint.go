@@ -0,0 +1,10 @@
+package dynamo
+
+// Constrained is implemented by systems whose states must satisfy
+// algebraic constraints, such as the closure of a linkage. Integration lets
+// states drift off the constraint manifold; Residual measures by how much
+// and Project maps a state back onto it.
+type Constrained interface {
+	Residual(x State) State
+	Project(x State) State
+}
@@ -0,0 +1,149 @@
+package dynamo
+
+import (
+	"math"
+	"sort"
+)
+
+// DelaySystem is a delay differential equation whose derivative also reads
+// past states, dx/dt = f(x(t), x(t-τ1), ..., x(t-τk), u, t). Its plain
+// Derive is the zero-lag limit, reading every delayed term as x(t); only a
+// delay-aware integrator such as the method of steps feeds it the history.
+type DelaySystem interface {
+	System
+	// Delays returns the lags τ the derivative reads, all positive.
+	Delays() []float64
+	DeriveDelayed(x State, past *History, u Control, t float64) State
+}
+
+// History records a trajectory so past states can be looked up between
+// the recorded samples. Samples older than Span behind the newest are
+// dropped; lookups before the first sample use Initial, or hold the first
+// state when Initial is nil.
+type History struct {
+	Span    float64
+	Initial func(t float64) State
+
+	times  []float64
+	states []State
+}
+
+func NewHistory(span float64) *History {
+	return &History{Span: span}
+}
+
+// Push records x at time t. Samples at or after t are discarded first, so
+// a rejected or repeated step simply overwrites its own future. Samples
+// within rounding of t count as at t; near-duplicate times would wreck the
+// interpolation.
+func (h *History) Push(t float64, x State) {
+	i := sort.SearchFloat64s(h.times, t-1e-12*math.Max(1, math.Abs(t)))
+	h.times = append(h.times[:i], t)
+	h.states = append(h.states[:i], x.Clone())
+
+	// Keep two samples beyond the span for the interpolation stencil and
+	// compact only once half the buffer is stale.
+	cut := sort.SearchFloat64s(h.times, t-h.Span) - 2
+	if cut > len(h.times)/2 {
+		h.times = append(h.times[:0], h.times[cut:]...)
+		h.states = append(h.states[:0], h.states[cut:]...)
+	}
+}
+
+// Clone returns a copy that later pushes to either history leave alone.
+func (h *History) Clone() *History {
+	c := *h
+	c.times = append([]float64(nil), h.times...)
+	c.states = append([]State(nil), h.states...)
+	return &c
+}
+
+// End returns the time of the newest sample, or 0 for an empty history.
+func (h *History) End() float64 {
+	if len(h.times) == 0 {
+		return 0
+	}
+	return h.times[len(h.times)-1]
+}
+
+// At returns the state at time t by cubic interpolation through the four
+// nearest samples. Times after the newest sample hold the newest state.
+// An empty history returns nil.
+func (h *History) At(t float64) State {
+	n := len(h.times)
+	if n == 0 {
+		return nil
+	}
+	if t < h.times[0] && h.Initial != nil {
+		return h.Initial(t)
+	}
+	if t <= h.times[0] {
+		return h.states[0].Clone()
+	}
+	if t >= h.times[n-1] {
+		return h.states[n-1].Clone()
+	}
+
+	i := sort.SearchFloat64s(h.times, t)
+	lo, hi := i-2, i+2
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > n {
+		hi = n
+	}
+	return lagrange(h.times[lo:hi], h.states[lo:hi], t)
+}
+
+// lagrange evaluates the polynomial through (ts[k], xs[k]) at t.
+func lagrange(ts []float64, xs []State, t float64) State {
+	out := make(State, len(xs[0]))
+	for k := range ts {
+		w := 1.0
+		for j := range ts {
+			if j != k {
+				w *= (t - ts[j]) / (ts[k] - ts[j])
+			}
+		}
+		for i := range out {
+			out[i] += w * xs[k][i]
+		}
+	}
+	return out
+}
+
+// WithHistory returns dyn as an ordinary System whose Derive reads delayed
+// terms from past, so any integrator can advance a delay system by steps no
+// longer than its shortest lag. Systems without delays are returned as is.
+func WithHistory(dyn System, past *History) System {
+	ds, ok := Unwrap(dyn).(DelaySystem)
+	if !ok {
+		return dyn
+	}
+	return &historySystem{System: dyn, delay: ds, past: past}
+}
+
+type historySystem struct {
+	System
+	delay DelaySystem
+	past  *History
+}
+
+// Derive calls DeriveDelayed in place of the wrapped Derive, so it reports
+// the evaluation to the first wrapper down the chain that counts them.
+func (h *historySystem) Derive(x State, u Control, t float64) State {
+	for sys := h.System; sys != nil; {
+		if c, ok := sys.(evalCounter); ok {
+			c.countEval()
+			break
+		}
+		w, ok := sys.(interface{ Unwrap() System })
+		if !ok {
+			break
+		}
+		sys = w.Unwrap()
+	}
+	return h.delay.DeriveDelayed(x, h.past, u, t)
+}
+
+func (h *historySystem) Unwrap() System { return h.System }
@@ -0,0 +1,109 @@
+package dynamo
+
+import "math"
+
+// Interpolant is the continuous extension of a single step: it returns the
+// solution at fraction theta in [0, 1] of the way through the step.
+type Interpolant func(theta float64) State
+
+// DenseIntegrator is an adaptive integrator that also provides dense output,
+// so states can be recovered anywhere inside a step without re-integrating.
+type DenseIntegrator interface {
+	AdaptiveIntegrator
+	StepDense(dyn System, x State, u Control, t, dt, tol float64) (State, float64, Interpolant, error)
+}
+
+// HermiteInterpolant builds a cubic Hermite interpolant across a step of
+// length h from x0 to x1, using the derivatives at both ends. It is the
+// fallback for integrators without dense output.
+func HermiteInterpolant(dyn System, x0, x1 State, u Control, t, h float64) Interpolant {
+	f0 := dyn.Derive(x0, u, t)
+	f1 := dyn.Derive(x1, u, t+h)
+	return func(theta float64) State {
+		t2 := theta * theta
+		t3 := t2 * theta
+		h00 := 2*t3 - 3*t2 + 1
+		h10 := t3 - 2*t2 + theta
+		h01 := -2*t3 + 3*t2
+		h11 := t3 - t2
+		x := make(State, len(x0))
+		for i := range x {
+			x[i] = h00*x0[i] + h10*h*f0[i] + h01*x1[i] + h11*h*f1[i]
+		}
+		return x
+	}
+}
+
+// outputGrid tracks the uniform grid 0, dt, 2dt, ... of Config.OutputDt.
+type outputGrid struct {
+	dt   float64
+	tol  float64
+	next int
+}
+
+func newOutputGrid(cfg Config) *outputGrid {
+	if cfg.OutputDt <= 0 {
+		return nil
+	}
+	return &outputGrid{dt: cfg.OutputDt, tol: 1e-9 * cfg.OutputDt}
+}
+
+// within returns the grid times in [t0, t1). The tolerance keeps the
+// intervals of consecutive steps from both claiming, or both missing, a
+// grid point that falls on a step boundary.
+func (g *outputGrid) within(t0, t1 float64) []float64 {
+	var times []float64
+	for {
+		tau := float64(g.next) * g.dt
+		if tau >= t1-g.tol {
+			return times
+		}
+		if tau >= t0-g.tol {
+			times = append(times, tau)
+		}
+		g.next++
+	}
+}
+
+// snap returns the grid time nearest t if t lies on the grid, else t.
+func (g *outputGrid) snap(t float64) float64 {
+	tau := math.Round(t/g.dt) * g.dt
+	if math.Abs(t-tau) <= g.tol {
+		return tau
+	}
+	return t
+}
+
+// Resample linearly interpolates a recorded trajectory onto the uniform
+// grid t0, t0+dt, ... up to the last recorded time. It is meant for stored
+// runs; live runs should set Config.OutputDt instead.
+func Resample(times []float64, states []State, dt float64) ([]float64, []State) {
+	if len(times) == 0 || dt <= 0 {
+		return nil, nil
+	}
+	t0, tEnd := times[0], times[len(times)-1]
+	n := int(math.Floor((tEnd-t0)/dt*(1+1e-9))) + 1
+
+	outTimes := make([]float64, 0, n)
+	outStates := make([]State, 0, n)
+	j := 0
+	for k := 0; k < n; k++ {
+		tau := t0 + float64(k)*dt
+		for j < len(times)-2 && times[j+1] <= tau {
+			j++
+		}
+		var x State
+		if j+1 >= len(times) || times[j+1] == times[j] {
+			x = states[j].Clone()
+		} else {
+			w := math.Max(0, math.Min(1, (tau-times[j])/(times[j+1]-times[j])))
+			x = make(State, len(states[j]))
+			for i := range x {
+				x[i] = (1-w)*states[j][i] + w*states[j+1][i]
+			}
+		}
+		outTimes = append(outTimes, tau)
+		outStates = append(outStates, x)
+	}
+	return outTimes, outStates
+}
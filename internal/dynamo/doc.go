@@ -7,6 +7,11 @@
 //   - [System]: interface for ODE systems (dX/dt = f(X, u, t))
 //   - [Stepper]: numerical integrator interface
 //   - [Controller]: feedback controller interface
+//   - [Sensor], [Estimator]: noisy measurements and the state estimate a
+//     controller acts on in their place
+//   - [Actuator]: shapes the control between controller and plant
+//   - [Reference], [Tracker]: time-varying setpoints and the controllers
+//     that follow them
 //   - [Simulator]: orchestrates simulation runs
 //
 // # Example
@@ -19,5 +24,6 @@
 // # Thread Safety
 //
 // Simulator instances are NOT thread-safe. For parallel simulations,
-// use the [Ensemble] type which safely manages multiple simulation runs.
+// use the [Ensemble] type, which builds fresh instances for every member
+// from a [Factory].
 package dynamo
@@ -0,0 +1,293 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Factory builds fresh instances for each ensemble member, so members never
+// share integrator scratch buffers, controller state or metric accumulators.
+// Metrics may be nil.
+type Factory struct {
+	System     func() System
+	Integrator func() Integrator
+	Controller func() Controller
+	Metrics    func() []Metric
+}
+
+// Perturbation describes how ensemble members differ from the nominal run.
+// Member i draws from its own generator seeded with Config.Seed + i, so an
+// ensemble is reproducible regardless of scheduling.
+type Perturbation struct {
+	// StateStd is the standard deviation of Gaussian noise added to each
+	// initial state component; a single value applies to every component.
+	StateStd []float64
+
+	// ParamStd is the relative standard deviation of Gaussian noise applied
+	// to model parameters, by name. Perturbed values are clamped to the
+	// model's parameter schema.
+	ParamStd map[string]float64
+}
+
+// Ensemble runs many independent simulations of perturbed copies of a
+// system in parallel.
+type Ensemble struct {
+	factory Factory
+	numRuns int
+
+	// Workers bounds how many members run at once; zero uses GOMAXPROCS.
+	Workers int
+
+	Perturb Perturbation
+
+	// Percentiles lists the envelopes reported in EnsembleStats, in
+	// percent. Defaults to 5, 50 and 95.
+	Percentiles []float64
+}
+
+// Member is one run of an ensemble.
+type Member struct {
+	Index     int
+	Seed      int64
+	InitState State
+	Params    map[string]float64 // perturbed parameters only
+	Result    *Result
+	Err       error
+}
+
+// EnsembleStats are statistics across members at each recorded time.
+// Envelopes[k][i] is the Percentiles[k]-th percentile at Times[i].
+type EnsembleStats struct {
+	Times       []float64
+	Mean        []State
+	Std         []State
+	Percentiles []float64
+	Envelopes   [][]State
+}
+
+type EnsembleResult struct {
+	Members []Member
+
+	// Stats is nil when members were recorded at different times, as
+	// adaptive or event-terminated runs are; set Config.OutputDt to align
+	// them.
+	Stats *EnsembleStats
+}
+
+func NewEnsemble(f Factory, numRuns int) *Ensemble {
+	return &Ensemble{factory: f, numRuns: numRuns}
+}
+
+// Run simulates every member from a perturbation of x0. Members that fail
+// keep their error in Member.Err; Run reports the first of them and leaves
+// them out of the statistics.
+func (e *Ensemble) Run(ctx context.Context, x0 State, cfg Config) (*EnsembleResult, error) {
+	if e.factory.System == nil || e.factory.Integrator == nil || e.factory.Controller == nil {
+		return nil, fmt.Errorf("ensemble factory needs a system, integrator and controller")
+	}
+	if e.numRuns <= 0 {
+		return nil, fmt.Errorf("ensemble needs at least one run, got %d", e.numRuns)
+	}
+
+	workers := e.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	members := make([]Member, e.numRuns)
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := range members {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(idx int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			members[idx] = e.runMember(ctx, idx, x0, cfg)
+		}(i)
+	}
+	wg.Wait()
+
+	result := &EnsembleResult{Members: members}
+	result.Stats = e.stats(members)
+
+	for _, m := range members {
+		if m.Err != nil {
+			return result, fmt.Errorf("ensemble member %d: %w", m.Index, m.Err)
+		}
+	}
+	return result, nil
+}
+
+func (e *Ensemble) runMember(ctx context.Context, idx int, x0 State, cfg Config) Member {
+	m := Member{Index: idx, Seed: cfg.Seed + int64(idx)}
+	rng := rand.New(rand.NewSource(m.Seed))
+
+	dyn := e.factory.System()
+	params, err := e.Perturb.applyParams(rng, dyn)
+	if err != nil {
+		m.Err = err
+		return m
+	}
+	m.Params = params
+	m.InitState = e.Perturb.applyState(rng, x0)
+
+	sim := New(dyn, e.factory.Integrator(), e.factory.Controller())
+	if e.factory.Metrics != nil {
+		for _, metric := range e.factory.Metrics() {
+			sim.AddMetric(metric)
+		}
+	}
+
+	memberCfg := cfg
+	memberCfg.Seed = m.Seed
+	m.Result, m.Err = sim.Run(ctx, m.InitState, memberCfg)
+	return m
+}
+
+func (p Perturbation) applyState(rng *rand.Rand, x0 State) State {
+	x := x0.Clone()
+	if len(p.StateStd) == 0 {
+		return x
+	}
+	for i := range x {
+		std := p.StateStd[0]
+		if len(p.StateStd) > 1 {
+			if i >= len(p.StateStd) {
+				break
+			}
+			std = p.StateStd[i]
+		}
+		x[i] += std * rng.NormFloat64()
+	}
+	return x
+}
+
+func (p Perturbation) applyParams(rng *rand.Rand, dyn System) (map[string]float64, error) {
+	if len(p.ParamStd) == 0 {
+		return nil, nil
+	}
+	conf, ok := dyn.(Configurable)
+	if !ok {
+		return nil, fmt.Errorf("model has no tunable parameters to perturb")
+	}
+
+	// draw in name order so a seed always maps to the same values
+	names := make([]string, 0, len(p.ParamStd))
+	for name := range p.ParamStd {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	current := conf.GetParams()
+	params := make(map[string]float64, len(names))
+	for _, name := range names {
+		base, ok := current[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown param: %s", name)
+		}
+		value := base * (1 + p.ParamStd[name]*rng.NormFloat64())
+		if pz, ok := dyn.(Parameterized); ok {
+			if schema, ok := LookupParam(pz.Params(), name); ok {
+				value = schema.Clamp(value)
+			}
+		}
+		if err := conf.SetParam(name, value); err != nil {
+			return nil, err
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// stats aggregates the successful members, or returns nil if they were not
+// recorded at the same times.
+func (e *Ensemble) stats(members []Member) *EnsembleStats {
+	var runs []*Result
+	for _, m := range members {
+		if m.Err == nil && m.Result != nil && len(m.Result.States) > 0 {
+			runs = append(runs, m.Result)
+		}
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+	times := runs[0].Times
+	for _, r := range runs[1:] {
+		if len(r.Times) != len(times) {
+			return nil
+		}
+		for i, t := range r.Times {
+			if math.Abs(t-times[i]) > 1e-9*math.Max(1, math.Abs(t)) {
+				return nil
+			}
+		}
+	}
+
+	percentiles := e.Percentiles
+	if len(percentiles) == 0 {
+		percentiles = []float64{5, 50, 95}
+	}
+
+	n := len(runs[0].States[0])
+	stats := &EnsembleStats{
+		Times:       append([]float64(nil), times...),
+		Mean:        make([]State, len(times)),
+		Std:         make([]State, len(times)),
+		Percentiles: percentiles,
+		Envelopes:   make([][]State, len(percentiles)),
+	}
+	for k := range stats.Envelopes {
+		stats.Envelopes[k] = make([]State, len(times))
+	}
+
+	values := make([]float64, len(runs))
+	for i := range times {
+		mean, std := make(State, n), make(State, n)
+		for k := range percentiles {
+			stats.Envelopes[k][i] = make(State, n)
+		}
+		for j := 0; j < n; j++ {
+			sum := 0.0
+			for r, run := range runs {
+				values[r] = run.States[i][j]
+				sum += values[r]
+			}
+			mean[j] = sum / float64(len(runs))
+			ss := 0.0
+			for _, v := range values {
+				ss += (v - mean[j]) * (v - mean[j])
+			}
+			if len(runs) > 1 {
+				std[j] = math.Sqrt(ss / float64(len(runs)-1))
+			}
+			sort.Float64s(values)
+			for k, p := range percentiles {
+				stats.Envelopes[k][i][j] = percentile(values, p)
+			}
+		}
+		stats.Mean[i], stats.Std[i] = mean, std
+	}
+	return stats
+}
+
+// percentile interpolates linearly between the order statistics of sorted.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := math.Max(0, math.Min(1, p/100)) * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo]*(1-frac) + sorted[lo+1]*frac
+}
@@ -19,6 +19,20 @@ var (
 	// ErrStepTooSmall indicates adaptive timestep became too small.
 	ErrStepTooSmall = errors.New("dynamo: adaptive timestep below minimum")
 
+	// ErrStepRejected is returned by AdaptiveIntegrator.StepAdaptive when the
+	// step's error estimate exceeds the tolerance. The step must be retried
+	// with the suggested dt.
+	ErrStepRejected = errors.New("dynamo: adaptive step rejected")
+
+	// ErrNoConvergence is returned by FallibleIntegrator.TryStep when an
+	// implicit solve fails, because the iteration matrix is singular or the
+	// Newton iteration does not converge.
+	ErrNoConvergence = errors.New("dynamo: implicit solve did not converge")
+
+	// ErrZeno indicates a hybrid system's guards kept firing without time
+	// advancing, as in a ball bouncing infinitely often before coming to rest.
+	ErrZeno = errors.New("dynamo: too many resets in one step (zeno behaviour)")
+
 	// ErrDimensionMismatch indicates mismatched state/control dimensions.
 	ErrDimensionMismatch = errors.New("dynamo: dimension mismatch between state and system")
 )
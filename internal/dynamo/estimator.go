@@ -0,0 +1,24 @@
+package dynamo
+
+// Sensor measures the plant for an Estimator. Measure returns nil between
+// samples. Reset restarts the sample clock and reseeds any noise.
+type Sensor interface {
+	Measure(x State, t float64) []float64
+	Reset(seed int64)
+}
+
+// Estimator reconstructs the state from sensor measurements. With one set,
+// see Simulator.SetEstimator, the controller sees the estimate instead of
+// the true state.
+type Estimator interface {
+	// Reset starts a run whose true initial state is x0. Estimators may
+	// start from a guess of their own instead.
+	Reset(x0 State, t float64)
+	// Update advances the estimate to t under u, the control held since
+	// the previous call, corrects it with the measurement y unless y is
+	// nil, and returns it.
+	Update(y []float64, u Control, t float64) State
+	Estimate() State
+	// CovarianceTrace is the trace of the estimate's error covariance.
+	CovarianceTrace() float64
+}
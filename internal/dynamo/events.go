@@ -0,0 +1,221 @@
+package dynamo
+
+import "math"
+
+// Direction selects which zero crossings of an event function count.
+type Direction int
+
+const (
+	CrossEither  Direction = iota // any sign change
+	CrossRising                   // g goes from negative to non-negative
+	CrossFalling                  // g goes from positive to non-positive
+)
+
+// Event watches a zero-crossing condition g(t, x) = 0 during a run. A
+// terminal event stops the run at the located crossing. An event with a
+// Reset is a guard: the run jumps to Reset(t, x) at the crossing and
+// carries on from there.
+type Event struct {
+	Name      string
+	Fn        func(t float64, x State) float64
+	Direction Direction
+	Terminal  bool
+	Reset     func(t float64, x State) State
+}
+
+// EventRecord is an event occurrence, located to within eventTol of the
+// true crossing time. State is the state at the crossing, before any reset.
+type EventRecord struct {
+	Name     string
+	Time     float64
+	State    State
+	Terminal bool
+	Reset    bool
+}
+
+// HybridSystem is a system with discrete jumps: impacts, switches and other
+// resets. Its guards are watched in every run alongside the simulator's own
+// events.
+type HybridSystem interface {
+	System
+	Guards() []Event
+}
+
+const (
+	eventTol     = 1e-10
+	maxEventIter = 60
+
+	// maxResets bounds the resets within one step. Exceeding it means the
+	// guards fire faster than time advances (Zeno behaviour).
+	maxResets = 1000
+)
+
+// Crosses reports whether g going from g0 to g1 is a crossing this event
+// watches.
+func (e Event) Crosses(g0, g1 float64) bool {
+	rising := g0 < 0 && g1 >= 0
+	falling := g0 > 0 && g1 <= 0
+	switch e.Direction {
+	case CrossRising:
+		return rising
+	case CrossFalling:
+		return falling
+	default:
+		return rising || falling
+	}
+}
+
+// runEvents returns the model's guards followed by the simulator's events.
+func (s *Simulator) runEvents() []Event {
+	var events []Event
+	if hs, ok := Unwrap(s.dyn).(HybridSystem); ok {
+		events = append(events, hs.Guards()...)
+	}
+	return append(events, s.events...)
+}
+
+// segment is the part of a step that runs from t until stop, the next
+// reset or terminal event, or t+h. It was integrated from (t, x0) to
+// (t+h, x1); interp, if set, is the integrator's dense output for that.
+// rewind restores the integrator to its state before that step.
+type segment struct {
+	t, h, stop float64
+	x0, x1     State
+	interp     Interpolant
+	rewind     func()
+}
+
+// resolveEvents handles the events across the step seg, which rewind
+// undoes. Each reset splits the step: the remainder is integrated again
+// from the reset state, so the step still ends at t+h. It returns the
+// segments the step was split into, the last of which ends at the terminal
+// event if one fired.
+func (s *Simulator) resolveEvents(sys System, events []Event, seg segment, u Control, rewind func(), result *Result) ([]segment, *EventRecord, error) {
+	end := seg.t + seg.h
+	seg.rewind = rewind
+	var segs []segment
+	for resets := 0; ; resets++ {
+		occurred, cut := s.detectEvents(sys, events, seg, u, resets > 0)
+		result.Events = append(result.Events, occurred...)
+		if cut == nil {
+			return append(segs, seg), nil, nil
+		}
+		rec := occurred[len(occurred)-1]
+		seg.stop = rec.Time
+		segs = append(segs, seg)
+		if rec.Terminal {
+			return segs, &rec, nil
+		}
+		if resets == maxResets {
+			return nil, nil, &SimulationError{Time: rec.Time, State: rec.State, Wrapped: ErrZeno}
+		}
+
+		xr := cut.Reset(rec.Time, rec.State.Clone())
+		seg = segment{t: rec.Time, h: end - rec.Time, stop: end, x0: xr, x1: xr, rewind: s.checkpoint()}
+		if seg.h > 0 {
+			seg.x1 = s.integrator.Step(sys, xr, u, seg.t, seg.h)
+		}
+	}
+}
+
+// detectEvents checks every event across the segment and returns the
+// occurrences in time order. When a terminal or
+// resetting event fires, later occurrences are dropped; it is the last
+// record and is also returned as the second value.
+//
+// After a reset, x usually lies exactly on the surface of the guard that
+// fired. Such a state counts as leaving the surface, so a return to it
+// within the same step is still seen as a crossing.
+func (s *Simulator) detectEvents(sys System, events []Event, seg segment, u Control, afterReset bool) ([]EventRecord, *Event) {
+	x, next, t, h := seg.x0, seg.x1, seg.t, seg.h
+	var found []EventRecord
+	var from []int
+	for k, ev := range events {
+		g0, g1 := ev.Fn(t, x), ev.Fn(t+h, next)
+		if afterReset && g0 == 0 {
+			g0 = -g1
+		}
+		if !ev.Crosses(g0, g1) {
+			continue
+		}
+		tau, xe := s.locateEvent(sys, ev, seg, u, g0, g1)
+		rec := EventRecord{Name: ev.Name, Time: t + tau, State: xe, Terminal: ev.Terminal, Reset: ev.Reset != nil}
+		i := len(found)
+		for i > 0 && found[i-1].Time > rec.Time {
+			i--
+		}
+		found = append(found, EventRecord{})
+		copy(found[i+1:], found[i:])
+		found[i] = rec
+		from = append(from, 0)
+		copy(from[i+1:], from[i:])
+		from[i] = k
+	}
+	for i, rec := range found {
+		if rec.Terminal || rec.Reset {
+			return found[:i+1], &events[from[i]]
+		}
+	}
+	return found, nil
+}
+
+// locateEvent finds the crossing inside the segment with the Illinois
+// variant of regula falsi, re-integrating from its start with partial steps.
+// It returns the offset into the segment and the state there. The partial
+// steps start from the integrator's state before the segment and leave it
+// as it was after.
+func (s *Simulator) locateEvent(sys System, ev Event, seg segment, u Control, g0, g1 float64) (float64, State) {
+	defer s.checkpoint()()
+	h := seg.h
+	a, b := 0.0, h
+	ga, gb := g0, g1
+	xb := State(nil)
+	side := 0
+	tol := eventTol * math.Max(1, math.Abs(h))
+
+	for iter := 0; iter < maxEventIter && b-a > tol; iter++ {
+		c := (a*gb - b*ga) / (gb - ga)
+		if !(c > a && c < b) {
+			c = 0.5 * (a + b)
+		}
+		xc := s.partialStep(sys, seg, u, c)
+		gc := ev.Fn(seg.t+c, xc)
+		if gc == 0 {
+			return c, xc
+		}
+		if (gc < 0) == (ga < 0) {
+			a, ga = c, gc
+			if side == -1 {
+				gb /= 2
+			}
+			side = -1
+		} else {
+			b, gb, xb = c, gc, xc
+			if side == 1 {
+				ga /= 2
+			}
+			side = 1
+		}
+	}
+
+	if xb == nil {
+		xb = s.partialStep(sys, seg, u, b)
+	}
+	return b, xb
+}
+
+// partialStep returns the state c into the segment. Stochastic integrators
+// would draw fresh noise on a re-integration, so their steps are
+// interpolated linearly instead.
+func (s *Simulator) partialStep(sys System, seg segment, u Control, c float64) State {
+	if _, ok := s.integrator.(StochasticIntegrator); !ok {
+		seg.rewind()
+		return s.integrator.Step(sys, seg.x0, u, seg.t, c)
+	}
+	w := c / seg.h
+	xc := make(State, len(seg.x0))
+	for i := range seg.x0 {
+		xc[i] = (1-w)*seg.x0[i] + w*seg.x1[i]
+	}
+	return xc
+}
@@ -0,0 +1,115 @@
+package dynamo
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/autodiff"
+)
+
+// Jacobian is implemented by models that supply the analytic state Jacobian
+// df/dx. Implicit integrators use it in place of finite differences.
+type Jacobian interface {
+	Jacobian(x State, u Control, t float64) [][]float64
+}
+
+// Differentiable is implemented by models that can evaluate their
+// right-hand side on dual numbers, which gives exact Jacobians by
+// forward-mode automatic differentiation. DeriveDual must compute the same
+// function as Derive.
+type Differentiable interface {
+	DeriveDual(x, u []autodiff.Dual, t float64) []autodiff.Dual
+}
+
+// StateJacobian returns df/dx at (x, u, t): from the model's own Jacobian
+// when it has one, by automatic differentiation when it is Differentiable
+// and by central differences otherwise.
+func StateJacobian(dyn System, x State, u Control, t float64) [][]float64 {
+	inner := Unwrap(dyn)
+	if j, ok := inner.(Jacobian); ok {
+		return j.Jacobian(x, u, t)
+	}
+	if d, ok := inner.(Differentiable); ok {
+		return dualJacobian(len(x), func(seed int) []autodiff.Dual {
+			return d.DeriveDual(seeded(x, seed), autodiff.Consts(u), t)
+		})
+	}
+	return FiniteJacobian(func(x []float64) []float64 { return dyn.Derive(x, u, t) }, x)
+}
+
+// ControlJacobian returns df/du at (x, u, t), by automatic differentiation
+// when the model is Differentiable and by central differences otherwise.
+// Models with no controls give a Jacobian with no columns.
+func ControlJacobian(dyn System, x State, u Control, t float64) [][]float64 {
+	m := dyn.ControlDim()
+	uf := make(Control, m)
+	copy(uf, u)
+	if m == 0 {
+		return newJacobian(len(dyn.Derive(x, uf, t)), 0)
+	}
+	if d, ok := Unwrap(dyn).(Differentiable); ok {
+		return dualJacobian(m, func(seed int) []autodiff.Dual {
+			return d.DeriveDual(autodiff.Consts(x), seeded(uf, seed), t)
+		})
+	}
+	return FiniteJacobian(func(u []float64) []float64 { return dyn.Derive(x, u, t) }, uf)
+}
+
+// seeded lifts v to duals with a unit derivative in component seed.
+func seeded(v []float64, seed int) []autodiff.Dual {
+	d := autodiff.Consts(v)
+	d[seed].Du = 1
+	return d
+}
+
+// dualJacobian assembles an n-column Jacobian from one dual evaluation per
+// column.
+func dualJacobian(n int, eval func(seed int) []autodiff.Dual) [][]float64 {
+	var jac [][]float64
+	for j := 0; j < n; j++ {
+		col := eval(j)
+		if jac == nil {
+			jac = newJacobian(len(col), n)
+		}
+		for i, d := range col {
+			jac[i][j] = d.Du
+		}
+	}
+	return jac
+}
+
+func newJacobian(rows, cols int) [][]float64 {
+	jac := make([][]float64, rows)
+	for i := range jac {
+		jac[i] = make([]float64, cols)
+	}
+	return jac
+}
+
+// FiniteJacobian approximates the Jacobian of f at x by central
+// differences. Each step is scaled to its component, h = ε^(1/3)·max(|x|, 1),
+// which balances truncation against rounding error, and is rounded so that
+// x+h and x-h are exactly representable.
+func FiniteJacobian(f func([]float64) []float64, x []float64) [][]float64 {
+	n := len(x)
+	if n == 0 {
+		return newJacobian(len(f(x)), 0)
+	}
+	var jac [][]float64
+	xp := append([]float64(nil), x...)
+	for j := 0; j < n; j++ {
+		h := math.Cbrt(2.2e-16) * math.Max(math.Abs(x[j]), 1)
+		xp[j] = x[j] + h
+		h = xp[j] - x[j]
+		fp := f(xp)
+		xp[j] = x[j] - h
+		fm := f(xp)
+		xp[j] = x[j]
+		if jac == nil {
+			jac = newJacobian(len(fp), n)
+		}
+		for i := range fp {
+			jac[i][j] = (fp[i] - fm[i]) / (2 * h)
+		}
+	}
+	return jac
+}
@@ -0,0 +1,14 @@
+package dynamo
+
+// Optimizer is implemented by controllers that choose their inputs by
+// solving an optimization problem, such as model predictive control.
+type Optimizer interface {
+	SolveStats() SolveStats
+}
+
+// SolveStats accumulates over every solve since a controller was built.
+type SolveStats struct {
+	Solves     int
+	Iterations int
+	Cost       float64 // sum of the optimal costs
+}
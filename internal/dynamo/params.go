@@ -0,0 +1,67 @@
+package dynamo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Param describes a tunable parameter of a model or controller.
+type Param struct {
+	Name        string
+	Default     float64
+	Min, Max    float64
+	Unit        string
+	Description string
+}
+
+// Parameterized is implemented by models and controllers that publish a
+// schema for their tunable parameters. SetParam rejects values outside
+// [Min, Max] with ErrParameterBounds.
+type Parameterized interface {
+	Configurable
+	Params() []Param
+}
+
+// Unbounded is used as Min or Max for parameters without a limit.
+var Unbounded = math.Inf(1)
+
+// Contains reports whether value lies within the parameter's bounds.
+func (p Param) Contains(value float64) bool {
+	return !math.IsNaN(value) && value >= p.Min && value <= p.Max
+}
+
+// Clamp limits value to the parameter's bounds.
+func (p Param) Clamp(value float64) float64 {
+	return math.Max(p.Min, math.Min(p.Max, value))
+}
+
+// LookupParam returns the schema entry for name.
+func LookupParam(schema []Param, name string) (Param, bool) {
+	for _, p := range schema {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// CheckParam validates a SetParam call against schema.
+func CheckParam(schema []Param, name string, value float64) error {
+	p, ok := LookupParam(schema, name)
+	if !ok {
+		return fmt.Errorf("unknown param: %s", name)
+	}
+	if !p.Contains(value) {
+		return fmt.Errorf("%w: %s=%g not in [%g, %g]", ErrParameterBounds, name, value, p.Min, p.Max)
+	}
+	return nil
+}
+
+// ParamDefaults returns the default value of every parameter in schema.
+func ParamDefaults(schema []Param) map[string]float64 {
+	values := make(map[string]float64, len(schema))
+	for _, p := range schema {
+		values[p.Name] = p.Default
+	}
+	return values
+}
@@ -0,0 +1,12 @@
+package dynamo
+
+// Reference is a time-varying setpoint for one state variable.
+type Reference interface {
+	Value(t float64) float64
+}
+
+// Tracker is implemented by controllers that can follow a Reference for
+// state variable i in place of their fixed target for it.
+type Tracker interface {
+	Track(i int, ref Reference) error
+}
@@ -0,0 +1,60 @@
+package dynamo
+
+import (
+	"math"
+	"math/rand"
+)
+
+// ControlSink is implemented by sinks that also record each update of a
+// sampled controller, see Config.ControlPeriod.
+type ControlSink interface {
+	WriteControl(t float64, u Control) error
+}
+
+// controlClock schedules the updates of a sampled controller. Update k is
+// due at k·period, delayed by a uniform random jitter of up to jitter.
+type controlClock struct {
+	period float64
+	jitter float64
+	due    float64
+	rng    *rand.Rand
+}
+
+// newControlClock returns nil when the controller runs every step.
+func newControlClock(cfg Config) *controlClock {
+	if cfg.ControlPeriod <= 0 {
+		return nil
+	}
+	return &controlClock{
+		period: cfg.ControlPeriod,
+		jitter: cfg.ControlJitter,
+		rng:    rand.New(rand.NewSource(cfg.Seed)),
+	}
+}
+
+// tick reports whether an update is due at t and, if so, schedules the
+// next one. A nil clock is always due.
+func (c *controlClock) tick(t float64) bool {
+	if c == nil {
+		return true
+	}
+	// tolerate round-off so updates land on the step that reaches them
+	if t < c.due-1e-9*c.period {
+		return false
+	}
+	next := math.Floor(t/c.period+1e-9) + 1
+	c.due = next*c.period + c.jitter*c.rng.Float64()
+	return true
+}
+
+// clamp shortens an adaptive step h from t so it ends on the next update,
+// unless that would leave less than MinDt before the end of the run.
+func (c *controlClock) clamp(cfg Config, t, h float64) float64 {
+	if c == nil {
+		return h
+	}
+	if left := c.due - t; left >= cfg.MinDt && left < h && cfg.Duration-c.due >= cfg.MinDt {
+		return left
+	}
+	return h
+}
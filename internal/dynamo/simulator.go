@@ -2,6 +2,7 @@ package dynamo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 )
@@ -12,6 +13,10 @@ type Simulator struct {
 	controller Controller
 	metrics    []Metric
 	observers  []Observer
+	events     []Event
+	sensor     Sensor
+	estimator  Estimator
+	actuators  []Actuator
 }
 
 func New(dyn System, integrator Integrator, controller Controller) *Simulator {
@@ -26,42 +31,144 @@ func New(dyn System, integrator Integrator, controller Controller) *Simulator {
 
 func (s *Simulator) AddMetric(m Metric)     { s.metrics = append(s.metrics, m) }
 func (s *Simulator) AddObserver(o Observer) { s.observers = append(s.observers, o) }
+func (s *Simulator) AddEvent(e Event)       { s.events = append(s.events, e) }
+
+// SetEstimator puts an estimator between the plant and the controller: each
+// step the sensor measures the true state, the estimator folds the
+// measurement in, and the controller acts on the estimate. Results then
+// record the estimate and its covariance trace alongside the state.
+func (s *Simulator) SetEstimator(sensor Sensor, est Estimator) {
+	s.sensor, s.estimator = sensor, est
+}
+
+// SetActuators puts a chain of actuators, applied in order, between the
+// controller and the plant. They run every step on the held controller
+// output, so the plant, the metrics and the recorded trajectory all see
+// the delivered input.
+func (s *Simulator) SetActuators(chain ...Actuator) {
+	s.actuators = chain
+}
 
+// Run simulates the system and returns the full trajectory in memory. Use
+// Stream for runs too long to buffer.
 func (s *Simulator) Run(ctx context.Context, x0 State, cfg Config) (*Result, error) {
 	if err := s.validateConfig(cfg); err != nil {
 		return nil, err
 	}
 
+	samples := int(cfg.Duration/cfg.Dt) + 1
+	if cfg.OutputDt > 0 {
+		samples = int(cfg.Duration/cfg.OutputDt) + 2
+	} else if cfg.SampleEvery > 1 {
+		samples = samples/cfg.SampleEvery + 2
+	}
+	result := s.newResult()
+	result.States = make([]State, 0, samples)
+	result.Controls = make([]Control, 0, samples)
+	result.Times = make([]float64, 0, samples)
+
+	err := s.run(ctx, x0, cfg, &collector{result: result}, result)
+	return result, err
+}
+
+// Stream simulates the system, pushing samples to sink instead of keeping
+// them, so memory use is independent of run length. The returned Result
+// carries metrics and diagnostics but no trajectory. Stream closes sink.
+func (s *Simulator) Stream(ctx context.Context, x0 State, cfg Config, sink Sink) (result *Result, err error) {
+	if err := s.validateConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	result = s.newResult()
+	defer func() {
+		if cerr := sink.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if err := sink.Begin(result.StateVars, result.ControlVars); err != nil {
+		return result, err
+	}
+	err = s.run(ctx, x0, cfg, sink, result)
+	return result, err
+}
+
+func (s *Simulator) newResult() *Result {
+	return &Result{
+		Metrics:     make(map[string]float64),
+		Errors:      make([]error, 0),
+		StateVars:   DescribeState(s.dyn),
+		ControlVars: DescribeControl(s.dyn),
+	}
+}
+
+// run is the integration loop shared by Run and Stream. Samples go to sink;
+// everything else is accumulated in result.
+//
+// Fixed-step runs take Duration/Dt steps of Dt. Adaptive runs start from Dt,
+// retry rejected steps with the size the integrator proposes, keep steps
+// within [MinDt, MaxDt] and shorten the last one to end exactly at Duration.
+// A failed implicit solve stops a fixed-step run with ErrNoConvergence;
+// adaptive runs retry it with a shorter step.
+func (s *Simulator) run(ctx context.Context, x0 State, cfg Config, sink Sink, result *Result) error {
 	steps := int(cfg.Duration / cfg.Dt)
-	result := &Result{
-		States:   make([]State, 0, steps+1),
-		Controls: make([]Control, 0, steps),
-		Times:    make([]float64, 0, steps+1),
-		Metrics:  make(map[string]float64),
-		Errors:   make([]error, 0),
+	dec := newDecimator(cfg)
+	grid := newOutputGrid(cfg)
+	clock := newControlClock(cfg)
+	controls, _ := sink.(ControlSink)
+	sys := &countingSystem{System: s.dyn}
+	defer func() { result.FuncEvals = sys.evals }()
+
+	if si, ok := s.integrator.(StochasticIntegrator); ok {
+		si.Seed(cfg.Seed)
 	}
 
 	for _, m := range s.metrics {
 		m.Reset()
 	}
+	if s.estimator != nil {
+		s.sensor.Reset(cfg.Seed)
+		s.estimator.Reset(x0, 0)
+	}
 
 	x := x0.Clone()
 	t := 0.0
 	dt := cfg.Dt
-
-	result.States = append(result.States, x.Clone())
-	result.Times = append(result.Times, t)
+	events := s.runEvents()
+	var cmd, u Control // held controller output and delivered input
 
 	initialEnergy := s.computeEnergy(x)
 
-	for i := 0; i < steps; i++ {
-		select {
-		case <-ctx.Done():
-			return result, ctx.Err()
-		default:
+	var runErr error
+	i := 0
+	for ; (!cfg.Adaptive && i < steps) || (cfg.Adaptive && t < cfg.Duration); i++ {
+		if runErr = ctx.Err(); runErr != nil {
+			break
 		}
 
-		u := s.controller.Compute(x, t)
+		var est State
+		var covTrace float64
+		if s.estimator != nil {
+			est, covTrace = s.estimate(x, u, t)
+		}
+		if clock.tick(t) {
+			if est != nil {
+				cmd = s.controller.Compute(est, t)
+			} else {
+				cmd = s.controller.Compute(x, t)
+			}
+			if clock != nil && controls != nil {
+				if err := controls.WriteControl(t, cmd); err != nil {
+					return err
+				}
+			}
+		}
+		u = s.actuate(cmd, t)
+
+		if grid == nil && dec.keep(i, t) {
+			if err := sink.Write(Sample{Step: i, Time: t, State: x, Control: u, Estimate: est, CovTrace: covTrace}); err != nil {
+				return err
+			}
+		}
 
 		for _, m := range s.metrics {
 			m.Observe(x, u, t)
@@ -70,18 +177,33 @@ func (s *Simulator) Run(ctx context.Context, x0 State, cfg Config) (*Result, err
 			obs.OnStep(x, u, t)
 		}
 
-		var newX State
-		var stepErr error
-
+		h := dt
 		if cfg.Adaptive {
-			newX, dt, stepErr = s.adaptiveStep(x, u, t, dt, cfg)
-		} else {
-			newX = s.integrator.Step(s.dyn, x, u, t, dt)
+			h = clock.clamp(cfg, t, clampStep(cfg, dt, t))
+		}
+		rewind := s.checkpoint()
+		newX, next, interp, stepErr := s.step(sys, x, u, t, h, cfg, grid != nil)
+		for errors.Is(stepErr, ErrStepRejected) {
+			result.RejectedSteps++
+			if next < cfg.MinDt || t+next == t {
+				stepErr = &SimulationError{Step: i, Time: t, State: x.Clone(), Wrapped: ErrStepTooSmall}
+				break
+			}
+			h = clock.clamp(cfg, t, clampStep(cfg, next, t))
+			rewind()
+			newX, next, interp, stepErr = s.step(sys, x, u, t, h, cfg, grid != nil)
+		}
+		if errors.Is(stepErr, ErrNoConvergence) {
+			stepErr = &SimulationError{Step: i, Time: t, State: x.Clone(), Wrapped: stepErr}
+		}
+		if errors.Is(stepErr, ErrStepTooSmall) || errors.Is(stepErr, ErrNoConvergence) {
+			runErr = stepErr
+			break
 		}
-
 		if stepErr != nil {
 			result.Errors = append(result.Errors, stepErr)
 		}
+		dt = next
 
 		if cfg.ValidateState && !newX.IsValid() {
 			err := SimError{Time: t, Step: i, Message: "invalid state (NaN/Inf)"}
@@ -89,13 +211,60 @@ func (s *Simulator) Run(ctx context.Context, x0 State, cfg Config) (*Result, err
 			break
 		}
 
+		segs := []segment{{t: t, h: h, stop: t + h, x0: x, x1: newX, interp: interp}}
+		var terminal *EventRecord
+		if len(events) > 0 {
+			var err error
+			segs, terminal, err = s.resolveEvents(sys, events, segs[0], u, rewind, result)
+			if err != nil {
+				runErr = err
+				break
+			}
+			newX = segs[len(segs)-1].x1
+		}
+
+		if grid != nil {
+			for _, seg := range segs {
+				if seg.interp == nil {
+					seg.interp = HermiteInterpolant(sys, seg.x0, seg.x1, u, seg.t, seg.h)
+				}
+				for _, tau := range grid.within(seg.t, seg.stop) {
+					theta := math.Max(0, math.Min(1, (tau-seg.t)/seg.h))
+					if err := sink.Write(Sample{Step: i, Time: tau, State: seg.interp(theta), Control: u, Estimate: est, CovTrace: covTrace}); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if terminal != nil {
+			result.recordStep(terminal.Time - t)
+			x, t = terminal.State, terminal.Time
+			i++
+			break
+		}
+		result.recordStep(h)
+
 		x = newX
-		t += dt
-		result.StepsTaken++
+		if cfg.Adaptive && h == cfg.Duration-t {
+			t = cfg.Duration
+		} else {
+			t += h
+		}
+	}
 
-		result.States = append(result.States, x.Clone())
-		result.Controls = append(result.Controls, u)
-		result.Times = append(result.Times, t)
+	final := Sample{Step: i, Time: t, State: x}
+	if grid != nil {
+		final.Time = grid.snap(t)
+	}
+	if s.estimator != nil {
+		final.Estimate, final.CovTrace = s.estimate(x, u, t)
+	}
+	if err := sink.Write(final); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return runErr
 	}
 
 	finalEnergy := s.computeEnergy(x)
@@ -107,7 +276,87 @@ func (s *Simulator) Run(ctx context.Context, x0 State, cfg Config) (*Result, err
 		result.Metrics[m.Name()] = m.Value()
 	}
 
-	return result, nil
+	return nil
+}
+
+// actuate passes a command through the actuator chain.
+func (s *Simulator) actuate(u Control, t float64) Control {
+	for _, a := range s.actuators {
+		u = a.Apply(u, t)
+	}
+	return u
+}
+
+// estimate measures x at t and returns the estimator's updated estimate
+// with its covariance trace. u is the control applied since the last call.
+func (s *Simulator) estimate(x State, u Control, t float64) (State, float64) {
+	y := s.sensor.Measure(x, t)
+	if u == nil {
+		u = make(Control, s.dyn.ControlDim())
+	}
+	est := s.estimator.Update(y, u, t).Clone()
+	return est, s.estimator.CovarianceTrace()
+}
+
+// step attempts a single step of size h from (t, x). In adaptive mode it
+// returns ErrStepRejected when the error estimate exceeds cfg.Tolerance;
+// either way the second value is the step size to try next. The interpolant
+// is only produced when dense output is requested and the integrator
+// supports it.
+func (s *Simulator) step(sys System, x State, u Control, t, h float64, cfg Config, dense bool) (State, float64, Interpolant, error) {
+	if di, ok := s.integrator.(DenseIntegrator); ok && dense {
+		newX, next, interp, err := di.StepDense(sys, x, u, t, h, denseTolerance(cfg))
+		if !cfg.Adaptive {
+			return newX, h, interp, nil
+		}
+		return newX, next, interp, err
+	}
+	if !cfg.Adaptive {
+		newX, err := s.integrate(sys, x, u, t, h)
+		return newX, h, nil, err
+	}
+	if ai, ok := s.integrator.(AdaptiveIntegrator); ok {
+		newX, next, err := ai.StepAdaptive(sys, x, u, t, h, cfg.Tolerance)
+		return newX, next, nil, err
+	}
+	newX, next, err := s.stepDoubling(sys, x, u, t, h, cfg.Tolerance)
+	return newX, next, nil, err
+}
+
+// integrate takes one plain step, reporting a failed implicit solve for
+// integrators that can detect one.
+func (s *Simulator) integrate(sys System, x State, u Control, t, h float64) (State, error) {
+	if fi, ok := s.integrator.(FallibleIntegrator); ok {
+		return fi.TryStep(sys, x, u, t, h)
+	}
+	return s.integrator.Step(sys, x, u, t, h), nil
+}
+
+// checkpoint saves the state of a StatefulIntegrator and returns a function
+// that restores it, as often as needed. For other integrators it does
+// nothing.
+func (s *Simulator) checkpoint() func() {
+	si, ok := s.integrator.(StatefulIntegrator)
+	if !ok {
+		return func() {}
+	}
+	saved := si.SaveState()
+	return func() { si.RestoreState(saved) }
+}
+
+// clampStep limits a proposed adaptive step to [MinDt, MaxDt] and to the
+// time remaining. Zero MinDt or MaxDt leave that side unbounded. A step
+// that would leave less than MinDt to go takes in the rest, so runs never
+// end on a sliver too short to retry.
+func clampStep(cfg Config, h, t float64) float64 {
+	if cfg.MaxDt > 0 {
+		h = math.Min(h, cfg.MaxDt)
+	}
+	h = math.Max(h, cfg.MinDt)
+	if left := cfg.Duration - t; left-h < cfg.MinDt {
+		return left
+	}
+	return h
 }
 
 func (s *Simulator) validateConfig(cfg Config) error {
@@ -117,10 +366,31 @@ func (s *Simulator) validateConfig(cfg Config) error {
 	if cfg.Duration <= 0 {
 		return fmt.Errorf("duration must be positive, got %f", cfg.Duration)
 	}
+	if cfg.SampleEvery < 0 || cfg.SampleInterval < 0 {
+		return fmt.Errorf("sample decimation must not be negative")
+	}
+	if cfg.OutputDt < 0 {
+		return fmt.Errorf("output dt must not be negative, got %f", cfg.OutputDt)
+	}
+	if cfg.OutputDt > 0 && (cfg.SampleEvery > 1 || cfg.SampleInterval > 0) {
+		return fmt.Errorf("output grid and sample decimation cannot be combined")
+	}
+	if _, ok := s.integrator.(StochasticIntegrator); ok && cfg.Adaptive {
+		return fmt.Errorf("adaptive stepping is not supported for stochastic integrators")
+	}
 	if cfg.Adaptive && cfg.Tolerance <= 0 {
 		return fmt.Errorf("tolerance must be positive for adaptive stepping")
 	}
-	return nil
+	if cfg.MinDt < 0 || cfg.MaxDt < 0 {
+		return fmt.Errorf("step bounds must not be negative")
+	}
+	if cfg.MaxDt > 0 && cfg.MinDt > cfg.MaxDt {
+		return fmt.Errorf("min dt %g exceeds max dt %g", cfg.MinDt, cfg.MaxDt)
+	}
+	if cfg.ControlPeriod < 0 || cfg.ControlJitter < 0 {
+		return fmt.Errorf("control period and jitter must not be negative")
+	}
+	return CheckIntegrator(s.dyn, s.integrator)
 }
 
 func (s *Simulator) computeEnergy(x State) float64 {
@@ -130,26 +400,73 @@ func (s *Simulator) computeEnergy(x State) float64 {
 	return 0
 }
 
-func (s *Simulator) adaptiveStep(x State, u Control, t, dt float64, cfg Config) (State, float64, error) {
-	if adaptive, ok := s.integrator.(AdaptiveIntegrator); ok {
-		return adaptive.StepAdaptive(s.dyn, x, u, t, dt, cfg.Tolerance)
+// denseTolerance is the error tolerance handed to a DenseIntegrator. Fixed
+// step runs ignore the step size it proposes, so any positive value will do.
+func denseTolerance(cfg Config) float64 {
+	if cfg.Tolerance > 0 {
+		return cfg.Tolerance
 	}
+	return 1e-6
+}
 
-	x1 := s.integrator.Step(s.dyn, x, u, t, dt)
-	xHalf := s.integrator.Step(s.dyn, x, u, t, dt/2)
-	x2 := s.integrator.Step(s.dyn, xHalf, u, t+dt/2, dt/2)
+// stepDoubling gives adaptive stepping to integrators without an error
+// estimate of their own by comparing one step of h against two of h/2. A
+// failed implicit solve rejects the step in favour of one half as long.
+func (s *Simulator) stepDoubling(sys System, x State, u Control, t, h, tol float64) (State, float64, error) {
+	rewind := s.checkpoint()
+	x1, err1 := s.integrate(sys, x, u, t, h)
+	rewind()
+	xHalf, err2 := s.integrate(sys, x, u, t, h/2)
+	x2, err3 := s.integrate(sys, xHalf, u, t+h/2, h/2)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return x2, h / 2, ErrStepRejected
+	}
 
 	err := x1.Sub(x2).Norm()
 
-	if err > cfg.Tolerance && dt > cfg.MinDt {
-		return s.adaptiveStep(x, u, t, dt/2, cfg)
+	switch {
+	case err > tol:
+		return x2, h / 2, ErrStepRejected
+	case err < tol/10:
+		return x2, h * 2, nil
+	default:
+		return x2, h, nil
 	}
+}
 
-	if err < cfg.Tolerance/10 && dt < cfg.MaxDt {
-		dt = math.Min(dt*2, cfg.MaxDt)
-	}
+// countingSystem counts Derive calls for Result.FuncEvals.
+type countingSystem struct {
+	System
+	evals int
+}
+
+func (c *countingSystem) Derive(x State, u Control, t float64) State {
+	c.evals++
+	return c.System.Derive(x, u, t)
+}
+
+func (c *countingSystem) countEval() { c.evals++ }
 
-	return x2, dt, nil
+// evalCounter is implemented by wrappers that count derivative evaluations,
+// so wrappers above them that evaluate the model some other way can still
+// report the evaluation.
+type evalCounter interface {
+	countEval()
+}
+
+// Unwrap returns the wrapped system, for integrators that look for optional
+// interfaces on the model.
+func (c *countingSystem) Unwrap() System { return c.System }
+
+// Unwrap strips any wrappers the simulator put around a model.
+func Unwrap(dyn System) System {
+	for {
+		w, ok := dyn.(interface{ Unwrap() System })
+		if !ok {
+			return dyn
+		}
+		dyn = w.Unwrap()
+	}
 }
 
 func (s *Simulator) RunWithCallback(ctx context.Context, x0 State, cfg Config, callback func(State, Control, float64) bool) error {
@@ -168,13 +485,16 @@ func (s *Simulator) RunWithCallback(ctx context.Context, x0 State, cfg Config, c
 		default:
 		}
 
-		u := s.controller.Compute(x, t)
+		u := s.actuate(s.controller.Compute(x, t), t)
 
 		if !callback(x, u, t) {
 			return nil
 		}
 
-		x = s.integrator.Step(s.dyn, x, u, t, dt)
+		var err error
+		if x, err = s.integrate(s.dyn, x, u, t, dt); err != nil {
+			return fmt.Errorf("t=%.4f: %w", t, err)
+		}
 		t += dt
 
 		if cfg.ValidateState && !x.IsValid() {
@@ -0,0 +1,81 @@
+package dynamo
+
+// Sample is one recorded point of a trajectory. Control is the input applied
+// from this state onward; it is nil for the final sample of a run.
+type Sample struct {
+	Step    int
+	Time    float64
+	State   State
+	Control Control
+
+	// Estimate and CovTrace are set when the run has an estimator.
+	Estimate State
+	CovTrace float64
+}
+
+// Sink consumes a trajectory sample by sample, see Simulator.Stream.
+// Samples are only valid for the duration of Write; sinks that keep them
+// must copy.
+type Sink interface {
+	Begin(stateVars, controlVars []Variable) error
+	Write(sample Sample) error
+	Close() error
+}
+
+// decimator picks which steps of a run are recorded, following
+// Config.SampleEvery and Config.SampleInterval.
+type decimator struct {
+	every    int
+	interval float64
+	last     float64
+	started  bool
+}
+
+func newDecimator(cfg Config) *decimator {
+	return &decimator{every: cfg.SampleEvery, interval: cfg.SampleInterval}
+}
+
+func (d *decimator) keep(step int, t float64) bool {
+	if !d.started {
+		d.started, d.last = true, t
+		return true
+	}
+	if d.every > 1 && step%d.every != 0 {
+		return false
+	}
+	// tolerate round-off so an interval that is a multiple of dt still
+	// lands on every Nth step
+	if d.interval > 0 && t-d.last < d.interval*(1-1e-9) {
+		return false
+	}
+	d.last = t
+	return true
+}
+
+// collector is the in-memory sink behind Run.
+type collector struct {
+	result *Result
+}
+
+func (c *collector) Begin(stateVars, controlVars []Variable) error { return nil }
+
+func (c *collector) Write(sample Sample) error {
+	c.result.States = append(c.result.States, sample.State.Clone())
+	c.result.Times = append(c.result.Times, sample.Time)
+	if sample.Control != nil {
+		c.result.Controls = append(c.result.Controls, append(Control(nil), sample.Control...))
+	}
+	if sample.Estimate != nil {
+		c.result.Estimates = append(c.result.Estimates, sample.Estimate.Clone())
+		c.result.CovTraces = append(c.result.CovTraces, sample.CovTrace)
+	}
+	return nil
+}
+
+func (c *collector) WriteControl(t float64, u Control) error {
+	c.result.ControlTimes = append(c.result.ControlTimes, t)
+	c.result.ControlSamples = append(c.result.ControlSamples, u.Clone())
+	return nil
+}
+
+func (c *collector) Close() error { return nil }
@@ -0,0 +1,60 @@
+package dynamo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Separable is implemented by second-order models, dq/dt = v and
+// dv/dt = a(q, t), to tell symplectic integrators how the state is laid
+// out: x[vel[i]] is the time derivative of x[pos[i]]. The schemes stay
+// symplectic only while a does not depend on v. A model whose layout is
+// only known at run time may return no positions to declare no split.
+type Separable interface {
+	Split() (pos, vel []int)
+}
+
+// SymplecticIntegrator is implemented by integrators that step positions
+// and velocities separately and so need the model to be Separable.
+type SymplecticIntegrator interface {
+	Integrator
+	Symplectic()
+}
+
+// ErrNotSeparable is returned for models that declare no position/velocity
+// split.
+var ErrNotSeparable = errors.New("model does not declare a position/velocity split")
+
+// SplitOf returns the model's declared position/velocity split.
+func SplitOf(dyn System) (pos, vel []int, err error) {
+	s, ok := Unwrap(dyn).(Separable)
+	if !ok {
+		return nil, nil, ErrNotSeparable
+	}
+	pos, vel = s.Split()
+	if len(pos) == 0 {
+		return nil, nil, ErrNotSeparable
+	}
+	return pos, vel, nil
+}
+
+// BlockSplit is the split of n coordinates laid out as [q..., v...].
+func BlockSplit(n int) (pos, vel []int) {
+	pos, vel = make([]int, n), make([]int, n)
+	for i := range pos {
+		pos[i], vel[i] = i, n+i
+	}
+	return pos, vel
+}
+
+// CheckIntegrator reports whether integ can step dyn: symplectic
+// integrators need a Separable model.
+func CheckIntegrator(dyn System, integ Integrator) error {
+	if _, ok := integ.(SymplecticIntegrator); !ok {
+		return nil
+	}
+	if _, _, err := SplitOf(dyn); err != nil {
+		return fmt.Errorf("symplectic integrator: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package dynamo
+
+// Stochastic is implemented by systems with a diffusion term, i.e. the Itô
+// SDE dx = f(x, u, t) dt + g(x, u, t) dW with independent Wiener processes
+// per component (diagonal noise). Diffusion returns g. Deterministic
+// integrators ignore it.
+type Stochastic interface {
+	Diffusion(x State, u Control, t float64) State
+}
+
+// StochasticIntegrator draws Wiener increments from its own generator. The
+// simulator reseeds it from Config.Seed at the start of every run, so a
+// stochastic run is reproducible bit for bit.
+type StochasticIntegrator interface {
+	Integrator
+	Seed(seed int64)
+}
+
+// DiffusionOf returns g(x, u, t) for stochastic systems and nil otherwise.
+func DiffusionOf(dyn System, x State, u Control, t float64) State {
+	if s, ok := Unwrap(dyn).(Stochastic); ok {
+		return s.Diffusion(x, u, t)
+	}
+	return nil
+}
@@ -64,6 +64,12 @@ func (s State) Sub(other State) State {
 
 type Control []float64
 
+func (c Control) Clone() Control {
+	out := make(Control, len(c))
+	copy(out, c)
+	return out
+}
+
 type System interface {
 	Derive(x State, u Control, t float64) State
 	StateDim() int
@@ -83,6 +89,25 @@ type AdaptiveIntegrator interface {
 	StepAdaptive(dyn System, x State, u Control, t, dt, tol float64) (State, float64, error)
 }
 
+// FallibleIntegrator is implemented by integrators whose steps can fail
+// outright, such as implicit methods whose Newton iteration does not
+// converge. The simulator steps them with TryStep; Step returns the same
+// state and drops the error.
+type FallibleIntegrator interface {
+	Integrator
+	TryStep(dyn System, x State, u Control, t, dt float64) (State, error)
+}
+
+// StatefulIntegrator is implemented by integrators that carry state from
+// one step to the next, such as multistep methods. The simulator saves it
+// before trial steps, those of step doubling and event location, and
+// restores it afterwards, so only the steps the run keeps advance it.
+type StatefulIntegrator interface {
+	Integrator
+	SaveState() any
+	RestoreState(saved any)
+}
+
 type Controller interface {
 	Compute(x State, t float64) Control
 }
@@ -112,6 +137,27 @@ type Config struct {
 	MinDt         float64
 	Adaptive      bool
 	ValidateState bool
+
+	// Output decimation: record every SampleEvery-th step and at most once
+	// per SampleInterval of simulated time. Zero values record every step;
+	// the first and last states are always recorded.
+	SampleEvery    int
+	SampleInterval float64
+
+	// OutputDt, when positive, records states on the uniform grid 0,
+	// OutputDt, 2*OutputDt, ... independent of the internal step size,
+	// using the integrator's dense output if it has one. The final state is
+	// still recorded when it falls between grid points.
+	OutputDt float64
+
+	// ControlPeriod, when positive, updates the controller every
+	// ControlPeriod seconds rather than every step and holds its command
+	// in between. Updates land on the first step at or after they are due,
+	// so Dt should divide the period; adaptive steps are shortened to end
+	// on them. ControlJitter delays each update by a uniform random time of
+	// up to ControlJitter seconds, drawn from Seed.
+	ControlPeriod float64
+	ControlJitter float64
 }
 
 func DefaultConfig() Config {
@@ -134,6 +180,37 @@ type Result struct {
 	EnergyDrift float64
 	StepsTaken  int
 	Errors      []error
+
+	// Step statistics. StepsTaken counts accepted steps; in fixed-step
+	// runs no step is rejected and the min and max step are both Dt.
+	RejectedSteps int
+	MinStep       float64
+	MaxStep       float64
+	FuncEvals     int
+
+	StateVars   []Variable
+	ControlVars []Variable
+	Events      []EventRecord
+
+	// Runs with an estimator record its estimate and covariance trace for
+	// every recorded state.
+	Estimates []State
+	CovTraces []float64
+
+	// Runs with a ControlPeriod record every controller update at the
+	// controller rate; Controls holds the command in force at each state.
+	ControlTimes   []float64
+	ControlSamples []Control
+}
+
+func (r *Result) recordStep(h float64) {
+	if r.StepsTaken == 0 || h < r.MinStep {
+		r.MinStep = h
+	}
+	if h > r.MaxStep {
+		r.MaxStep = h
+	}
+	r.StepsTaken++
 }
 
 type SimError struct {
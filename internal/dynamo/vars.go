@@ -0,0 +1,74 @@
+package dynamo
+
+import "fmt"
+
+// Variable names one component of a state or control vector.
+type Variable struct {
+	Name string
+	Unit string
+}
+
+// Descriptor is implemented by systems that name their state and control
+// components. It is optional; undescribed systems fall back to x0..xN and
+// u0..uM.
+type Descriptor interface {
+	StateVars() []Variable
+	ControlVars() []Variable
+}
+
+// DescribeState returns the state variables of dyn, falling back to
+// generic x0..xN names when dyn does not implement Descriptor.
+func DescribeState(dyn System) []Variable {
+	if d, ok := dyn.(Descriptor); ok {
+		if vars := d.StateVars(); len(vars) == dyn.StateDim() {
+			return vars
+		}
+	}
+	return GenericVars("x", dyn.StateDim())
+}
+
+// DescribeControl returns the control variables of dyn, falling back to
+// generic u0..uM names when dyn does not implement Descriptor.
+func DescribeControl(dyn System) []Variable {
+	if d, ok := dyn.(Descriptor); ok {
+		if vars := d.ControlVars(); len(vars) == dyn.ControlDim() {
+			return vars
+		}
+	}
+	return GenericVars("u", dyn.ControlDim())
+}
+
+// GenericVars returns n unitless variables named prefix0..prefix(n-1).
+func GenericVars(prefix string, n int) []Variable {
+	vars := make([]Variable, n)
+	for i := range vars {
+		vars[i] = Variable{Name: fmt.Sprintf("%s%d", prefix, i)}
+	}
+	return vars
+}
+
+// VarNames extracts the names from vars.
+func VarNames(vars []Variable) []string {
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.Name
+	}
+	return names
+}
+
+// VarUnits extracts the units from vars.
+func VarUnits(vars []Variable) []string {
+	units := make([]string, len(vars))
+	for i, v := range vars {
+		units[i] = v.Unit
+	}
+	return units
+}
+
+// Label formats a variable as "name [unit]", or just the name when unitless.
+func (v Variable) Label() string {
+	if v.Unit == "" {
+		return v.Name
+	}
+	return fmt.Sprintf("%s [%s]", v.Name, v.Unit)
+}
@@ -0,0 +1,18 @@
+// Package estimation reconstructs a model's state from noisy partial
+// measurements, so controllers can act on what a real system would know.
+//
+// A [Measurement] is the sensor: it samples chosen state components every
+// Period seconds, adding a constant bias and white Gaussian noise. The
+// estimators implement dynamo.Estimator and predict with the model itself
+// between measurements:
+//
+//   - [NewKalman]: the Kalman filter of the model linearized at an
+//     operating point
+//   - [NewEKF]: the extended Kalman filter, relinearizing at the estimate
+//   - [NewUKF]: the unscented Kalman filter, propagating sigma points
+//     through the nonlinear model
+//
+// Process noise is white with spectral density diag(Q); measurement noise
+// has the sensor's variance. The sensor bias is not modelled by the
+// filters, so a biased sensor shows up as estimation error.
+package estimation
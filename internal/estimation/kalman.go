@@ -0,0 +1,169 @@
+package estimation
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/san-kum/dynsim/internal/control"
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/integrators"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// maxPredictStep bounds the substeps the filters take between measurements.
+const maxPredictStep = 0.01
+
+// filter holds the Gaussian belief and settings the estimators share.
+type filter struct {
+	Model  dynamo.System
+	Sensor *Measurement
+	// Q is the process noise spectral density, one value per state.
+	Q []float64
+	// X0 is the initial estimate; nil starts from the true initial state.
+	// P0 is the diagonal of the initial covariance.
+	X0 dynamo.State
+	P0 []float64
+
+	x    dynamo.State
+	p    linalg.Matrix
+	t    float64
+	step dynamo.Integrator
+}
+
+func newFilter(model dynamo.System, sensor *Measurement, q []float64) (filter, error) {
+	n := model.StateDim()
+	for _, i := range sensor.Observed {
+		if i >= n {
+			return filter{}, fmt.Errorf("sensor observes state %d of a %d-state model", i, n)
+		}
+	}
+	qd, err := broadcast(q, n)
+	if err != nil {
+		return filter{}, fmt.Errorf("process noise: %w", err)
+	}
+	p0 := make([]float64, n)
+	for i := range p0 {
+		p0[i] = 1
+	}
+	return filter{Model: model, Sensor: sensor, Q: qd, P0: p0, step: integrators.NewRK4()}, nil
+}
+
+func (f *filter) Reset(x0 dynamo.State, t float64) {
+	f.x = x0.Clone()
+	if f.X0 != nil {
+		f.x = f.X0.Clone()
+	}
+	n := len(f.x)
+	f.p = linalg.New(n, n)
+	for i := 0; i < n && i < len(f.P0); i++ {
+		f.p[i][i] = f.P0[i]
+	}
+	f.t = t
+}
+
+func (f *filter) Estimate() dynamo.State { return f.x.Clone() }
+
+func (f *filter) CovarianceTrace() float64 {
+	tr := 0.0
+	for i := range f.p {
+		tr += f.p[i][i]
+	}
+	return tr
+}
+
+// substeps splits the time since the last update into steps of at most
+// maxPredictStep.
+func (f *filter) substeps(t float64) (int, float64) {
+	h := t - f.t
+	if h <= 0 {
+		return 0, 0
+	}
+	n := int(math.Ceil(h / maxPredictStep))
+	return n, h / float64(n)
+}
+
+// addProcessNoise adds the noise accumulated over h to the covariance.
+func (f *filter) addProcessNoise(h float64) {
+	for i, q := range f.Q {
+		f.p[i][i] += q * h
+	}
+}
+
+// correct is the Kalman measurement update with the Joseph form of the
+// covariance update, which keeps P symmetric positive definite.
+func (f *filter) correct(y []float64) {
+	n := len(f.x)
+	h, r := f.Sensor.H(n), f.Sensor.R()
+	pht := f.p.Mul(h.T())
+	sinv, err := linalg.Inverse(h.Mul(pht).Add(r))
+	if err != nil {
+		return
+	}
+	k := pht.Mul(sinv)
+	innov := make([]float64, len(y))
+	for j, i := range f.Sensor.Observed {
+		innov[j] = y[j] - f.x[i]
+	}
+	dx := k.MulVec(innov)
+	for i := range f.x {
+		f.x[i] += dx[i]
+	}
+	ikh := linalg.Identity(n).Sub(k.Mul(h))
+	f.p = symmetrize(ikh.Mul(f.p).Mul(ikh.T()).Add(k.Mul(r).Mul(k.T())))
+}
+
+// EKF is the extended Kalman filter: it propagates the estimate through the
+// model and the covariance through the model's Jacobian at the estimate.
+type EKF struct {
+	filter
+}
+
+// NewEKF returns an extended Kalman filter predicting with model. q is
+// broadcast from a single value.
+func NewEKF(model dynamo.System, sensor *Measurement, q []float64) (*EKF, error) {
+	f, err := newFilter(model, sensor, q)
+	if err != nil {
+		return nil, err
+	}
+	return &EKF{filter: f}, nil
+}
+
+// NewKalman returns the linear Kalman filter for model linearized at
+// (x0, u0); a nil u0 is the control that best holds the model at x0. It is
+// the EKF of the linearization, so its Jacobian is fixed.
+func NewKalman(model dynamo.System, x0 dynamo.State, u0 dynamo.Control, sensor *Measurement, q []float64) (*EKF, error) {
+	if len(x0) != model.StateDim() {
+		return nil, fmt.Errorf("operating point has %d values, model state has %d", len(x0), model.StateDim())
+	}
+	return NewEKF(control.NewLinearModel(model, x0, u0), sensor, q)
+}
+
+// Update implements dynamo.Estimator.
+func (f *EKF) Update(y []float64, u dynamo.Control, t float64) dynamo.State {
+	steps, h := f.substeps(t)
+	for k := 0; k < steps; k++ {
+		tk := f.t + float64(k)*h
+		a := linalg.Matrix(dynamo.StateJacobian(f.Model, f.x, u, tk))
+		phi := linalg.Expm(a.Scale(h))
+		f.x = f.step.Step(f.Model, f.x, u, tk, h)
+		f.p = phi.Mul(f.p).Mul(phi.T())
+		f.addProcessNoise(h)
+	}
+	if steps > 0 {
+		f.t = t
+	}
+	if y != nil {
+		f.correct(y)
+	}
+	return f.Estimate()
+}
+
+func symmetrize(p linalg.Matrix) linalg.Matrix {
+	for i := range p {
+		for j := 0; j < i; j++ {
+			s := 0.5 * (p[i][j] + p[j][i])
+			p[i][j], p[j][i] = s, s
+		}
+	}
+	return p
+}
@@ -0,0 +1,125 @@
+package estimation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// Measurement observes the state components listed in Observed, each with
+// a constant Bias and Gaussian noise of standard deviation Noise, every
+// Period seconds (every call when Period is zero). It implements
+// dynamo.Sensor.
+type Measurement struct {
+	Observed []int
+	Noise    []float64
+	Bias     []float64
+	Period   float64
+
+	rng  *rand.Rand
+	next float64
+}
+
+// NewMeasurement returns a sensor for the listed state components of an
+// n-dimensional state; nil observes every component. noise and bias are
+// broadcast from a single value and may be empty.
+func NewMeasurement(n int, observed []int, noise, bias []float64, period float64) (*Measurement, error) {
+	if observed == nil {
+		observed = make([]int, n)
+		for i := range observed {
+			observed[i] = i
+		}
+	}
+	for _, i := range observed {
+		if i < 0 || i >= n {
+			return nil, fmt.Errorf("observed state %d out of range [0, %d)", i, n)
+		}
+	}
+	if period < 0 {
+		return nil, fmt.Errorf("sensor period must not be negative, got %g", period)
+	}
+	m := &Measurement{Observed: observed, Period: period}
+	var err error
+	if m.Noise, err = broadcast(noise, len(observed)); err != nil {
+		return nil, fmt.Errorf("sensor noise: %w", err)
+	}
+	if m.Bias, err = broadcast(bias, len(observed)); err != nil {
+		return nil, fmt.Errorf("sensor bias: %w", err)
+	}
+	for _, s := range m.Noise {
+		if s < 0 {
+			return nil, fmt.Errorf("sensor noise must not be negative, got %g", s)
+		}
+	}
+	m.Reset(0)
+	return m, nil
+}
+
+// Reset implements dynamo.Sensor.
+func (m *Measurement) Reset(seed int64) {
+	m.rng = rand.New(rand.NewSource(seed))
+	m.next = math.Inf(-1)
+}
+
+// Measure implements dynamo.Sensor.
+func (m *Measurement) Measure(x dynamo.State, t float64) []float64 {
+	if m.Period > 0 {
+		// tolerate round-off so a period that is a multiple of dt still
+		// lands on every Nth step
+		eps := 1e-9 * m.Period
+		if t < m.next-eps {
+			return nil
+		}
+		if math.IsInf(m.next, -1) {
+			m.next = t
+		}
+		for m.next <= t+eps {
+			m.next += m.Period
+		}
+	}
+	y := make([]float64, len(m.Observed))
+	for k, i := range m.Observed {
+		y[k] = x[i] + m.Bias[k] + m.Noise[k]*m.rng.NormFloat64()
+	}
+	return y
+}
+
+// H returns the measurement matrix for an n-dimensional state.
+func (m *Measurement) H(n int) linalg.Matrix {
+	h := linalg.New(len(m.Observed), n)
+	for k, i := range m.Observed {
+		h[k][i] = 1
+	}
+	return h
+}
+
+// R returns the measurement noise covariance. Noise-free components get a
+// tiny variance to keep the filter update well posed.
+func (m *Measurement) R() linalg.Matrix {
+	r := linalg.New(len(m.Observed), len(m.Observed))
+	for k, s := range m.Noise {
+		r[k][k] = math.Max(s*s, 1e-12)
+	}
+	return r
+}
+
+// broadcast expands v to n values: empty gives zeros and a single value is
+// repeated.
+func broadcast(v []float64, n int) ([]float64, error) {
+	out := make([]float64, n)
+	switch len(v) {
+	case 0:
+	case 1:
+		for i := range out {
+			out[i] = v[0]
+		}
+	case n:
+		copy(out, v)
+	default:
+		return nil, fmt.Errorf("got %d values, want 1 or %d", len(v), n)
+	}
+	return out, nil
+}
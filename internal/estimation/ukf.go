@@ -0,0 +1,90 @@
+package estimation
+
+import (
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// UKF is the unscented Kalman filter: it propagates 2n+1 sigma points,
+// spread by the square root of the covariance, through the nonlinear model
+// and refits the mean and covariance to them. Alpha, Beta and Kappa are the
+// usual spread and weighting parameters. Measurements are linear in the
+// state, so the correction is the Kalman one.
+type UKF struct {
+	filter
+	Alpha, Beta, Kappa float64
+}
+
+// NewUKF returns an unscented Kalman filter predicting with model. q is
+// broadcast from a single value.
+func NewUKF(model dynamo.System, sensor *Measurement, q []float64) (*UKF, error) {
+	f, err := newFilter(model, sensor, q)
+	if err != nil {
+		return nil, err
+	}
+	return &UKF{filter: f, Alpha: 1, Beta: 2, Kappa: 0}, nil
+}
+
+// Update implements dynamo.Estimator.
+func (f *UKF) Update(y []float64, u dynamo.Control, t float64) dynamo.State {
+	if steps, h := f.substeps(t); steps > 0 {
+		f.predict(u, steps, h)
+		f.t = t
+	}
+	if y != nil {
+		f.correct(y)
+	}
+	return f.Estimate()
+}
+
+func (f *UKF) predict(u dynamo.Control, steps int, h float64) {
+	n := len(f.x)
+	lambda := f.Alpha*f.Alpha*(float64(n)+f.Kappa) - float64(n)
+	l, err := linalg.Cholesky(f.p.Scale(float64(n) + lambda))
+	if err != nil {
+		// Round-off has cost P its definiteness; fall back to the mean.
+		l = linalg.New(n, n)
+	}
+
+	points := make([]dynamo.State, 0, 2*n+1)
+	points = append(points, f.x.Clone())
+	for sign := 1.0; sign >= -1; sign -= 2 {
+		for j := 0; j < n; j++ {
+			p := f.x.Clone()
+			for i := 0; i < n; i++ {
+				p[i] += sign * l[i][j]
+			}
+			points = append(points, p)
+		}
+	}
+	for i, p := range points {
+		for k := 0; k < steps; k++ {
+			p = f.step.Step(f.Model, p, u, f.t+float64(k)*h, h)
+		}
+		points[i] = p
+	}
+
+	wm0 := lambda / (float64(n) + lambda)
+	wc0 := wm0 + 1 - f.Alpha*f.Alpha + f.Beta
+	wi := 1 / (2 * (float64(n) + lambda))
+
+	mean := points[0].Scale(wm0)
+	for _, p := range points[1:] {
+		mean = mean.Add(p.Scale(wi))
+	}
+	cov := linalg.New(n, n)
+	for k, p := range points {
+		w := wi
+		if k == 0 {
+			w = wc0
+		}
+		d := p.Sub(mean)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				cov[i][j] += w * d[i] * d[j]
+			}
+		}
+	}
+	f.x, f.p = mean, cov
+	f.addProcessNoise(h * float64(steps))
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
 
 	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/metrics"
 )
 
 type Config struct {
@@ -17,11 +19,32 @@ type Config struct {
 	Duration   float64
 	Seed       int64
 	Params     map[string]float64
+
+	// SampleEvery and SampleInterval decimate the recorded trajectory,
+	// see dynamo.Config.
+	SampleEvery    int
+	SampleInterval float64
+
+	// OutputDt records states on a uniform time grid, see dynamo.Config.
+	OutputDt float64
+
+	// Adaptive stepping, see dynamo.Config.
+	Adaptive  bool
+	Tolerance float64
+	MinDt     float64
+	MaxDt     float64
+
+	// ControlPeriod and ControlJitter sample the controller, see
+	// dynamo.Config.
+	ControlPeriod float64
+	ControlJitter float64
 }
 
 type Experiment struct {
 	cfg        Config
 	simulator  *dynamo.Simulator
+	dyn        dynamo.System
+	controller dynamo.Controller
 	randSource *rand.Rand
 }
 
@@ -34,27 +57,98 @@ func New(cfg Config) *Experiment {
 
 func (e *Experiment) Setup(dyn dynamo.System, integrator dynamo.Integrator, controller dynamo.Controller, metrics []dynamo.Metric) error {
 	e.simulator = dynamo.New(dyn, integrator, controller)
+	e.dyn, e.controller = dyn, controller
 	for _, m := range metrics {
 		e.simulator.AddMetric(m)
 	}
 	return nil
 }
 
+// SetEstimator has the controller act on est's estimate, fed by sensor,
+// and adds the estimation error metric. Call it after Setup.
+func (e *Experiment) SetEstimator(sensor dynamo.Sensor, est dynamo.Estimator) {
+	e.simulator.SetEstimator(sensor, est)
+	e.simulator.AddMetric(metrics.NewEstimationError(est))
+}
+
+// SetActuators puts chain between the controller and the model, see
+// dynamo.Simulator.SetActuators. Call it after Setup.
+func (e *Experiment) SetActuators(chain []dynamo.Actuator) {
+	e.simulator.SetActuators(chain...)
+}
+
+// Track has the controller follow refs, keyed by state index, and adds the
+// tracking metrics for each. With several references the metric names
+// carry the state name, as in "iae_y". Call it after Setup.
+func (e *Experiment) Track(refs map[int]dynamo.Reference) error {
+	if e.simulator == nil {
+		return fmt.Errorf("experiment not setup")
+	}
+	tracker, ok := unwrap(e.controller).(dynamo.Tracker)
+	if !ok {
+		return fmt.Errorf("controller %s cannot track references", e.cfg.Controller)
+	}
+	indices := make([]int, 0, len(refs))
+	for i := range refs {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	vars := dynamo.DescribeState(e.dyn)
+	for _, i := range indices {
+		if i < 0 || i >= len(vars) {
+			return fmt.Errorf("reference on state %d, model has %d", i, len(vars))
+		}
+		if err := tracker.Track(i, refs[i]); err != nil {
+			return err
+		}
+		suffix := ""
+		if len(refs) > 1 {
+			suffix = vars[i].Name
+		}
+		for _, m := range metrics.Tracking(i, refs[i], suffix) {
+			e.simulator.AddMetric(m)
+		}
+	}
+	return nil
+}
+
 func (e *Experiment) Run(ctx context.Context) (*dynamo.Result, error) {
 	if e.simulator == nil {
 		return nil, fmt.Errorf("experiment not setup")
 	}
+	return e.simulator.Run(ctx, e.initialState(), e.simConfig())
+}
+
+// Stream runs the experiment, writing samples to sink instead of
+// collecting them in the Result.
+func (e *Experiment) Stream(ctx context.Context, sink dynamo.Sink) (*dynamo.Result, error) {
+	if e.simulator == nil {
+		return nil, fmt.Errorf("experiment not setup")
+	}
+	return e.simulator.Stream(ctx, e.initialState(), e.simConfig(), sink)
+}
 
+func (e *Experiment) initialState() dynamo.State {
 	x0 := make(dynamo.State, len(e.cfg.InitState))
 	copy(x0, e.cfg.InitState)
+	return x0
+}
 
-	simCfg := dynamo.Config{
-		Dt:       e.cfg.Dt,
-		Duration: e.cfg.Duration,
-		Seed:     e.cfg.Seed,
+func (e *Experiment) simConfig() dynamo.Config {
+	return dynamo.Config{
+		Dt:             e.cfg.Dt,
+		Duration:       e.cfg.Duration,
+		Seed:           e.cfg.Seed,
+		SampleEvery:    e.cfg.SampleEvery,
+		SampleInterval: e.cfg.SampleInterval,
+		OutputDt:       e.cfg.OutputDt,
+		Adaptive:       e.cfg.Adaptive,
+		Tolerance:      e.cfg.Tolerance,
+		MinDt:          e.cfg.MinDt,
+		MaxDt:          e.cfg.MaxDt,
+		ControlPeriod:  e.cfg.ControlPeriod,
+		ControlJitter:  e.cfg.ControlJitter,
 	}
-
-	return e.simulator.Run(ctx, x0, simCfg)
 }
 
 // GetSimulator returns the underlying simulator for adding observers
@@ -2,12 +2,15 @@ package experiment
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/san-kum/dynsim/internal/control"
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/estimation"
 	"github.com/san-kum/dynsim/internal/integrators"
 	"github.com/san-kum/dynsim/internal/metrics"
+	"github.com/san-kum/dynsim/internal/modelfile"
 	"github.com/san-kum/dynsim/internal/physics"
-	"github.com/san-kum/dynsim/internal/dynamo"
 )
 
 type ModelFactory func() dynamo.System
@@ -16,6 +19,7 @@ type ControllerFactory func(map[string]float64) dynamo.Controller
 
 type Registry struct {
 	models      map[string]ModelFactory
+	specs       map[string]physics.Spec
 	integrators map[string]IntegratorFactory
 	controllers map[string]ControllerFactory
 }
@@ -23,6 +27,7 @@ type Registry struct {
 func NewRegistry() *Registry {
 	r := &Registry{
 		models:      make(map[string]ModelFactory),
+		specs:       make(map[string]physics.Spec),
 		integrators: make(map[string]IntegratorFactory),
 		controllers: make(map[string]ControllerFactory),
 	}
@@ -33,13 +38,36 @@ func NewRegistry() *Registry {
 }
 
 func (r *Registry) registerModels() {
-	r.models["pendulum"] = func() dynamo.System { return physics.NewPendulum() }
-	r.models["cartpole"] = func() dynamo.System { return physics.NewCartPole() }
-	r.models["nbody"] = func() dynamo.System { return physics.NewNBody(3) }
-	r.models["double_pendulum"] = func() dynamo.System { return physics.NewDoublePendulum() }
-	r.models["spring_mass"] = func() dynamo.System { return physics.NewSpringMass() }
-	r.models["spring_chain"] = func() dynamo.System { return physics.NewSpringMassChain(3) }
-	r.models["drone"] = func() dynamo.System { return physics.NewDrone() }
+	for _, spec := range physics.Catalog() {
+		r.RegisterModel(spec)
+	}
+}
+
+// RegisterModel adds a model to the registry, replacing any model of the
+// same name.
+func (r *Registry) RegisterModel(spec physics.Spec) {
+	r.models[spec.Name] = spec.New
+	r.specs[spec.Name] = spec
+}
+
+// LoadModelFile compiles a YAML model definition, registers it under the
+// name it declares and returns that name.
+func (r *Registry) LoadModelFile(path string) (string, error) {
+	m, err := modelfile.Load(path)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := physics.Lookup(m.Name()); ok {
+		return "", fmt.Errorf("%s: model name %s is taken by a built-in model", path, m.Name())
+	}
+	r.RegisterModel(physics.Spec{
+		Name:        m.Name(),
+		Description: m.Description(),
+		ControlDim:  m.ControlDim(),
+		New:         func() dynamo.System { return m.Clone() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*modelfile.Model).DefaultState() },
+	})
+	return m.Name(), nil
 }
 
 func (r *Registry) registerIntegrators() {
@@ -48,6 +76,19 @@ func (r *Registry) registerIntegrators() {
 	r.integrators["rk45"] = func() dynamo.Integrator { return integrators.NewRK45() }
 	r.integrators["verlet"] = func() dynamo.Integrator { return integrators.NewVerlet() }
 	r.integrators["leapfrog"] = func() dynamo.Integrator { return integrators.NewLeapfrog() }
+	r.integrators["forest_ruth"] = func() dynamo.Integrator { return integrators.NewForestRuth() }
+	r.integrators["pefrl"] = func() dynamo.Integrator { return integrators.NewPEFRL() }
+	r.integrators["yoshida6"] = func() dynamo.Integrator { return integrators.NewYoshida6() }
+	r.integrators["yoshida8"] = func() dynamo.Integrator { return integrators.NewYoshida8() }
+	r.integrators["backward_euler"] = func() dynamo.Integrator { return integrators.NewBackwardEuler() }
+	r.integrators["trapezoidal"] = func() dynamo.Integrator { return integrators.NewTrapezoidal() }
+	r.integrators["bdf2"] = func() dynamo.Integrator { return integrators.NewBDF2() }
+	r.integrators["rosenbrock"] = func() dynamo.Integrator { return integrators.NewRosenbrock() }
+	r.integrators["euler_maruyama"] = func() dynamo.Integrator { return integrators.NewEulerMaruyama() }
+	r.integrators["milstein"] = func() dynamo.Integrator { return integrators.NewMilstein() }
+	r.integrators["srk"] = func() dynamo.Integrator { return integrators.NewSRK() }
+	r.integrators["dde"] = func() dynamo.Integrator { return integrators.NewMethodOfSteps(integrators.NewRK4()) }
+	r.integrators["projected_rk4"] = func() dynamo.Integrator { return integrators.NewProjected(integrators.NewRK4()) }
 }
 
 func (r *Registry) registerControllers() {
@@ -101,14 +142,143 @@ func (r *Registry) GetController(name string, params map[string]float64) (dynamo
 	return nil, fmt.Errorf("unknown controller: %s", name)
 }
 
+// ControllerDesign holds the vector settings of controllers designed from
+// the model itself: LQR and MPC weights, the setpoint, MPC bounds and the
+// loops of a multi-loop PID.
+type ControllerDesign struct {
+	Q, R       []float64
+	Setpoint   []float64
+	UMin, UMax []float64
+	XMin, XMax []float64
+	Loops      []control.Loop
+}
+
+// GetControllerFor is GetController for a particular model instance. An
+// "lqr" controller with Q or R weights is synthesized for dyn, with its
+// current parameters, instead of using the hand-tuned gains, and "mpc"
+// predicts with dyn. MPC reads "horizon", "mpc_dt" and "max_iter" from
+// params, and predicts with the linearization at the setpoint when
+// "linear" is nonzero. A "pid" with Loops runs those instead of the single
+// loop on x[0].
+func (r *Registry) GetControllerFor(name string, dyn dynamo.System, params map[string]float64, design ControllerDesign) (dynamo.Controller, error) {
+	switch {
+	case name == "lqr" && (len(design.Q) > 0 || len(design.R) > 0):
+		return control.NewLQRFor(dyn, design.Setpoint, design.Q, design.R)
+	case name == "mpc":
+		return newMPC(dyn, params, design)
+	case name == "pid" && len(design.Loops) > 0:
+		return control.NewMultiPID(dyn.ControlDim(), design.Loops)
+	}
+	return r.GetController(name, params)
+}
+
+func newMPC(dyn dynamo.System, params map[string]float64, design ControllerDesign) (*control.MPC, error) {
+	horizon, dt := control.DefaultHorizon, control.DefaultMPCDt
+	if v := params["horizon"]; v > 0 {
+		horizon = int(v)
+	}
+	if v := params["mpc_dt"]; v > 0 {
+		dt = v
+	}
+	model := dyn
+	if params["linear"] != 0 {
+		x0 := design.Setpoint
+		if len(x0) == 0 {
+			x0 = make(dynamo.State, dyn.StateDim())
+		}
+		model = control.NewLinearModel(dyn, x0, nil)
+	}
+	c, err := control.NewMPC(model, design.Setpoint, design.Q, design.R, horizon, dt)
+	if err != nil {
+		return nil, err
+	}
+	if v := params["max_iter"]; v > 0 {
+		c.MaxIter = int(v)
+	}
+	if err := c.SetInputBounds(design.UMin, design.UMax); err != nil {
+		return nil, err
+	}
+	if err := c.SetStateBounds(design.XMin, design.XMax); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetEstimator builds the named estimator for a model instance: "kf",
+// "ekf" or "ukf". The Kalman filter is linearized at x0, the origin when
+// empty. An empty name or "none" returns a nil estimator.
+func (r *Registry) GetEstimator(name string, dyn dynamo.System, sensor *estimation.Measurement, q []float64, x0 dynamo.State) (dynamo.Estimator, error) {
+	switch name {
+	case "", "none":
+		return nil, nil
+	case "kf":
+		if len(x0) == 0 {
+			x0 = make(dynamo.State, dyn.StateDim())
+		}
+		return estimation.NewKalman(dyn, x0, nil, sensor, q)
+	case "ekf":
+		return estimation.NewEKF(dyn, sensor, q)
+	case "ukf":
+		return estimation.NewUKF(dyn, sensor, q)
+	}
+	return nil, fmt.Errorf("unknown estimator: %s", name)
+}
+
+// GetModelSpec returns the catalog entry for a registered model.
+func (r *Registry) GetModelSpec(name string) (physics.Spec, error) {
+	if spec, ok := r.specs[name]; ok {
+		return spec, nil
+	}
+	return physics.Spec{}, fmt.Errorf("unknown model: %s", name)
+}
+
+// DefaultState returns the catalog initial state for a model instance.
+func (r *Registry) DefaultState(name string, dyn dynamo.System) (dynamo.State, error) {
+	spec, err := r.GetModelSpec(name)
+	if err != nil {
+		return nil, err
+	}
+	return spec.Init(dyn), nil
+}
+
 func (r *Registry) ListModels() []string {
 	names := make([]string, 0, len(r.models))
 	for name := range r.models {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
 
+// Factory returns a dynamo.Factory that builds fresh instances of the named
+// model, integrator and controller, so ensemble members share no state.
+// Controllers are built as GetControllerFor would, designed for the nominal
+// model rather than each member's perturbed one.
+func (r *Registry) Factory(model, integrator, controller string, params map[string]float64, design ControllerDesign) (dynamo.Factory, error) {
+	newModel, ok := r.models[model]
+	if !ok {
+		return dynamo.Factory{}, fmt.Errorf("unknown model: %s", model)
+	}
+	newIntegrator, ok := r.integrators[integrator]
+	if !ok {
+		return dynamo.Factory{}, fmt.Errorf("unknown integrator: %s", integrator)
+	}
+	// Design once up front so a bad design is reported here; members then
+	// repeat the same design and cannot fail.
+	if _, err := r.GetControllerFor(controller, newModel(), params, design); err != nil {
+		return dynamo.Factory{}, err
+	}
+	return dynamo.Factory{
+		System:     newModel,
+		Integrator: newIntegrator,
+		Controller: func() dynamo.Controller {
+			ctrl, _ := r.GetControllerFor(controller, newModel(), params, design)
+			return ctrl
+		},
+		Metrics: func() []dynamo.Metric { return r.DefaultMetrics(model) },
+	}, nil
+}
+
 func (r *Registry) DefaultMetrics(model string) []dynamo.Metric {
 	return []dynamo.Metric{
 		metrics.NewEnergy(1.0, 1.0, 9.81),
@@ -116,3 +286,25 @@ func (r *Registry) DefaultMetrics(model string) []dynamo.Metric {
 		metrics.NewControlEffort(),
 	}
 }
+
+// MetricsFor returns DefaultMetrics plus the metrics that need the model or
+// controller instance itself, such as constraint drift for constrained
+// models and solver statistics for optimizing controllers.
+func (r *Registry) MetricsFor(model string, dyn dynamo.System, ctrl dynamo.Controller) []dynamo.Metric {
+	ms := r.DefaultMetrics(model)
+	if c, ok := dyn.(dynamo.Constrained); ok {
+		ms = append(ms, metrics.NewConstraintDrift(c))
+	}
+	if o, ok := unwrap(ctrl).(dynamo.Optimizer); ok {
+		ms = append(ms, metrics.NewSolverIterations(o), metrics.NewSolverCost(o))
+	}
+	return ms
+}
+
+// unwrap strips the delay wrapper off a controller.
+func unwrap(ctrl dynamo.Controller) dynamo.Controller {
+	if d, ok := ctrl.(*control.Delayed); ok {
+		return d.Inner
+	}
+	return ctrl
+}
@@ -0,0 +1,224 @@
+package expr
+
+import "math"
+
+// The constructors below fold constants and drop identities such as x+0
+// and x*1, which keeps repeated derivatives from growing needlessly.
+
+// Num returns a constant.
+func Num(c float64) *Node { return &Node{op: "num", num: c} }
+
+// Var returns a reference to a named variable.
+func Var(name string) *Node { return &Node{op: "var", name: name} }
+
+func (n *Node) isNum(c float64) bool { return n.op == "num" && n.num == c }
+
+// Neg returns -a.
+func Neg(a *Node) *Node {
+	switch a.op {
+	case "num":
+		return Num(-a.num)
+	case "neg":
+		return a.args[0]
+	}
+	return &Node{op: "neg", args: []*Node{a}}
+}
+
+// Add returns a + b.
+func Add(a, b *Node) *Node {
+	switch {
+	case a.op == "num" && b.op == "num":
+		return Num(a.num + b.num)
+	case a.isNum(0):
+		return b
+	case b.isNum(0):
+		return a
+	}
+	return &Node{op: "+", args: []*Node{a, b}}
+}
+
+// Sub returns a - b.
+func Sub(a, b *Node) *Node {
+	switch {
+	case a.op == "num" && b.op == "num":
+		return Num(a.num - b.num)
+	case b.isNum(0):
+		return a
+	case a.isNum(0):
+		return Neg(b)
+	}
+	return &Node{op: "-", args: []*Node{a, b}}
+}
+
+// Mul returns a * b.
+func Mul(a, b *Node) *Node {
+	switch {
+	case a.op == "num" && b.op == "num":
+		return Num(a.num * b.num)
+	case a.isNum(0) || b.isNum(0):
+		return Num(0)
+	case a.isNum(1):
+		return b
+	case b.isNum(1):
+		return a
+	case a.isNum(-1):
+		return Neg(b)
+	case b.isNum(-1):
+		return Neg(a)
+	}
+	return &Node{op: "*", args: []*Node{a, b}}
+}
+
+// Div returns a / b.
+func Div(a, b *Node) *Node {
+	switch {
+	case a.op == "num" && b.op == "num" && b.num != 0:
+		return Num(a.num / b.num)
+	case a.isNum(0):
+		return Num(0)
+	case b.isNum(1):
+		return a
+	}
+	return &Node{op: "/", args: []*Node{a, b}}
+}
+
+// Pow returns a ^ b.
+func Pow(a, b *Node) *Node {
+	switch {
+	case a.op == "num" && b.op == "num":
+		return Num(math.Pow(a.num, b.num))
+	case b.isNum(0):
+		return Num(1)
+	case b.isNum(1):
+		return a
+	}
+	return &Node{op: "^", args: []*Node{a, b}}
+}
+
+func call(name string, args ...*Node) *Node {
+	constant := true
+	vals := make([]float64, len(args))
+	for i, a := range args {
+		constant = constant && a.op == "num"
+		vals[i] = a.num
+	}
+	if constant {
+		return Num(functions[name].call(vals))
+	}
+	return &Node{op: "call", name: name, args: args}
+}
+
+// Depends reports whether the expression reads the variable name.
+func (n *Node) Depends(name string) bool {
+	if n.op == "var" {
+		return n.name == name
+	}
+	for _, a := range n.args {
+		if a.Depends(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the partial derivative of the expression with respect to
+// the variable name. Comparisons and step functions such as floor and sign
+// differentiate to zero; min, max, abs and if pick the derivative of the
+// active branch.
+func (n *Node) Diff(name string) *Node {
+	if !n.Depends(name) {
+		return Num(0)
+	}
+	if n.op == "var" {
+		return Num(1)
+	}
+	a := n.args[0]
+	da := a.Diff(name)
+	switch n.op {
+	case "neg":
+		return Neg(da)
+	case "call":
+		return n.diffCall(name, da)
+	}
+	b := n.args[1]
+	db := b.Diff(name)
+	switch n.op {
+	case "+":
+		return Add(da, db)
+	case "-":
+		return Sub(da, db)
+	case "*":
+		return Add(Mul(da, b), Mul(a, db))
+	case "/":
+		return Div(Sub(Mul(da, b), Mul(a, db)), Pow(b, Num(2)))
+	case "^":
+		return diffPow(a, b, da, db, name)
+	}
+	return Num(0) // comparisons
+}
+
+func diffPow(a, b, da, db *Node, name string) *Node {
+	if !b.Depends(name) {
+		// d(a^c) = c a^(c-1) da
+		return Mul(Mul(b, Pow(a, Sub(b, Num(1)))), da)
+	}
+	// d(a^b) = a^b (db ln a + b da / a)
+	return Mul(Pow(a, b), Add(Mul(db, call("log", a)), Div(Mul(b, da), a)))
+}
+
+func (n *Node) diffCall(name string, da *Node) *Node {
+	a := n.args[0]
+	switch n.name {
+	case "sin":
+		return Mul(call("cos", a), da)
+	case "cos":
+		return Neg(Mul(call("sin", a), da))
+	case "tan":
+		return Div(da, Pow(call("cos", a), Num(2)))
+	case "asin":
+		return Div(da, call("sqrt", Sub(Num(1), Pow(a, Num(2)))))
+	case "acos":
+		return Neg(Div(da, call("sqrt", Sub(Num(1), Pow(a, Num(2))))))
+	case "atan":
+		return Div(da, Add(Num(1), Pow(a, Num(2))))
+	case "sinh":
+		return Mul(call("cosh", a), da)
+	case "cosh":
+		return Mul(call("sinh", a), da)
+	case "tanh":
+		return Mul(Sub(Num(1), Pow(n, Num(2))), da)
+	case "exp":
+		return Mul(n, da)
+	case "log":
+		return Div(da, a)
+	case "log10":
+		return Div(da, Mul(a, Num(math.Ln10)))
+	case "sqrt":
+		return Div(da, Mul(Num(2), n))
+	case "abs":
+		return Mul(call("sign", a), da)
+	case "floor", "ceil", "sign":
+		return Num(0)
+	case "if":
+		return call("if", a, n.args[1].Diff(name), n.args[2].Diff(name))
+	}
+	b := n.args[1]
+	db := b.Diff(name)
+	switch n.name {
+	case "atan2":
+		// atan2(a, b) is the angle of (b, a)
+		return Div(Sub(Mul(b, da), Mul(a, db)), Add(Pow(a, Num(2)), Pow(b, Num(2))))
+	case "pow":
+		return diffPow(a, b, da, db, name)
+	case "hypot":
+		return Div(Add(Mul(a, da), Mul(b, db)), n)
+	case "min":
+		return call("if", &Node{op: "<=", args: []*Node{a, b}}, da, db)
+	case "max":
+		return call("if", &Node{op: ">=", args: []*Node{a, b}}, da, db)
+	case "mod":
+		// mod(a, b) = a - trunc(a/b) b
+		return Sub(da, Mul(Div(Sub(a, n), b), db))
+	}
+	return Num(0)
+}
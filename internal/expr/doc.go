@@ -0,0 +1,12 @@
+// Package expr compiles arithmetic expressions such as
+//
+//	sigma*(y - x) + 0.5*sin(omega*t)
+//
+// into functions over a vector of named variables. It supports + - * / ^,
+// unary minus, comparisons (yielding 1 or 0), parentheses, the constants
+// pi and e, and the usual math functions, including if(cond, a, b) for
+// piecewise definitions.
+//
+// Parsed expressions can also be differentiated symbolically with
+// [Node.Diff], which is how package lagrange derives equations of motion.
+package expr
@@ -0,0 +1,146 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/san-kum/dynsim/internal/autodiff"
+)
+
+// DualFunc evaluates a compiled expression on dual numbers.
+type DualFunc func(vars []autodiff.Dual) autodiff.Dual
+
+// CompileDual turns the tree into a DualFunc over the variables in names,
+// for forward-mode differentiation of models defined by expressions.
+func (n *Node) CompileDual(names []string) (DualFunc, error) {
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	return n.compileDual(index)
+}
+
+func (n *Node) compileDual(index map[string]int) (DualFunc, error) {
+	switch n.op {
+	case "num":
+		c := autodiff.Const(n.num)
+		return func([]autodiff.Dual) autodiff.Dual { return c }, nil
+	case "var":
+		if i, ok := index[n.name]; ok {
+			return func(v []autodiff.Dual) autodiff.Dual { return v[i] }, nil
+		}
+		if c, ok := constants[n.name]; ok {
+			d := autodiff.Const(c)
+			return func([]autodiff.Dual) autodiff.Dual { return d }, nil
+		}
+		return nil, fmt.Errorf("unknown variable: %s", n.name)
+	}
+	args := make([]DualFunc, len(n.args))
+	for i, a := range n.args {
+		f, err := a.compileDual(index)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = f
+	}
+	switch n.op {
+	case "neg":
+		x := args[0]
+		return func(v []autodiff.Dual) autodiff.Dual { return x(v).Neg() }, nil
+	case "call":
+		call := dualFunctions[n.name]
+		return func(v []autodiff.Dual) autodiff.Dual {
+			vals := make([]autodiff.Dual, len(args))
+			for i, a := range args {
+				vals[i] = a(v)
+			}
+			return call(vals)
+		}, nil
+	}
+	a, b := args[0], args[1]
+	switch n.op {
+	case "+":
+		return func(v []autodiff.Dual) autodiff.Dual { return a(v).Add(b(v)) }, nil
+	case "-":
+		return func(v []autodiff.Dual) autodiff.Dual { return a(v).Sub(b(v)) }, nil
+	case "*":
+		return func(v []autodiff.Dual) autodiff.Dual { return a(v).Mul(b(v)) }, nil
+	case "/":
+		return func(v []autodiff.Dual) autodiff.Dual { return a(v).Div(b(v)) }, nil
+	case "^":
+		return func(v []autodiff.Dual) autodiff.Dual { return dualPow(a(v), b(v)) }, nil
+	}
+	cmp := binaryOps[n.op]
+	return func(v []autodiff.Dual) autodiff.Dual {
+		return autodiff.Const(cmp(a(v).Re, b(v).Re))
+	}, nil
+}
+
+// dualPow keeps constant exponents on the power rule, so negative bases
+// with integer exponents stay finite.
+func dualPow(a, b autodiff.Dual) autodiff.Dual {
+	if b.Du == 0 {
+		return autodiff.Pow(a, b.Re)
+	}
+	return autodiff.Exp(b.Mul(autodiff.Log(a)))
+}
+
+func dualUnary(f func(autodiff.Dual) autodiff.Dual) func([]autodiff.Dual) autodiff.Dual {
+	return func(a []autodiff.Dual) autodiff.Dual { return f(a[0]) }
+}
+
+// constantDual lifts a function whose derivative is zero almost everywhere.
+func constantDual(f func(float64) float64) func([]autodiff.Dual) autodiff.Dual {
+	return func(a []autodiff.Dual) autodiff.Dual { return autodiff.Const(f(a[0].Re)) }
+}
+
+var dualFunctions = map[string]func([]autodiff.Dual) autodiff.Dual{
+	"sin":  dualUnary(autodiff.Sin),
+	"cos":  dualUnary(autodiff.Cos),
+	"tan":  dualUnary(autodiff.Tan),
+	"atan": dualUnary(autodiff.Atan),
+	"asin": func(a []autodiff.Dual) autodiff.Dual {
+		x := a[0]
+		return autodiff.Dual{Re: math.Asin(x.Re), Du: x.Du / math.Sqrt(1-x.Re*x.Re)}
+	},
+	"acos": func(a []autodiff.Dual) autodiff.Dual {
+		x := a[0]
+		return autodiff.Dual{Re: math.Acos(x.Re), Du: -x.Du / math.Sqrt(1-x.Re*x.Re)}
+	},
+	"sinh": func(a []autodiff.Dual) autodiff.Dual {
+		x := a[0]
+		return autodiff.Dual{Re: math.Sinh(x.Re), Du: math.Cosh(x.Re) * x.Du}
+	},
+	"cosh": func(a []autodiff.Dual) autodiff.Dual {
+		x := a[0]
+		return autodiff.Dual{Re: math.Cosh(x.Re), Du: math.Sinh(x.Re) * x.Du}
+	},
+	"tanh": dualUnary(autodiff.Tanh),
+	"exp":  dualUnary(autodiff.Exp),
+	"log":  dualUnary(autodiff.Log),
+	"log10": func(a []autodiff.Dual) autodiff.Dual {
+		return autodiff.Log(a[0]).Scale(1 / math.Ln10)
+	},
+	"sqrt":  dualUnary(autodiff.Sqrt),
+	"abs":   dualUnary(autodiff.Abs),
+	"floor": constantDual(math.Floor),
+	"ceil":  constantDual(math.Ceil),
+	"sign": func(a []autodiff.Dual) autodiff.Dual {
+		return autodiff.Const(functions["sign"].call([]float64{a[0].Re}))
+	},
+	"atan2": func(a []autodiff.Dual) autodiff.Dual { return autodiff.Atan2(a[0], a[1]) },
+	"pow":   func(a []autodiff.Dual) autodiff.Dual { return dualPow(a[0], a[1]) },
+	"hypot": func(a []autodiff.Dual) autodiff.Dual { return autodiff.Hypot(a[0], a[1]) },
+	"min":   func(a []autodiff.Dual) autodiff.Dual { return autodiff.Min(a[0], a[1]) },
+	"max":   func(a []autodiff.Dual) autodiff.Dual { return autodiff.Max(a[0], a[1]) },
+	"mod": func(a []autodiff.Dual) autodiff.Dual {
+		x, y := a[0], a[1]
+		return autodiff.Dual{Re: math.Mod(x.Re, y.Re), Du: x.Du - math.Trunc(x.Re/y.Re)*y.Du}
+	},
+	"if": func(a []autodiff.Dual) autodiff.Dual {
+		if a[0].Re != 0 {
+			return a[1]
+		}
+		return a[2]
+	},
+}
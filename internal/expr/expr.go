@@ -0,0 +1,219 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Func evaluates a compiled expression. vars holds the variable values in
+// the order of the names it was compiled against.
+type Func func(vars []float64) float64
+
+// Node is a parsed expression. Nodes are immutable; Diff and the
+// simplifying constructors share subtrees freely.
+type Node struct {
+	op   string // "num", "var", "neg", "call" or a binary operator
+	num  float64
+	name string // variable or function name
+	args []*Node
+}
+
+// Parse parses src into an expression tree.
+func Parse(src string) (*Node, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("expr %q: %w", src, err)
+	}
+	p := &parser{toks: toks}
+	n, err := p.comparison()
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.unexpected()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expr %q: %w", src, err)
+	}
+	return n, nil
+}
+
+// Compile parses src into a Func over the variables in names.
+func Compile(src string, names []string) (Func, error) {
+	n, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	fn, err := n.Compile(names)
+	if err != nil {
+		return nil, fmt.Errorf("expr %q: %w", src, err)
+	}
+	return fn, nil
+}
+
+// Compile turns the tree into a Func over the variables in names. The
+// constants pi and e are available unless names shadows them.
+func (n *Node) Compile(names []string) (Func, error) {
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	return n.compile(index)
+}
+
+func (n *Node) compile(index map[string]int) (Func, error) {
+	switch n.op {
+	case "num":
+		c := n.num
+		return func([]float64) float64 { return c }, nil
+	case "var":
+		if i, ok := index[n.name]; ok {
+			return func(v []float64) float64 { return v[i] }, nil
+		}
+		if c, ok := constants[n.name]; ok {
+			return func([]float64) float64 { return c }, nil
+		}
+		return nil, fmt.Errorf("unknown variable: %s", n.name)
+	}
+	args := make([]Func, len(n.args))
+	for i, a := range n.args {
+		f, err := a.compile(index)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = f
+	}
+	switch n.op {
+	case "neg":
+		x := args[0]
+		return func(v []float64) float64 { return -x(v) }, nil
+	case "call":
+		// One closure per arity, so evaluation allocates nothing and the
+		// compiled Func stays safe to share between goroutines.
+		fn := functions[n.name]
+		switch fn.arity {
+		case 1:
+			f, a := fn.f1, args[0]
+			return func(v []float64) float64 { return f(a(v)) }, nil
+		case 2:
+			f, a, b := fn.f2, args[0], args[1]
+			return func(v []float64) float64 { return f(a(v), b(v)) }, nil
+		}
+		f, a, b, c := fn.f3, args[0], args[1], args[2]
+		return func(v []float64) float64 { return f(a(v), b(v), c(v)) }, nil
+	}
+	a, b, op := args[0], args[1], binaryOps[n.op]
+	return func(v []float64) float64 { return op(a(v), b(v)) }, nil
+}
+
+// String formats the tree with explicit parentheses around every binary
+// operation.
+func (n *Node) String() string {
+	switch n.op {
+	case "num":
+		return strconv.FormatFloat(n.num, 'g', -1, 64)
+	case "var":
+		return n.name
+	case "neg":
+		return "-" + n.args[0].String()
+	case "call":
+		parts := make([]string, len(n.args))
+		for i, a := range n.args {
+			parts[i] = a.String()
+		}
+		return n.name + "(" + strings.Join(parts, ", ") + ")"
+	}
+	return "(" + n.args[0].String() + " " + n.op + " " + n.args[1].String() + ")"
+}
+
+func boolean(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var binaryOps = map[string]func(a, b float64) float64{
+	"+":  func(a, b float64) float64 { return a + b },
+	"-":  func(a, b float64) float64 { return a - b },
+	"*":  func(a, b float64) float64 { return a * b },
+	"/":  func(a, b float64) float64 { return a / b },
+	"^":  math.Pow,
+	"<":  func(a, b float64) float64 { return boolean(a < b) },
+	">":  func(a, b float64) float64 { return boolean(a > b) },
+	"<=": func(a, b float64) float64 { return boolean(a <= b) },
+	">=": func(a, b float64) float64 { return boolean(a >= b) },
+	"==": func(a, b float64) float64 { return boolean(a == b) },
+	"!=": func(a, b float64) float64 { return boolean(a != b) },
+}
+
+// function is a built-in function. Only the field for its arity is set.
+type function struct {
+	arity int
+	f1    func(float64) float64
+	f2    func(float64, float64) float64
+	f3    func(float64, float64, float64) float64
+}
+
+func unary(f func(float64) float64) function {
+	return function{arity: 1, f1: f}
+}
+
+func binary(f func(float64, float64) float64) function {
+	return function{arity: 2, f2: f}
+}
+
+// call applies the function to len(args) == arity values.
+func (f function) call(args []float64) float64 {
+	switch f.arity {
+	case 1:
+		return f.f1(args[0])
+	case 2:
+		return f.f2(args[0], args[1])
+	}
+	return f.f3(args[0], args[1], args[2])
+}
+
+var functions = map[string]function{
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"sinh":  unary(math.Sinh),
+	"cosh":  unary(math.Cosh),
+	"tanh":  unary(math.Tanh),
+	"exp":   unary(math.Exp),
+	"log":   unary(math.Log),
+	"log10": unary(math.Log10),
+	"sqrt":  unary(math.Sqrt),
+	"abs":   unary(math.Abs),
+	"floor": unary(math.Floor),
+	"ceil":  unary(math.Ceil),
+	"sign": unary(func(x float64) float64 {
+		switch {
+		case x > 0:
+			return 1
+		case x < 0:
+			return -1
+		}
+		return 0
+	}),
+	"atan2": binary(math.Atan2),
+	"pow":   binary(math.Pow),
+	"hypot": binary(math.Hypot),
+	"min":   binary(math.Min),
+	"max":   binary(math.Max),
+	"mod":   binary(math.Mod),
+	"if": {arity: 3, f3: func(cond, a, b float64) float64 {
+		if cond != 0 {
+			return a
+		}
+		return b
+	}},
+}
+
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
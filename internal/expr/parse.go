@@ -0,0 +1,187 @@
+package expr
+
+import "fmt"
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) unexpected() error { return unexpected(p.peek()) }
+
+func unexpected(t token) error {
+	if t.kind == tokEOF {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+func (p *parser) isOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// comparison := sum (cmp sum)?
+func (p *parser) comparison() (*Node, error) {
+	lhs, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.isOp("<", ">", "<=", ">=", "==", "!=")
+	if !ok {
+		return lhs, nil
+	}
+	p.next()
+	rhs, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	return &Node{op: op, args: []*Node{lhs, rhs}}, nil
+}
+
+// sum := product (('+' | '-') product)*
+func (p *parser) sum() (*Node, error) {
+	lhs, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.isOp("+", "-")
+		if !ok {
+			return lhs, nil
+		}
+		p.next()
+		rhs, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &Node{op: op, args: []*Node{lhs, rhs}}
+	}
+}
+
+// product := unary (('*' | '/') unary)*
+func (p *parser) product() (*Node, error) {
+	lhs, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.isOp("*", "/")
+		if !ok {
+			return lhs, nil
+		}
+		p.next()
+		rhs, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &Node{op: op, args: []*Node{lhs, rhs}}
+	}
+}
+
+// unary := ('-' | '+') unary | power
+func (p *parser) unary() (*Node, error) {
+	if op, ok := p.isOp("-", "+"); ok {
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "-" {
+			return &Node{op: "neg", args: []*Node{x}}, nil
+		}
+		return x, nil
+	}
+	return p.power()
+}
+
+// power := primary ('^' unary)?, right associative so a^b^c = a^(b^c)
+// and -x^2 = -(x^2).
+func (p *parser) power() (*Node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.isOp("^"); !ok {
+		return base, nil
+	}
+	p.next()
+	exp, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &Node{op: "^", args: []*Node{base, exp}}, nil
+}
+
+// primary := number | variable | call | '(' comparison ')'
+func (p *parser) primary() (*Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return Num(t.num), nil
+	case tokLParen:
+		x, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.unexpected()
+		}
+		p.next()
+		return x, nil
+	case tokIdent:
+		if p.peek().kind == tokLParen {
+			return p.call(t)
+		}
+		return Var(t.text), nil
+	}
+	return nil, unexpected(t)
+}
+
+func (p *parser) call(name token) (*Node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function: %s", name.text)
+	}
+	p.next() // (
+	var args []*Node
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.comparison()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.peek().kind != tokRParen {
+		return nil, p.unexpected()
+	}
+	p.next()
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", name.text, fn.arity, len(args))
+	}
+	return &Node{op: "call", name: name.text, args: args}, nil
+}
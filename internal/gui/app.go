@@ -81,12 +81,7 @@ func loadFont() rl.Font {
 }
 
 func NewApp(startModel string, interactive bool) *App {
-	models := []string{
-		"fluid", "nbody", "lorenz", "rossler", "pendulum", "double_pendulum", "cartpole",
-		"spring_mass", "drone", "threebody", "coupled", "masschain", "gyroscope",
-		"wave", "doublewell", "duffing", "magnetic", "vanderpol",
-	}
-	sort.Strings(models)
+	models := physics.ModelNames()
 
 	// Audio Init
 	proc := audio.NewProcessor()
@@ -163,8 +158,12 @@ func (a *App) RunLoop() {
 }
 
 func (a *App) loadModel(name string) {
-	var dyn dynamo.System
-	var state []float64
+	spec, ok := physics.Lookup(name)
+	if !ok {
+		// Fallback to Pendulum if unknown
+		spec, _ = physics.Lookup("pendulum")
+	}
+	dyn, state := spec.Build()
 
 	// Reset Backend Flags
 	a.UseCompute = false
@@ -178,81 +177,26 @@ func (a *App) loadModel(name string) {
 		rl.CameraPerspective,
 	)
 
-	switch name {
+	switch spec.Name {
 	case "fluid":
-		dyn, state = physics.NewSPH(400), physics.NewSPH(400).DefaultState()
 		a.Camera.Position = rl.NewVector3(30, 20, 70)
 		a.Camera.Target = rl.NewVector3(30, 20, 0)
 	case "nbody":
 		// Massive Scale GPU Implementation
-		// We don't use CPU dyn/state for physics, just for controller placeholder
-		dyn, state = physics.NewNBody(1), []float64{0}
+		// The CPU model only backs the controller; physics runs in the compute shader
 		a.UseCompute = true
 		a.GLBackend = compute.NewOpenGLBackend(65536) // Start with 64k particles
 		a.Camera.Position = rl.NewVector3(0, 0, 400)
-
 	case "hybrid":
-		// 8k Stars + 4k Gas
-		h := physics.NewHybrid(8192, 4096)
-		dyn, state = h, h.DefaultState()
 		a.Camera.Position = rl.NewVector3(0, 0, 150)
-	case "lorenz":
-		dyn, state = physics.NewLorenz(), []float64{1, 1, 1}
-	case "rossler":
-		dyn, state = physics.NewRossler(), []float64{1, 1, 1}
-	case "doublewell":
-		dyn, state = physics.NewDoubleWell(), []float64{0.5, 0}
 
 	// Mechanical Systems
-	case "pendulum":
-		dyn, state = physics.NewPendulum(), []float64{0.5, 0}
-		a.Camera.Position = rl.NewVector3(0, 5, 20)
-		a.Camera.Target = rl.NewVector3(0, 5, 0)
-	case "double_pendulum":
-		dyn, state = physics.NewDoublePendulum(), []float64{0.5, 0.5, 0, 0}
-		a.Camera.Position = rl.NewVector3(0, 5, 20)
-		a.Camera.Target = rl.NewVector3(0, 5, 0)
-	case "cartpole":
-		dyn, state = physics.NewCartPole(), []float64{0, 0, 0.1, 0}
-		a.Camera.Position = rl.NewVector3(0, 5, 20)
-		a.Camera.Target = rl.NewVector3(0, 5, 0)
-	case "spring_mass":
-		dyn, state = physics.NewSpringMass(), []float64{1, 0}
-	case "drone":
-		dyn, state = physics.NewDrone(), []float64{0, 5, 0, 0, 0, 0}
+	case "pendulum", "double_pendulum", "cartpole", "drone":
 		a.Camera.Position = rl.NewVector3(0, 5, 20)
 		a.Camera.Target = rl.NewVector3(0, 5, 0)
-	case "threebody":
-		t := physics.NewThreeBody()
-		dyn, state = t, t.DefaultState()
-	case "coupled":
-		dyn, state = physics.NewCoupledPendulums(), []float64{0.5, 0, 0, 0}
-	case "masschain":
-		m := physics.NewMassChain(40)
-		dyn, state = m, m.DefaultState()
-		a.Camera.Position = rl.NewVector3(30, 10, 60)
-		a.Camera.Target = rl.NewVector3(30, 10, 0)
-	case "gyroscope":
-		g := physics.NewGyroscope()
-		dyn, state = g, g.DefaultState()
-	case "wave":
-		w := physics.NewWave(50)
-		dyn, state = w, w.DefaultState()
+	case "masschain", "wave":
 		a.Camera.Position = rl.NewVector3(30, 10, 60)
 		a.Camera.Target = rl.NewVector3(30, 10, 0)
-	case "duffing":
-		d := physics.NewDuffing()
-		dyn, state = d, d.DefaultState()
-	case "magnetic":
-		m := physics.NewMagneticPendulum()
-		dyn, state = m, m.DefaultState()
-	case "vanderpol":
-		v := physics.NewVanDerPol()
-		dyn, state = v, v.DefaultState()
-
-	default:
-		// Fallback to Pendulum if unknown
-		dyn, state = physics.NewPendulum(), []float64{0.5, 0}
 	}
 
 	a.Dyn = dyn
@@ -268,7 +212,7 @@ func (a *App) loadModel(name string) {
 		a.Ctrl = control.NewNone(ctrlDim)
 	}
 
-	a.ModelName = name
+	a.ModelName = spec.Name
 	a.Time = 0
 	a.Dt = 0.016
 	a.Running = true
@@ -369,6 +313,11 @@ func (a *App) Update() {
 			if rl.IsKeyPressed(rl.KeyLeft) || rl.IsKeyPressed(rl.KeyH) {
 				a.Params[key] -= step
 			}
+			if p, ok := a.Dyn.(dynamo.Parameterized); ok {
+				if param, ok := dynamo.LookupParam(p.Params(), key); ok {
+					a.Params[key] = param.Clamp(a.Params[key])
+				}
+			}
 		}
 		return
 	}
@@ -665,6 +614,8 @@ func (a *App) drawSim() {
 	switch a.ModelName {
 	case "fluid":
 		a.RenderSPH()
+	case "hybrid":
+		a.RenderHybrid()
 	case "nbody":
 		a.RenderComputeNBody() // Replaced CPU Render
 	case "lorenz", "rossler":
@@ -0,0 +1,86 @@
+package integrators
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// MethodOfSteps integrates delay systems by recording the trajectory as it
+// goes and splitting each step so no substep is longer than the shortest
+// lag; every delayed term then falls inside the recorded past and the inner
+// integrator sees an ordinary ODE. The state before the first step is held
+// constant as the initial history. Other systems pass straight through.
+type MethodOfSteps struct {
+	inner dynamo.Integrator
+	past  *dynamo.History
+}
+
+func NewMethodOfSteps(inner dynamo.Integrator) *MethodOfSteps {
+	return &MethodOfSteps{inner: inner}
+}
+
+func (m *MethodOfSteps) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
+	ds, ok := dynamo.Unwrap(dyn).(dynamo.DelaySystem)
+	if !ok {
+		return m.inner.Step(dyn, x, u, t, dt)
+	}
+
+	minLag, maxLag := math.Inf(1), 0.0
+	for _, tau := range ds.Delays() {
+		minLag = math.Min(minLag, tau)
+		maxLag = math.Max(maxLag, tau)
+	}
+	if m.past == nil {
+		m.past = dynamo.NewHistory(maxLag)
+	}
+	m.past.Span = maxLag
+	// A new run or a retried step restarts from t and discards whatever had
+	// been recorded beyond it.
+	m.past.Push(t, x)
+
+	bound := dynamo.WithHistory(dyn, m.past)
+	n := 1
+	if dt > minLag {
+		n = int(math.Ceil(dt / minLag))
+	}
+	h := dt / float64(n)
+	for k := 0; k < n; k++ {
+		tk := t + float64(k)*h
+		x = m.inner.Step(bound, x, u, tk, h)
+		m.past.Push(tk+h, x)
+	}
+	return x
+}
+
+// methodOfStepsState is a saved MethodOfSteps: its history and whatever the
+// inner integrator saved.
+type methodOfStepsState struct {
+	past  *dynamo.History
+	inner any
+}
+
+// SaveState implements dynamo.StatefulIntegrator, so the partial steps of
+// event location do not leave their samples in the history.
+func (m *MethodOfSteps) SaveState() any {
+	var saved methodOfStepsState
+	if m.past != nil {
+		saved.past = m.past.Clone()
+	}
+	if si, ok := m.inner.(dynamo.StatefulIntegrator); ok {
+		saved.inner = si.SaveState()
+	}
+	return saved
+}
+
+// RestoreState implements dynamo.StatefulIntegrator.
+func (m *MethodOfSteps) RestoreState(saved any) {
+	st := saved.(methodOfStepsState)
+	m.past = nil
+	if st.past != nil {
+		m.past = st.past.Clone()
+	}
+	if si, ok := m.inner.(dynamo.StatefulIntegrator); ok {
+		si.RestoreState(st.inner)
+	}
+}
@@ -0,0 +1,240 @@
+package integrators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// Newton iteration limits shared by the implicit methods.
+const (
+	newtonTol     = 1e-10
+	newtonMaxIter = 20
+)
+
+// iterationMatrix factors I - hg*J, the matrix every implicit method here
+// solves against.
+func iterationMatrix(jac [][]float64, hg float64) (*linalg.LU, error) {
+	n := len(jac)
+	m := linalg.Identity(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			m[i][j] -= hg * jac[i][j]
+		}
+	}
+	return linalg.Factor(m)
+}
+
+// solveImplicit solves y = c + hg*f(y, t1) by simplified Newton iteration,
+// reusing the Jacobian at the initial guess y0. A singular iteration matrix
+// or an iteration that does not settle within newtonMaxIter returns
+// dynamo.ErrNoConvergence along with the last iterate.
+func solveImplicit(dyn dynamo.System, u dynamo.Control, t1 float64, c, y0 dynamo.State, hg float64) (dynamo.State, error) {
+	lu, err := iterationMatrix(dynamo.StateJacobian(dyn, y0, u, t1), hg)
+	if err != nil {
+		return y0.Clone(), fmt.Errorf("%w: %v", dynamo.ErrNoConvergence, err)
+	}
+
+	y := y0.Clone()
+	res := make([]float64, len(y))
+	for iter := 0; iter < newtonMaxIter; iter++ {
+		f := dyn.Derive(y, u, t1)
+		for i := range y {
+			res[i] = c[i] + hg*f[i] - y[i]
+		}
+		delta := lu.Solve(res)
+		norm, scale := 0.0, 0.0
+		for i := range y {
+			y[i] += delta[i]
+			norm = math.Max(norm, math.Abs(delta[i]))
+			scale = math.Max(scale, math.Abs(y[i]))
+		}
+		if norm <= newtonTol*(1+scale) {
+			return y, nil
+		}
+	}
+	return y, fmt.Errorf("%w in %d Newton iterations at t=%g", dynamo.ErrNoConvergence, newtonMaxIter, t1)
+}
+
+// BackwardEuler is the first-order implicit Euler method, L-stable and
+// strongly damping.
+type BackwardEuler struct{}
+
+func NewBackwardEuler() *BackwardEuler {
+	return &BackwardEuler{}
+}
+
+func (b *BackwardEuler) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
+	next, _ := b.TryStep(dyn, x, u, t, dt)
+	return next
+}
+
+// TryStep implements dynamo.FallibleIntegrator.
+func (b *BackwardEuler) TryStep(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) (dynamo.State, error) {
+	return solveImplicit(dyn, u, t+dt, x, x, dt)
+}
+
+// Trapezoidal is the second-order implicit trapezoidal rule (Crank-Nicolson).
+// It is A-stable and does not damp oscillations, but stiff modes ring.
+type Trapezoidal struct{}
+
+func NewTrapezoidal() *Trapezoidal {
+	return &Trapezoidal{}
+}
+
+func (tr *Trapezoidal) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
+	next, _ := tr.TryStep(dyn, x, u, t, dt)
+	return next
+}
+
+// TryStep implements dynamo.FallibleIntegrator.
+func (tr *Trapezoidal) TryStep(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) (dynamo.State, error) {
+	f := dyn.Derive(x, u, t)
+	c := make(dynamo.State, len(x))
+	guess := make(dynamo.State, len(x))
+	for i := range x {
+		c[i] = x[i] + 0.5*dt*f[i]
+		guess[i] = x[i] + dt*f[i]
+	}
+	return solveImplicit(dyn, u, t+dt, c, guess, 0.5*dt)
+}
+
+// BDF2 is the variable-step second-order backward differentiation formula.
+// It remembers the previous step, so an instance must not be shared between
+// runs in parallel; whenever a step does not continue the last one it
+// restarts with a backward Euler step. The simulator saves and restores
+// that memory around trial steps, see dynamo.StatefulIntegrator, so event
+// location and step doubling keep second order.
+type BDF2 struct {
+	prev   dynamo.State
+	last   dynamo.State
+	lastT  float64
+	lastDt float64
+}
+
+func NewBDF2() *BDF2 {
+	return &BDF2{}
+}
+
+func (b *BDF2) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
+	next, _ := b.TryStep(dyn, x, u, t, dt)
+	return next
+}
+
+// TryStep implements dynamo.FallibleIntegrator.
+func (b *BDF2) TryStep(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) (dynamo.State, error) {
+	var next dynamo.State
+	var err error
+	if b.continues(x, t) {
+		w := dt / b.lastDt
+		a1 := (1 + w) * (1 + w) / (1 + 2*w)
+		a0 := w * w / (1 + 2*w)
+		beta := (1 + w) / (1 + 2*w)
+		c := make(dynamo.State, len(x))
+		guess := make(dynamo.State, len(x))
+		for i := range x {
+			c[i] = a1*x[i] - a0*b.prev[i]
+			guess[i] = x[i] + w*(x[i]-b.prev[i])
+		}
+		next, err = solveImplicit(dyn, u, t+dt, c, guess, beta*dt)
+	} else {
+		next, err = solveImplicit(dyn, u, t+dt, x, x, dt)
+	}
+
+	b.prev = x.Clone()
+	b.last = next.Clone()
+	b.lastT = t + dt
+	b.lastDt = dt
+	return next, err
+}
+
+// SaveState implements dynamo.StatefulIntegrator. Steps replace the saved
+// slices rather than writing into them, so a shallow copy will do.
+func (b *BDF2) SaveState() any { return *b }
+
+// RestoreState implements dynamo.StatefulIntegrator.
+func (b *BDF2) RestoreState(saved any) { *b = saved.(BDF2) }
+
+// continues reports whether (t, x) is where the previous step ended.
+func (b *BDF2) continues(x dynamo.State, t float64) bool {
+	if b.last == nil || len(x) != len(b.last) {
+		return false
+	}
+	if math.Abs(t-b.lastT) > 1e-9*math.Max(1, math.Abs(t)) {
+		return false
+	}
+	for i := range x {
+		if x[i] != b.last[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Rosenbrock is the two-stage, second-order, L-stable ROS2 method. It only
+// needs linear solves, no Newton iteration, and its embedded first-order
+// solution drives adaptive stepping. Explicit time dependence of f is
+// treated as frozen over the step.
+type Rosenbrock struct {
+	safety   float64
+	minScale float64
+	maxScale float64
+}
+
+func NewRosenbrock() *Rosenbrock {
+	return &Rosenbrock{
+		safety:   0.9,
+		minScale: 0.2,
+		maxScale: 5.0,
+	}
+}
+
+// rosGamma is the ROS2 stability parameter 1 + 1/sqrt(2).
+var rosGamma = 1 + 1/math.Sqrt2
+
+func (r *Rosenbrock) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
+	newX, _, _ := r.StepAdaptive(dyn, x, u, t, dt, 1e-6)
+	return newX
+}
+
+func (r *Rosenbrock) StepAdaptive(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt, tol float64) (dynamo.State, float64, error) {
+	n := len(x)
+	lu, err := iterationMatrix(dynamo.StateJacobian(dyn, x, u, t), rosGamma*dt)
+	if err != nil {
+		return x.Clone(), dt * r.minScale, dynamo.ErrStepRejected
+	}
+
+	f0 := dyn.Derive(x, u, t)
+	k1 := lu.Solve(f0)
+
+	x1 := make(dynamo.State, n)
+	for i := 0; i < n; i++ {
+		x1[i] = x[i] + dt*k1[i]
+	}
+	f1 := dyn.Derive(x1, u, t+dt)
+	for i := 0; i < n; i++ {
+		f1[i] -= 2 * k1[i]
+	}
+	k2 := lu.Solve(f1)
+
+	xNew := make(dynamo.State, n)
+	errMax := 0.0
+	for i := 0; i < n; i++ {
+		xNew[i] = x[i] + dt*(1.5*k1[i]+0.5*k2[i])
+		// difference from the embedded first-order solution x + dt*k1
+		errEst := 0.5 * dt * (k1[i] + k2[i])
+		scale := math.Abs(x[i]) + math.Abs(dt*f0[i]) + 1e-10
+		errMax = math.Max(errMax, math.Abs(errEst)/scale)
+	}
+
+	errRatio := errMax / tol
+	if errRatio > 1 {
+		return xNew, dt * math.Max(r.minScale, r.safety/math.Sqrt(errRatio)), dynamo.ErrStepRejected
+	}
+	if errRatio > 0 {
+		return xNew, dt * math.Min(r.maxScale, r.safety/math.Sqrt(errRatio)), nil
+	}
+	return xNew, dt * r.maxScale, nil
+}
@@ -0,0 +1,22 @@
+package integrators
+
+import "github.com/san-kum/dynsim/internal/dynamo"
+
+// Projected follows every step of its inner integrator with a projection
+// onto the constraint manifold of dynamo.Constrained systems, so constraint
+// drift never accumulates. Other systems pass straight through.
+type Projected struct {
+	inner dynamo.Integrator
+}
+
+func NewProjected(inner dynamo.Integrator) *Projected {
+	return &Projected{inner: inner}
+}
+
+func (p *Projected) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
+	next := p.inner.Step(dyn, x, u, t, dt)
+	if c, ok := dynamo.Unwrap(dyn).(dynamo.Constrained); ok {
+		return c.Project(next)
+	}
+	return next
+}
@@ -43,6 +43,16 @@ var (
 	dc7 = -1.0 / 40.0
 )
 
+// Dormand-Prince dense output coefficients (Hairer, Norsett & Wanner)
+var (
+	d1 = -12715105075.0 / 11282082432.0
+	d3 = 87487479700.0 / 32700410799.0
+	d4 = -10690763975.0 / 1880347072.0
+	d5 = 701980252875.0 / 199316789632.0
+	d6 = -1453857185.0 / 822651844.0
+	d7 = 69997945.0 / 29380423.0
+)
+
 type RK45 struct {
 	safety   float64
 	minScale float64
@@ -63,6 +73,44 @@ func (r *RK45) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt f
 }
 
 func (r *RK45) StepAdaptive(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt, tol float64) (dynamo.State, float64, error) {
+	xNew, k := r.stages(dyn, x, u, t, dt)
+	dtNew, err := r.nextDt(x, k, dt, tol)
+	return xNew, dtNew, err
+}
+
+// StepDense is StepAdaptive plus the 4th-order Dormand-Prince continuous
+// extension of the step, which costs no extra derivative evaluations.
+func (r *RK45) StepDense(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt, tol float64) (dynamo.State, float64, dynamo.Interpolant, error) {
+	xNew, k := r.stages(dyn, x, u, t, dt)
+
+	n := len(x)
+	r1 := make([]float64, n)
+	r2 := make([]float64, n)
+	r3 := make([]float64, n)
+	r4 := make([]float64, n)
+	for i := 0; i < n; i++ {
+		r1[i] = xNew[i] - x[i]
+		r2[i] = dt*k[0][i] - r1[i]
+		r3[i] = r1[i] - dt*k[6][i] - r2[i]
+		r4[i] = dt * (d1*k[0][i] + d3*k[2][i] + d4*k[3][i] + d5*k[4][i] + d6*k[5][i] + d7*k[6][i])
+	}
+	x0 := x.Clone()
+	interp := func(theta float64) dynamo.State {
+		s := 1 - theta
+		out := make(dynamo.State, n)
+		for i := 0; i < n; i++ {
+			out[i] = x0[i] + theta*(r1[i]+s*(r2[i]+theta*(r3[i]+s*r4[i])))
+		}
+		return out
+	}
+
+	dtNew, err := r.nextDt(x, k, dt, tol)
+	return xNew, dtNew, interp, err
+}
+
+// stages evaluates one Dormand-Prince step and returns the 5th-order
+// solution with all seven stage derivatives; the last is f(xNew).
+func (r *RK45) stages(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) (dynamo.State, [7]dynamo.State) {
 	n := len(x)
 
 	k1 := dyn.Derive(x, u, t)
@@ -104,27 +152,28 @@ func (r *RK45) StepAdaptive(dyn dynamo.System, x dynamo.State, u dynamo.Control,
 
 	k7 := dyn.Derive(xNew, u, t+dt)
 
+	return xNew, [7]dynamo.State{k1, k2, k3, k4, k5, k6, k7}
+}
+
+// nextDt estimates the local error from the embedded 4th-order solution and
+// proposes the next step size, rejecting the step if the error exceeds tol.
+func (r *RK45) nextDt(x dynamo.State, k [7]dynamo.State, dt, tol float64) (float64, error) {
 	errMax := 0.0
-	for i := 0; i < n; i++ {
-		errEst := dt * (dc1*k1[i] + dc3*k3[i] + dc4*k4[i] + dc5*k5[i] + dc6*k6[i] + dc7*k7[i])
-		scale := math.Abs(x[i]) + math.Abs(dt*k1[i]) + 1e-10
+	for i := range x {
+		errEst := dt * (dc1*k[0][i] + dc3*k[2][i] + dc4*k[3][i] + dc5*k[4][i] + dc6*k[5][i] + dc7*k[6][i])
+		scale := math.Abs(x[i]) + math.Abs(dt*k[0][i]) + 1e-10
 		errMax = math.Max(errMax, math.Abs(errEst)/scale)
 	}
 
 	errRatio := errMax / tol
 
-	var dtNew float64
 	if errRatio > 1 {
 		scale := math.Max(r.minScale, r.safety*math.Pow(errRatio, -0.25))
-		dtNew = dt * scale
-	} else {
-		if errRatio > 0 {
-			scale := math.Min(r.maxScale, r.safety*math.Pow(errRatio, -0.2))
-			dtNew = dt * scale
-		} else {
-			dtNew = dt * r.maxScale
-		}
+		return dt * scale, dynamo.ErrStepRejected
 	}
-
-	return xNew, dtNew, nil
+	if errRatio > 0 {
+		scale := math.Min(r.maxScale, r.safety*math.Pow(errRatio, -0.2))
+		return dt * scale, nil
+	}
+	return dt * r.maxScale, nil
 }
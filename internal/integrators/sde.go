@@ -0,0 +1,132 @@
+package integrators
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// wiener draws the Brownian increments of the SDE integrators. It embeds
+// into each of them to provide dynamo.StochasticIntegrator's Seed.
+type wiener struct {
+	rng *rand.Rand
+}
+
+func (w *wiener) Seed(seed int64) {
+	w.rng = rand.New(rand.NewSource(seed))
+}
+
+// increments returns n independent N(0, dt) draws.
+func (w *wiener) increments(n int, dt float64) []float64 {
+	if w.rng == nil {
+		w.Seed(0)
+	}
+	sq := math.Sqrt(dt)
+	dW := make([]float64, n)
+	for i := range dW {
+		dW[i] = sq * w.rng.NormFloat64()
+	}
+	return dW
+}
+
+// EulerMaruyama is the strong order 1/2 Euler scheme for Itô SDEs. On
+// systems without a diffusion term it reduces to explicit Euler.
+type EulerMaruyama struct {
+	wiener
+}
+
+func NewEulerMaruyama() *EulerMaruyama {
+	return &EulerMaruyama{}
+}
+
+func (e *EulerMaruyama) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
+	f := dyn.Derive(x, u, t)
+	result := make(dynamo.State, len(x))
+	for i := range x {
+		result[i] = x[i] + dt*f[i]
+	}
+
+	g := dynamo.DiffusionOf(dyn, x, u, t)
+	if g == nil {
+		return result
+	}
+	dW := e.increments(len(x), dt)
+	for i := range x {
+		result[i] += g[i] * dW[i]
+	}
+	return result
+}
+
+// Milstein is the strong order 1 Milstein scheme for diagonal noise. The
+// derivative of the diffusion term is taken by finite differences.
+type Milstein struct {
+	wiener
+}
+
+func NewMilstein() *Milstein {
+	return &Milstein{}
+}
+
+func (m *Milstein) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
+	f := dyn.Derive(x, u, t)
+	result := make(dynamo.State, len(x))
+	for i := range x {
+		result[i] = x[i] + dt*f[i]
+	}
+
+	g := dynamo.DiffusionOf(dyn, x, u, t)
+	if g == nil {
+		return result
+	}
+	dW := m.increments(len(x), dt)
+
+	xp := x.Clone()
+	for i := range x {
+		dg := 0.0
+		if g[i] != 0 {
+			h := math.Sqrt(2.2e-16) * math.Max(math.Abs(x[i]), 1)
+			xp[i] = x[i] + h
+			dg = (dynamo.DiffusionOf(dyn, xp, u, t)[i] - g[i]) / h
+			xp[i] = x[i]
+		}
+		result[i] += g[i]*dW[i] + 0.5*g[i]*dg*(dW[i]*dW[i]-dt)
+	}
+	return result
+}
+
+// SRK is Platen's derivative-free stochastic Runge-Kutta scheme, strong
+// order 1 like Milstein but using one extra diffusion evaluation instead of
+// derivatives.
+type SRK struct {
+	wiener
+}
+
+func NewSRK() *SRK {
+	return &SRK{}
+}
+
+func (s *SRK) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
+	f := dyn.Derive(x, u, t)
+	result := make(dynamo.State, len(x))
+	for i := range x {
+		result[i] = x[i] + dt*f[i]
+	}
+
+	g := dynamo.DiffusionOf(dyn, x, u, t)
+	if g == nil {
+		return result
+	}
+	dW := s.increments(len(x), dt)
+
+	sq := math.Sqrt(dt)
+	support := make(dynamo.State, len(x))
+	for i := range x {
+		support[i] = result[i] + g[i]*sq
+	}
+	gs := dynamo.DiffusionOf(dyn, support, u, t)
+	for i := range x {
+		result[i] += g[i]*dW[i] + (gs[i]-g[i])*(dW[i]*dW[i]-dt)/(2*sq)
+	}
+	return result
+}
@@ -3,7 +3,6 @@ package integrators
 import "github.com/san-kum/dynsim/internal/dynamo"
 
 type Verlet struct {
-	prevAcc dynamo.State
 	scratch dynamo.State
 }
 
@@ -11,36 +10,30 @@ func NewVerlet() *Verlet {
 	return &Verlet{}
 }
 
-func (v *Verlet) ensureScratch(n int) {
-	if len(v.scratch) != n {
-		v.scratch = make(dynamo.State, n)
-		v.prevAcc = nil
-	}
-}
+// Symplectic implements dynamo.SymplecticIntegrator.
+func (v *Verlet) Symplectic() {}
 
 func (v *Verlet) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
 	n := len(x)
-	half := n / 2
-	v.ensureScratch(n)
+	pos, vel := mustSplit(dyn)
+	if len(v.scratch) != n {
+		v.scratch = make(dynamo.State, n)
+	}
 
-	result := make(dynamo.State, n)
+	result := x.Clone()
 	dx := dyn.Derive(x, u, t)
 	dt2 := dt * dt
 
-	for i := 0; i < half; i++ {
-		result[i] = x[i] + x[half+i]*dt + 0.5*dx[half+i]*dt2
-	}
-
-	for i := 0; i < half; i++ {
-		v.scratch[i] = result[i]
-		v.scratch[half+i] = x[half+i]
+	for i := range pos {
+		result[pos[i]] = x[pos[i]] + x[vel[i]]*dt + 0.5*dx[vel[i]]*dt2
 	}
 
+	copy(v.scratch, result)
 	dxNew := dyn.Derive(v.scratch, u, t+dt)
 
 	halfDt := 0.5 * dt
-	for i := 0; i < half; i++ {
-		result[half+i] = x[half+i] + (dx[half+i]+dxNew[half+i])*halfDt
+	for i := range vel {
+		result[vel[i]] = x[vel[i]] + (dx[vel[i]]+dxNew[vel[i]])*halfDt
 	}
 
 	return result
@@ -54,31 +47,46 @@ func NewLeapfrog() *Leapfrog {
 	return &Leapfrog{}
 }
 
+// mustSplit returns the model's position/velocity split. Simulator runs
+// check it up front, see dynamo.CheckIntegrator; stepping a model without
+// one is a programming error.
+func mustSplit(dyn dynamo.System) (pos, vel []int) {
+	pos, vel, err := dynamo.SplitOf(dyn)
+	if err != nil {
+		panic(err)
+	}
+	return pos, vel
+}
+
+// Symplectic implements dynamo.SymplecticIntegrator.
+func (l *Leapfrog) Symplectic() {}
+
 func (l *Leapfrog) Step(dyn dynamo.System, x dynamo.State, u dynamo.Control, t, dt float64) dynamo.State {
 	n := len(x)
-	half := n / 2
+	pos, vel := mustSplit(dyn)
 
 	if len(l.scratch) != n {
 		l.scratch = make(dynamo.State, n)
 	}
 
-	result := make(dynamo.State, n)
+	result := x.Clone()
 	dx := dyn.Derive(x, u, t)
 	halfDt := dt * 0.5
 
-	for i := 0; i < half; i++ {
-		l.scratch[half+i] = x[half+i] + dx[half+i]*halfDt
+	copy(l.scratch, x)
+	for i := range vel {
+		l.scratch[vel[i]] = x[vel[i]] + dx[vel[i]]*halfDt
 	}
 
-	for i := 0; i < half; i++ {
-		result[i] = x[i] + l.scratch[half+i]*dt
-		l.scratch[i] = result[i]
+	for i := range pos {
+		result[pos[i]] = x[pos[i]] + l.scratch[vel[i]]*dt
+		l.scratch[pos[i]] = result[pos[i]]
 	}
 
 	dxNew := dyn.Derive(l.scratch, u, t+dt)
 
-	for i := 0; i < half; i++ {
-		result[half+i] = l.scratch[half+i] + dxNew[half+i]*halfDt
+	for i := range vel {
+		result[vel[i]] = l.scratch[vel[i]] + dxNew[vel[i]]*halfDt
 	}
 
 	return result
@@ -0,0 +1,16 @@
+// Package lagrange builds models from their kinetic and potential energy.
+//
+// A [System] lists generalized coordinates q and gives T(q, q̇, t) and
+// V(q, t) as expressions (see package expr). New differentiates the
+// Lagrangian L = T - V symbolically and integrates the Euler-Lagrange
+// equations
+//
+//	∂²L/∂q̇² q̈ = ∂L/∂q - ∂²L/∂q̇∂q q̇ - ∂²L/∂q̇∂t + Q
+//
+// where Q are the optional generalized forces, which may read the controls
+// and so model damping, drive torques and the like. The energy function
+// h = q̇·∂L/∂q̇ - L is derived the same way, so every [Model] is a
+// dynamo.Hamiltonian and works with the energy drift metric. The state is
+// [q..., q̇...]; systems whose accelerations do not read q̇ declare it as
+// their split and so also work with the symplectic integrators.
+package lagrange
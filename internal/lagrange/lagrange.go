@@ -0,0 +1,183 @@
+package lagrange
+
+import (
+	"fmt"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/expr"
+)
+
+// Coord is a generalized coordinate.
+type Coord struct {
+	Name string
+	// Velocity names q̇ in the expressions; it defaults to Name + "_dot".
+	Velocity string
+	Unit     string
+}
+
+// System describes a mechanical system by its energies. Expressions may
+// read the coordinates, velocities, controls and parameters by name, and
+// the time t.
+type System struct {
+	Coords   []Coord
+	Controls []dynamo.Variable
+	Params   []dynamo.Param
+
+	Kinetic   string
+	Potential string
+	// Forces maps coordinate names to generalized forces outside the
+	// potential. Coordinates without an entry are unforced.
+	Forces map[string]string
+}
+
+func (c Coord) velocity() string {
+	if c.Velocity != "" {
+		return c.Velocity
+	}
+	return c.Name + "_dot"
+}
+
+// New derives the equations of motion of sys.
+func New(sys System) (*Model, error) {
+	if len(sys.Coords) == 0 {
+		return nil, fmt.Errorf("lagrange: no coordinates")
+	}
+	if sys.Kinetic == "" {
+		return nil, fmt.Errorf("lagrange: no kinetic energy")
+	}
+
+	// Expressions see [q..., q̇..., controls..., params..., t].
+	n := len(sys.Coords)
+	names := make([]string, 0, 2*n+len(sys.Controls)+len(sys.Params)+1)
+	for _, c := range sys.Coords {
+		names = append(names, c.Name)
+	}
+	for _, c := range sys.Coords {
+		names = append(names, c.velocity())
+	}
+	for _, u := range sys.Controls {
+		names = append(names, u.Name)
+	}
+	for _, p := range sys.Params {
+		names = append(names, p.Name)
+	}
+	names = append(names, "t")
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			return nil, fmt.Errorf("lagrange: duplicate name: %s", name)
+		}
+		seen[name] = true
+	}
+	for name := range sys.Forces {
+		if indexOf(names[:n], name) < 0 {
+			return nil, fmt.Errorf("lagrange: force on unknown coordinate: %s", name)
+		}
+	}
+
+	kinetic, err := expr.Parse(sys.Kinetic)
+	if err != nil {
+		return nil, fmt.Errorf("lagrange: kinetic energy: %w", err)
+	}
+	potential := expr.Num(0)
+	if sys.Potential != "" {
+		if potential, err = expr.Parse(sys.Potential); err != nil {
+			return nil, fmt.Errorf("lagrange: potential energy: %w", err)
+		}
+	}
+	lagrangian := expr.Sub(kinetic, potential)
+
+	c := &compiler{names: names}
+	m := &Model{
+		sys:    sys,
+		dLdq:   make([]expr.Func, n),
+		dLdvt:  make([]expr.Func, n),
+		forces: make([]expr.Func, n),
+		mass:   make([][]expr.Func, n),
+		mixed:  make([][]expr.Func, n),
+		params: make([]float64, len(sys.Params)),
+	}
+	energy := expr.Neg(lagrangian)
+	// terms collects every expression the accelerations are solved from,
+	// to tell whether they read the velocities.
+	var terms []*expr.Node
+	for i, ci := range sys.Coords {
+		qi, vi := names[i], names[n+i]
+		p := lagrangian.Diff(vi)
+		energy = expr.Add(energy, expr.Mul(expr.Var(vi), p))
+		rhs := expr.Sub(lagrangian.Diff(qi), p.Diff("t"))
+		m.dLdq[i] = c.compile(lagrangian.Diff(qi))
+		m.dLdvt[i] = c.compile(p.Diff("t"))
+		m.mass[i] = make([]expr.Func, n)
+		m.mixed[i] = make([]expr.Func, n)
+		for j := range sys.Coords {
+			mass, mixed := p.Diff(names[n+j]), p.Diff(names[j])
+			m.mass[i][j] = c.compile(mass)
+			m.mixed[i][j] = c.compile(mixed)
+			terms = append(terms, mass)
+			rhs = expr.Sub(rhs, expr.Mul(mixed, expr.Var(names[n+j])))
+		}
+		force := expr.Num(0)
+		if src, ok := sys.Forces[ci.Name]; ok {
+			if force, err = expr.Parse(src); err != nil {
+				return nil, fmt.Errorf("lagrange: force on %s: %w", ci.Name, err)
+			}
+		}
+		m.forces[i] = c.compile(force)
+		terms = append(terms, expr.Add(rhs, force))
+	}
+	m.separable = !dependsOnAny(terms, names[n:2*n])
+	m.energy = c.compile(energy)
+	if c.err != nil {
+		return nil, fmt.Errorf("lagrange: %w", c.err)
+	}
+	for i, p := range sys.Params {
+		m.params[i] = p.Default
+	}
+	return m, nil
+}
+
+// MustNew is like New but panics on error. It is meant for models whose
+// energies are fixed in the source.
+func MustNew(sys System) *Model {
+	m, err := New(sys)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// compiler compiles expressions against one variable layout, keeping the
+// first error.
+type compiler struct {
+	names []string
+	err   error
+}
+
+func (c *compiler) compile(n *expr.Node) expr.Func {
+	f, err := n.Compile(c.names)
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+	return f
+}
+
+func dependsOnAny(nodes []*expr.Node, names []string) bool {
+	for _, n := range nodes {
+		for _, name := range names {
+			if n.Depends(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,120 @@
+package lagrange
+
+import (
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/expr"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// Model is a compiled System. The derived expressions are shared between
+// clones; parameter values are not.
+type Model struct {
+	sys    System
+	dLdq   []expr.Func   // ∂L/∂q
+	dLdvt  []expr.Func   // ∂²L/∂q̇∂t
+	mass   [][]expr.Func // ∂²L/∂q̇∂q̇
+	mixed  [][]expr.Func // ∂²L/∂q̇∂q
+	forces []expr.Func
+	energy expr.Func
+	// separable is set when the accelerations do not read the velocities.
+	separable bool
+	params    []float64
+}
+
+// Clone returns an independent instance with the same parameter values.
+func (m *Model) Clone() *Model {
+	c := *m
+	c.params = append([]float64(nil), m.params...)
+	return &c
+}
+
+func (m *Model) StateDim() int   { return 2 * len(m.sys.Coords) }
+func (m *Model) ControlDim() int { return len(m.sys.Controls) }
+
+func (m *Model) StateVars() []dynamo.Variable {
+	n := len(m.sys.Coords)
+	vars := make([]dynamo.Variable, 2*n)
+	for i, c := range m.sys.Coords {
+		vars[i] = dynamo.Variable{Name: c.Name, Unit: c.Unit}
+		vars[n+i] = dynamo.Variable{Name: c.velocity()}
+		if c.Unit != "" {
+			vars[n+i].Unit = c.Unit + "/s"
+		}
+	}
+	return vars
+}
+
+func (m *Model) ControlVars() []dynamo.Variable { return m.sys.Controls }
+
+// Split implements dynamo.Separable: the coordinates come first, then their
+// velocities. Systems whose accelerations read the velocities, through a
+// configuration-dependent mass matrix or velocity-dependent forces, report
+// no split, since the symplectic integrators lose their order on them.
+func (m *Model) Split() (pos, vel []int) {
+	if !m.separable {
+		return nil, nil
+	}
+	return dynamo.BlockSplit(len(m.sys.Coords))
+}
+
+// env lays out the expression variables. Missing controls read as zero.
+func (m *Model) env(x dynamo.State, u dynamo.Control, t float64) []float64 {
+	n, nu := len(m.sys.Coords), len(m.sys.Controls)
+	vars := make([]float64, 2*n+nu+len(m.params)+1)
+	copy(vars[:2*n], x)
+	copy(vars[2*n:2*n+nu], u)
+	copy(vars[2*n+nu:], m.params)
+	vars[len(vars)-1] = t
+	return vars
+}
+
+// Derive solves the Euler-Lagrange equations for q̈. Where the mass matrix
+// is singular the accelerations are left at zero.
+func (m *Model) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.State {
+	n := len(m.sys.Coords)
+	vars := m.env(x, u, t)
+	mass := linalg.New(n, n)
+	rhs := make([]float64, n)
+	for i := 0; i < n; i++ {
+		rhs[i] = m.dLdq[i](vars) - m.dLdvt[i](vars) + m.forces[i](vars)
+		for j := 0; j < n; j++ {
+			mass[i][j] = m.mass[i][j](vars)
+			rhs[i] -= m.mixed[i][j](vars) * vars[n+j]
+		}
+	}
+	out := make(dynamo.State, 2*n)
+	copy(out, vars[n:2*n])
+	if lu, err := linalg.Factor(mass); err == nil {
+		copy(out[n:], lu.Solve(rhs))
+	}
+	return out
+}
+
+// Energy implements dynamo.Hamiltonian with h = q̇·∂L/∂q̇ - L, which is
+// T + V when T is quadratic in the velocities.
+func (m *Model) Energy(x dynamo.State) float64 {
+	return m.energy(m.env(x, nil, 0))
+}
+
+// Params implements dynamo.Parameterized.
+func (m *Model) Params() []dynamo.Param { return m.sys.Params }
+
+func (m *Model) GetParams() map[string]float64 {
+	values := make(map[string]float64, len(m.params))
+	for i, p := range m.sys.Params {
+		values[p.Name] = m.params[i]
+	}
+	return values
+}
+
+func (m *Model) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(m.sys.Params, name, value); err != nil {
+		return err
+	}
+	for i, p := range m.sys.Params {
+		if p.Name == name {
+			m.params[i] = value
+		}
+	}
+	return nil
+}
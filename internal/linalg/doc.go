@@ -0,0 +1,6 @@
+// Package linalg provides the small dense linear algebra needed by the
+// implicit integrators, constrained mechanics, controllers, estimators and
+// linearization: matrices as row slices, LU and Cholesky factorization,
+// eigenvalues of general real matrices, numerical rank, the matrix
+// exponential and continuous and discrete algebraic Riccati solvers.
+package linalg
@@ -0,0 +1,268 @@
+package linalg
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrNoConvergence is returned when the QR iteration fails to converge.
+var ErrNoConvergence = errors.New("linalg: eigenvalue iteration did not converge")
+
+// Eigenvalues returns the eigenvalues of the square matrix a, sorted by
+// decreasing real part. It reduces a to Hessenberg form and runs the
+// Francis double-shift QR iteration; a is not modified.
+func Eigenvalues(a Matrix) ([]complex128, error) {
+	h := a.Clone()
+	hessenberg(h)
+	eig, err := hqr(h)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(eig, func(i, j int) bool {
+		if real(eig[i]) != real(eig[j]) {
+			return real(eig[i]) > real(eig[j])
+		}
+		return imag(eig[i]) > imag(eig[j])
+	})
+	return eig, nil
+}
+
+// hessenberg reduces a in place to upper Hessenberg form by stabilized
+// elementary similarity transforms.
+func hessenberg(a Matrix) {
+	n := a.Rows()
+	for m := 1; m < n-1; m++ {
+		x, p := 0.0, m
+		for j := m; j < n; j++ {
+			if math.Abs(a[j][m-1]) > math.Abs(x) {
+				x, p = a[j][m-1], j
+			}
+		}
+		if p != m {
+			for j := m - 1; j < n; j++ {
+				a[p][j], a[m][j] = a[m][j], a[p][j]
+			}
+			for j := 0; j < n; j++ {
+				a[j][p], a[j][m] = a[j][m], a[j][p]
+			}
+		}
+		if x == 0 {
+			continue
+		}
+		for i := m + 1; i < n; i++ {
+			y := a[i][m-1]
+			if y == 0 {
+				continue
+			}
+			y /= x
+			a[i][m-1] = 0
+			for j := m; j < n; j++ {
+				a[i][j] -= y * a[m][j]
+			}
+			for j := 0; j < n; j++ {
+				a[j][m] += y * a[j][i]
+			}
+		}
+	}
+}
+
+// hqr finds the eigenvalues of an upper Hessenberg matrix, destroying it.
+func hqr(a Matrix) ([]complex128, error) {
+	n := a.Rows()
+	eig := make([]complex128, n)
+	const eps = 2.220446049250313e-16
+
+	anorm := 0.0
+	for i := 0; i < n; i++ {
+		for j := max(i-1, 0); j < n; j++ {
+			anorm += math.Abs(a[i][j])
+		}
+	}
+
+	var p, q, r, s, t, w, x, y, z float64
+	nn := n - 1
+	for nn >= 0 {
+		its := 0
+		var l int
+		for {
+			// Look for a single small subdiagonal element to split at.
+			for l = nn; l > 0; l-- {
+				s = math.Abs(a[l-1][l-1]) + math.Abs(a[l][l])
+				if s == 0 {
+					s = anorm
+				}
+				if math.Abs(a[l][l-1]) <= eps*s {
+					a[l][l-1] = 0
+					break
+				}
+			}
+			x = a[nn][nn]
+			if l == nn {
+				eig[nn] = complex(x+t, 0)
+				nn--
+				break
+			}
+			y = a[nn-1][nn-1]
+			w = a[nn][nn-1] * a[nn-1][nn]
+			if l == nn-1 {
+				p = 0.5 * (y - x)
+				q = p*p + w
+				z = math.Sqrt(math.Abs(q))
+				x += t
+				if q >= 0 {
+					z = p + math.Copysign(z, p)
+					eig[nn-1], eig[nn] = complex(x+z, 0), complex(x+z, 0)
+					if z != 0 {
+						eig[nn] = complex(x-w/z, 0)
+					}
+				} else {
+					eig[nn-1] = complex(x+p, z)
+					eig[nn] = complex(x+p, -z)
+				}
+				nn -= 2
+				break
+			}
+
+			if its == 60 {
+				return nil, ErrNoConvergence
+			}
+			if its == 10 || its == 20 {
+				// Exceptional shift.
+				t += x
+				for i := 0; i <= nn; i++ {
+					a[i][i] -= x
+				}
+				s = math.Abs(a[nn][nn-1]) + math.Abs(a[nn-1][nn-2])
+				x = 0.75 * s
+				y = x
+				w = -0.4375 * s * s
+			}
+			its++
+
+			// Look for two consecutive small subdiagonal elements.
+			var m int
+			for m = nn - 2; m >= l; m-- {
+				z = a[m][m]
+				r = x - z
+				s = y - z
+				p = (r*s-w)/a[m+1][m] + a[m][m+1]
+				q = a[m+1][m+1] - z - r - s
+				r = a[m+2][m+1]
+				s = math.Abs(p) + math.Abs(q) + math.Abs(r)
+				p /= s
+				q /= s
+				r /= s
+				if m == l {
+					break
+				}
+				u := math.Abs(a[m][m-1]) * (math.Abs(q) + math.Abs(r))
+				v := math.Abs(p) * (math.Abs(a[m-1][m-1]) + math.Abs(z) + math.Abs(a[m+1][m+1]))
+				if u <= eps*v {
+					break
+				}
+			}
+			for i := m; i < nn-1; i++ {
+				a[i+2][i] = 0
+				if i != m {
+					a[i+2][i-1] = 0
+				}
+			}
+
+			// Double QR step on rows l..nn and columns m..nn.
+			for k := m; k < nn; k++ {
+				if k != m {
+					p = a[k][k-1]
+					q = a[k+1][k-1]
+					r = 0
+					if k+1 != nn {
+						r = a[k+2][k-1]
+					}
+					if x = math.Abs(p) + math.Abs(q) + math.Abs(r); x != 0 {
+						p /= x
+						q /= x
+						r /= x
+					}
+				}
+				s = math.Copysign(math.Sqrt(p*p+q*q+r*r), p)
+				if s == 0 {
+					continue
+				}
+				if k == m {
+					if l != m {
+						a[k][k-1] = -a[k][k-1]
+					}
+				} else {
+					a[k][k-1] = -s * x
+				}
+				p += s
+				x = p / s
+				y = q / s
+				z = r / s
+				q /= p
+				r /= p
+				for j := k; j <= nn; j++ {
+					p = a[k][j] + q*a[k+1][j]
+					if k+1 != nn {
+						p += r * a[k+2][j]
+						a[k+2][j] -= p * z
+					}
+					a[k+1][j] -= p * y
+					a[k][j] -= p * x
+				}
+				mmin := min(nn, k+3)
+				for i := l; i <= mmin; i++ {
+					p = x*a[i][k] + y*a[i][k+1]
+					if k+1 != nn {
+						p += z * a[i][k+2]
+						a[i][k+2] -= p * r
+					}
+					a[i][k+1] -= p * q
+					a[i][k] -= p
+				}
+			}
+		}
+	}
+	return eig, nil
+}
+
+// Rank returns the numerical rank of a: the number of pivots left by
+// Gaussian elimination with full pivoting that exceed 1e-10·max|a|.
+func Rank(a Matrix) int {
+	m := a.Clone()
+	rows, cols := m.Rows(), m.Cols()
+	scale := 0.0
+	for _, row := range m {
+		for _, v := range row {
+			scale = math.Max(scale, math.Abs(v))
+		}
+	}
+	tol := 1e-10 * scale
+
+	rank := 0
+	colUsed := make([]bool, cols)
+	for r := 0; r < rows && rank < cols; r++ {
+		// Full pivot over the remaining rows and unused columns.
+		pr, pc, best := -1, -1, tol
+		for i := r; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				if !colUsed[j] && math.Abs(m[i][j]) > best {
+					pr, pc, best = i, j, math.Abs(m[i][j])
+				}
+			}
+		}
+		if pr < 0 {
+			break
+		}
+		m[r], m[pr] = m[pr], m[r]
+		colUsed[pc] = true
+		rank++
+		for i := r + 1; i < rows; i++ {
+			f := m[i][pc] / m[r][pc]
+			for j := 0; j < cols; j++ {
+				m[i][j] -= f * m[r][j]
+			}
+		}
+	}
+	return rank
+}
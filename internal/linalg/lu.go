@@ -0,0 +1,72 @@
+package linalg
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrSingular is returned when factoring a matrix with no usable pivot.
+var ErrSingular = errors.New("linalg: matrix is singular")
+
+// LU is the factorization P*A = L*U of a square matrix, with L and U packed
+// into one matrix.
+type LU struct {
+	lu  Matrix
+	piv []int
+}
+
+// Factor computes the LU factorization of a with partial pivoting. a is not
+// modified.
+func Factor(a Matrix) (*LU, error) {
+	n := a.Rows()
+	lu := a.Clone()
+	piv := make([]int, n)
+	for i := range piv {
+		piv[i] = i
+	}
+
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i][k]) > math.Abs(lu[p][k]) {
+				p = i
+			}
+		}
+		if lu[p][k] == 0 {
+			return nil, ErrSingular
+		}
+		lu[k], lu[p] = lu[p], lu[k]
+		piv[k], piv[p] = piv[p], piv[k]
+
+		for i := k + 1; i < n; i++ {
+			lu[i][k] /= lu[k][k]
+			f := lu[i][k]
+			for j := k + 1; j < n; j++ {
+				lu[i][j] -= f * lu[k][j]
+			}
+		}
+	}
+
+	return &LU{lu: lu, piv: piv}, nil
+}
+
+// Solve returns x with A*x = b.
+func (f *LU) Solve(b []float64) []float64 {
+	n := len(f.piv)
+	x := make([]float64, n)
+	for i := 0; i < n; i++ {
+		x[i] = b[f.piv[i]]
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= f.lu[i][j] * x[j]
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= f.lu[i][j] * x[j]
+		}
+		x[i] /= f.lu[i][i]
+	}
+	return x
+}
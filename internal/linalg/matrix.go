@@ -0,0 +1,80 @@
+package linalg
+
+// Matrix is a dense matrix stored as a slice of rows.
+type Matrix [][]float64
+
+// New returns a zero rows x cols matrix.
+func New(rows, cols int) Matrix {
+	m := make(Matrix, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+	}
+	return m
+}
+
+// Identity returns the n x n identity matrix.
+func Identity(n int) Matrix {
+	m := New(n, n)
+	for i := 0; i < n; i++ {
+		m[i][i] = 1
+	}
+	return m
+}
+
+func (m Matrix) Rows() int { return len(m) }
+
+func (m Matrix) Cols() int {
+	if len(m) == 0 {
+		return 0
+	}
+	return len(m[0])
+}
+
+// Clone returns a deep copy of m.
+func (m Matrix) Clone() Matrix {
+	c := make(Matrix, len(m))
+	for i, row := range m {
+		c[i] = append([]float64(nil), row...)
+	}
+	return c
+}
+
+// MulVec returns m*v.
+func (m Matrix) MulVec(v []float64) []float64 {
+	out := make([]float64, len(m))
+	for i, row := range m {
+		sum := 0.0
+		for j, a := range row {
+			sum += a * v[j]
+		}
+		out[i] = sum
+	}
+	return out
+}
+
+// T returns the transpose of m.
+func (m Matrix) T() Matrix {
+	t := New(m.Cols(), m.Rows())
+	for i, row := range m {
+		for j, a := range row {
+			t[j][i] = a
+		}
+	}
+	return t
+}
+
+// Mul returns m*b.
+func (m Matrix) Mul(b Matrix) Matrix {
+	out := New(m.Rows(), b.Cols())
+	for i, row := range m {
+		for k, a := range row {
+			if a == 0 {
+				continue
+			}
+			for j, c := range b[k] {
+				out[i][j] += a * c
+			}
+		}
+	}
+	return out
+}
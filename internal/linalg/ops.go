@@ -0,0 +1,132 @@
+package linalg
+
+import "math"
+
+// Add returns m + b.
+func (m Matrix) Add(b Matrix) Matrix {
+	out := m.Clone()
+	for i, row := range b {
+		for j, v := range row {
+			out[i][j] += v
+		}
+	}
+	return out
+}
+
+// Sub returns m - b.
+func (m Matrix) Sub(b Matrix) Matrix {
+	out := m.Clone()
+	for i, row := range b {
+		for j, v := range row {
+			out[i][j] -= v
+		}
+	}
+	return out
+}
+
+// Scale returns c·m.
+func (m Matrix) Scale(c float64) Matrix {
+	out := m.Clone()
+	for _, row := range out {
+		for j := range row {
+			row[j] *= c
+		}
+	}
+	return out
+}
+
+// Norm1 returns the maximum absolute column sum of m.
+func (m Matrix) Norm1() float64 {
+	sums := make([]float64, m.Cols())
+	for _, row := range m {
+		for j, v := range row {
+			sums[j] += math.Abs(v)
+		}
+	}
+	norm := 0.0
+	for _, s := range sums {
+		norm = math.Max(norm, s)
+	}
+	return norm
+}
+
+// Inverse returns a⁻¹.
+func Inverse(a Matrix) (Matrix, error) {
+	lu, err := Factor(a)
+	if err != nil {
+		return nil, err
+	}
+	return lu.Inverse(), nil
+}
+
+// Inverse returns A⁻¹ from the factorization.
+func (f *LU) Inverse() Matrix {
+	n := len(f.piv)
+	inv := New(n, n)
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		e[j] = 1
+		col := f.Solve(e)
+		e[j] = 0
+		for i := range col {
+			inv[i][j] = col[i]
+		}
+	}
+	return inv
+}
+
+// LogAbsDet returns log|det A|.
+func (f *LU) LogAbsDet() float64 {
+	s := 0.0
+	for i := range f.piv {
+		s += math.Log(math.Abs(f.lu[i][i]))
+	}
+	return s
+}
+
+// Expm returns the matrix exponential e^a, by scaling and squaring with a
+// truncated Taylor series.
+func Expm(a Matrix) Matrix {
+	n := a.Rows()
+	squarings := 0
+	if norm := a.Norm1(); norm > 0.5 {
+		squarings = int(math.Ceil(math.Log2(norm / 0.5)))
+	}
+	x := a.Scale(math.Ldexp(1, -squarings))
+
+	out := Identity(n)
+	term := Identity(n)
+	for k := 1; k <= 18; k++ {
+		term = term.Mul(x).Scale(1 / float64(k))
+		out = out.Add(term)
+		if term.Norm1() < 1e-17*out.Norm1() {
+			break
+		}
+	}
+	for i := 0; i < squarings; i++ {
+		out = out.Mul(out)
+	}
+	return out
+}
+
+// ZOH discretizes x' = Ax + Bu for a zero-order hold on u over steps of
+// length dt, returning Ad = e^(A dt) and Bd = ∫₀^dt e^(As) ds B.
+func ZOH(a, b Matrix, dt float64) (ad, bd Matrix) {
+	n, m := a.Rows(), b.Cols()
+	aug := New(n+m, n+m)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			aug[i][j] = a[i][j] * dt
+		}
+		for j := 0; j < m; j++ {
+			aug[i][n+j] = b[i][j] * dt
+		}
+	}
+	e := Expm(aug)
+	ad, bd = New(n, n), New(n, m)
+	for i := 0; i < n; i++ {
+		copy(ad[i], e[i][:n])
+		copy(bd[i], e[i][n:])
+	}
+	return ad, bd
+}
@@ -0,0 +1,117 @@
+package linalg
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrRiccati is returned when a Riccati equation has no stabilizing
+// solution, typically because (A, B) is not stabilizable or (A, Q) has
+// unobservable modes on the stability boundary.
+var ErrRiccati = errors.New("linalg: no stabilizing riccati solution")
+
+const riccatiMaxIter = 100
+
+// SolveCARE solves the continuous-time algebraic Riccati equation
+//
+//	AᵀP + PA - PBR⁻¹BᵀP + Q = 0
+//
+// for the stabilizing P, using the matrix sign function of the Hamiltonian
+// matrix [A -BR⁻¹Bᵀ; -Q -Aᵀ].
+func SolveCARE(a, b, q, r Matrix) (Matrix, error) {
+	n := a.Rows()
+	rinv, err := Inverse(r)
+	if err != nil {
+		return nil, err
+	}
+	g := b.Mul(rinv).Mul(b.T())
+
+	z := New(2*n, 2*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			z[i][j] = a[i][j]
+			z[i][n+j] = -g[i][j]
+			z[n+i][j] = -q[i][j]
+			z[n+i][n+j] = -a[j][i]
+		}
+	}
+
+	converged := false
+	for iter := 0; iter < riccatiMaxIter && !converged; iter++ {
+		lu, err := Factor(z)
+		if err != nil {
+			return nil, ErrRiccati
+		}
+		// Determinant scaling speeds up the early iterations.
+		c := math.Exp(-lu.LogAbsDet() / float64(2*n))
+		next := z.Scale(0.5 * c).Add(lu.Inverse().Scale(0.5 / c))
+		converged = next.Sub(z).Norm1() <= 1e-12*next.Norm1()
+		z = next
+	}
+	if !converged {
+		return nil, ErrRiccati
+	}
+
+	// P solves [W12; W22 + I] P = -[W11 + I; W21] in the least-squares sense.
+	lhs, rhs := New(2*n, n), New(2*n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			lhs[i][j] = z[i][n+j]
+			lhs[n+i][j] = z[n+i][n+j]
+			rhs[i][j] = -z[i][j]
+			rhs[n+i][j] = -z[n+i][j]
+		}
+		lhs[n+i][i]++
+		rhs[i][i]--
+	}
+	lt := lhs.T()
+	lu, err := Factor(lt.Mul(lhs))
+	if err != nil {
+		return nil, ErrRiccati
+	}
+	p := lu.Inverse().Mul(lt.Mul(rhs))
+	return symmetrize(p), nil
+}
+
+// SolveDARE solves the discrete-time algebraic Riccati equation
+//
+//	AᵀPA - P - AᵀPB(R + BᵀPB)⁻¹BᵀPA + Q = 0
+//
+// for the stabilizing P, using the structure-preserving doubling algorithm.
+func SolveDARE(a, b, q, r Matrix) (Matrix, error) {
+	n := a.Rows()
+	rinv, err := Inverse(r)
+	if err != nil {
+		return nil, err
+	}
+	ak := a.Clone()
+	gk := b.Mul(rinv).Mul(b.T())
+	hk := q.Clone()
+	for iter := 0; iter < riccatiMaxIter; iter++ {
+		w, err := Inverse(Identity(n).Add(gk.Mul(hk)))
+		if err != nil {
+			return nil, ErrRiccati
+		}
+		wa := w.Mul(ak)
+		hNext := hk.Add(ak.T().Mul(hk).Mul(wa))
+		gk = gk.Add(ak.Mul(w).Mul(gk).Mul(ak.T()))
+		ak = ak.Mul(wa)
+		done := hNext.Sub(hk).Norm1() <= 1e-12*hNext.Norm1()
+		hk = hNext
+		if done {
+			return symmetrize(hk), nil
+		}
+	}
+	return nil, ErrRiccati
+}
+
+func symmetrize(p Matrix) Matrix {
+	n := p.Rows()
+	out := New(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			out[i][j] = 0.5 * (p[i][j] + p[j][i])
+		}
+	}
+	return out
+}
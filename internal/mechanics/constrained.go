@@ -0,0 +1,181 @@
+package mechanics
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+)
+
+// Mechanism is a mechanical system in n generalized coordinates subject to
+// m holonomic constraints.
+type Mechanism interface {
+	Coords() int
+	ControlDim() int
+	Mass(q []float64) linalg.Matrix
+	Forces(q, v []float64, u dynamo.Control, t float64) []float64
+	Constraints(q []float64) []float64
+	ConstraintJacobian(q []float64) linalg.Matrix
+}
+
+// Constrained adapts a Mechanism to dynamo.System with state [q..., v...].
+// Models usually embed it and implement Mechanism themselves.
+type Constrained struct {
+	Mechanism
+
+	// Baumgarte gains; zero disables stabilization.
+	Alpha, Beta float64
+}
+
+// Default Baumgarte gains, critically damping drift on a ~0.2 s timescale.
+const (
+	DefaultAlpha = 5.0
+	DefaultBeta  = 5.0
+)
+
+func NewConstrained(m Mechanism) *Constrained {
+	return &Constrained{Mechanism: m, Alpha: DefaultAlpha, Beta: DefaultBeta}
+}
+
+func (c *Constrained) StateDim() int { return 2 * c.Coords() }
+
+// Split implements dynamo.Separable: the coordinates, then their velocities.
+func (c *Constrained) Split() (pos, vel []int) { return dynamo.BlockSplit(c.Coords()) }
+
+// Derive solves the constrained equations of motion for the accelerations.
+func (c *Constrained) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.State {
+	n := c.Coords()
+	q, v := x[:n], x[n:2*n]
+
+	mass := c.Mass(q)
+	jac := c.ConstraintJacobian(q)
+	phi := c.Constraints(q)
+	m := len(phi)
+
+	kkt := linalg.New(n+m, n+m)
+	rhs := make([]float64, n+m)
+	copy(rhs, c.Forces(q, v, u, t))
+	for i := 0; i < n; i++ {
+		copy(kkt[i], mass[i])
+	}
+	jv := jac.MulVec(v)
+	jdotv := c.jacobianRate(q, v)
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			kkt[n+i][j] = jac[i][j]
+			kkt[j][n+i] = jac[i][j]
+		}
+		rhs[n+i] = -jdotv[i] - 2*c.Alpha*jv[i] - c.Beta*c.Beta*phi[i]
+	}
+
+	sol, err := solveRegularized(kkt, rhs, n)
+	out := make(dynamo.State, 2*n)
+	copy(out, v)
+	if err == nil {
+		copy(out[n:], sol[:n])
+	}
+	return out
+}
+
+// jacobianRate returns J̇v, the derivative of J(q) along v applied to v,
+// by central differences.
+func (c *Constrained) jacobianRate(q, v []float64) []float64 {
+	speed := 0.0
+	for _, vi := range v {
+		speed = math.Max(speed, math.Abs(vi))
+	}
+	if speed == 0 {
+		return make([]float64, len(c.Constraints(q)))
+	}
+	h := 1e-6 / math.Max(1, speed)
+	qp := make([]float64, len(q))
+	qm := make([]float64, len(q))
+	for i := range q {
+		qp[i] = q[i] + h*v[i]
+		qm[i] = q[i] - h*v[i]
+	}
+	jp := c.ConstraintJacobian(qp).MulVec(v)
+	jm := c.ConstraintJacobian(qm).MulVec(v)
+	for i := range jp {
+		jp[i] = (jp[i] - jm[i]) / (2 * h)
+	}
+	return jp
+}
+
+// solveRegularized solves the saddle-point system, retrying with a small
+// negative diagonal in the constraint block when redundant constraints make
+// it singular, as at a linkage's dead-centre positions.
+func solveRegularized(a linalg.Matrix, b []float64, n int) ([]float64, error) {
+	lu, err := linalg.Factor(a)
+	if err == nil {
+		return lu.Solve(b), nil
+	}
+	reg := a.Clone()
+	for i := n; i < reg.Rows(); i++ {
+		reg[i][i] -= 1e-10
+	}
+	lu, err = linalg.Factor(reg)
+	if err != nil {
+		return nil, err
+	}
+	return lu.Solve(b), nil
+}
+
+// Residual implements dynamo.Constrained: Φ(q) followed by J(q)v, the
+// position and velocity level violations.
+func (c *Constrained) Residual(x dynamo.State) dynamo.State {
+	n := c.Coords()
+	q, v := x[:n], x[n:2*n]
+	return append(dynamo.State(c.Constraints(q)), c.ConstraintJacobian(q).MulVec(v)...)
+}
+
+// maxProjectIter bounds the Gauss-Newton iterations of Project.
+const maxProjectIter = 10
+
+// Project implements dynamo.Constrained. Positions are moved onto Φ = 0 by
+// Gauss-Newton steps along the constraint normals; velocities then lose
+// their component normal to the manifold.
+func (c *Constrained) Project(x dynamo.State) dynamo.State {
+	n := c.Coords()
+	out := x.Clone()
+	q, v := out[:n], out[n:2*n]
+
+	for iter := 0; iter < maxProjectIter; iter++ {
+		phi := c.Constraints(q)
+		if maxAbs(phi) < 1e-12 {
+			break
+		}
+		dq, ok := minimumNorm(c.ConstraintJacobian(q), phi)
+		if !ok {
+			break
+		}
+		for i := range q {
+			q[i] -= dq[i]
+		}
+	}
+
+	if dv, ok := minimumNorm(c.ConstraintJacobian(q), c.ConstraintJacobian(q).MulVec(v)); ok {
+		for i := range v {
+			v[i] -= dv[i]
+		}
+	}
+	return out
+}
+
+// minimumNorm returns Jᵀ(JJᵀ)⁻¹r, the smallest d with J d = r.
+func minimumNorm(jac linalg.Matrix, r []float64) ([]float64, bool) {
+	jt := jac.T()
+	lu, err := linalg.Factor(jac.Mul(jt))
+	if err != nil {
+		return nil, false
+	}
+	return jt.MulVec(lu.Solve(r)), true
+}
+
+func maxAbs(v []float64) float64 {
+	m := 0.0
+	for _, a := range v {
+		m = math.Max(m, math.Abs(a))
+	}
+	return m
+}
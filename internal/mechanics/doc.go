@@ -0,0 +1,17 @@
+// Package mechanics turns mechanical systems with holonomic constraints
+// into ordinary [dynamo.System] models.
+//
+// A [Mechanism] describes its generalized coordinates q, mass matrix M(q),
+// applied forces and constraints Φ(q) = 0. [Constrained] integrates it with
+// the Lagrange multiplier formulation
+//
+//	M(q) q̈ + J(q)ᵀ λ = F(q, q̇, u, t)
+//	J(q) q̈ = -J̇ q̇ - 2α J q̇ - β² Φ(q)
+//
+// where J = ∂Φ/∂q. The α and β terms are Baumgarte stabilization, which
+// pulls drifting states back towards the constraint manifold; integrators
+// wrapped with integrators.NewProjected remove the drift after every step
+// instead.
+//
+// The state is [q..., q̇...], so any integrator can advance it.
+package mechanics
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// ConstraintDrift reports the largest constraint violation seen during a
+// run, the max-norm of the system's residual.
+type ConstraintDrift struct {
+	name  string
+	sys   dynamo.Constrained
+	worst float64
+}
+
+func NewConstraintDrift(sys dynamo.Constrained) *ConstraintDrift {
+	return &ConstraintDrift{
+		name: "constraint_drift",
+		sys:  sys,
+	}
+}
+
+func (c *ConstraintDrift) Name() string {
+	return c.name
+}
+
+func (c *ConstraintDrift) Observe(x dynamo.State, u dynamo.Control, t float64) {
+	for _, r := range c.sys.Residual(x) {
+		c.worst = math.Max(c.worst, math.Abs(r))
+	}
+}
+
+func (c *ConstraintDrift) Value() float64 {
+	return c.worst
+}
+
+func (c *ConstraintDrift) Reset() {
+	c.worst = 0
+}
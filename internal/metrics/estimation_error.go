@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// EstimationError reports the root mean square distance between the true
+// state and an estimator's estimate over a run.
+type EstimationError struct {
+	name    string
+	est     dynamo.Estimator
+	sum     float64
+	samples int
+}
+
+func NewEstimationError(est dynamo.Estimator) *EstimationError {
+	return &EstimationError{
+		name: "estimation_rmse",
+		est:  est,
+	}
+}
+
+func (e *EstimationError) Name() string {
+	return e.name
+}
+
+func (e *EstimationError) Observe(x dynamo.State, u dynamo.Control, t float64) {
+	d := x.Sub(e.est.Estimate()).Norm()
+	e.sum += d * d
+	e.samples++
+}
+
+func (e *EstimationError) Value() float64 {
+	if e.samples == 0 {
+		return 0
+	}
+	return math.Sqrt(e.sum / float64(e.samples))
+}
+
+func (e *EstimationError) Reset() {
+	e.sum = 0
+	e.samples = 0
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// SolverIterations reports the mean number of optimizer iterations per
+// solve of a controller during a run.
+type SolverIterations struct {
+	name string
+	opt  dynamo.Optimizer
+	base dynamo.SolveStats
+}
+
+func NewSolverIterations(opt dynamo.Optimizer) *SolverIterations {
+	return &SolverIterations{
+		name: "solver_iterations",
+		opt:  opt,
+	}
+}
+
+func (s *SolverIterations) Name() string {
+	return s.name
+}
+
+// Observe is a no-op: the controller keeps the counts.
+func (s *SolverIterations) Observe(x dynamo.State, u dynamo.Control, t float64) {}
+
+func (s *SolverIterations) Value() float64 {
+	st := s.opt.SolveStats()
+	solves := st.Solves - s.base.Solves
+	if solves == 0 {
+		return 0
+	}
+	return float64(st.Iterations-s.base.Iterations) / float64(solves)
+}
+
+func (s *SolverIterations) Reset() {
+	s.base = s.opt.SolveStats()
+}
+
+// SolverCost reports the mean optimal cost a controller reached per solve
+// during a run.
+type SolverCost struct {
+	name string
+	opt  dynamo.Optimizer
+	base dynamo.SolveStats
+}
+
+func NewSolverCost(opt dynamo.Optimizer) *SolverCost {
+	return &SolverCost{
+		name: "solver_cost",
+		opt:  opt,
+	}
+}
+
+func (s *SolverCost) Name() string {
+	return s.name
+}
+
+// Observe is a no-op: the controller keeps the costs.
+func (s *SolverCost) Observe(x dynamo.State, u dynamo.Control, t float64) {}
+
+func (s *SolverCost) Value() float64 {
+	st := s.opt.SolveStats()
+	solves := st.Solves - s.base.Solves
+	if solves == 0 {
+		return 0
+	}
+	return (st.Cost - s.base.Cost) / float64(solves)
+}
+
+func (s *SolverCost) Reset() {
+	s.base = s.opt.SolveStats()
+}
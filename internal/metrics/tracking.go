@@ -0,0 +1,221 @@
+package metrics
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// Tracking returns every tracking metric for state variable i following
+// ref. A non-empty suffix is appended to the names, as in "iae_y", to tell
+// several tracked variables apart.
+func Tracking(i int, ref dynamo.Reference, suffix string) []dynamo.Metric {
+	return []dynamo.Metric{
+		NewIAE(i, ref, suffix),
+		NewISE(i, ref, suffix),
+		NewITAE(i, ref, suffix),
+		NewOvershoot(i, ref, suffix),
+		NewRiseTime(i, ref, suffix),
+		NewSettlingTime(i, ref, suffix),
+	}
+}
+
+func metricName(name, suffix string) string {
+	if suffix == "" {
+		return name
+	}
+	return name + "_" + suffix
+}
+
+// IntegralError integrates a weighting of the error e = r(t) - x[i]
+// between a reference and the state variable following it, by the
+// trapezoidal rule over the observed steps.
+type IntegralError struct {
+	name   string
+	index  int
+	ref    dynamo.Reference
+	weight func(t, e float64) float64
+
+	sum     float64
+	prevT   float64
+	prevW   float64
+	started bool
+}
+
+// NewIAE integrates the absolute error, ∫|e|dt.
+func NewIAE(i int, ref dynamo.Reference, suffix string) *IntegralError {
+	return newIntegralError("iae", i, ref, suffix, func(_, e float64) float64 { return math.Abs(e) })
+}
+
+// NewISE integrates the squared error, ∫e²dt.
+func NewISE(i int, ref dynamo.Reference, suffix string) *IntegralError {
+	return newIntegralError("ise", i, ref, suffix, func(_, e float64) float64 { return e * e })
+}
+
+// NewITAE integrates the time-weighted absolute error, ∫t|e|dt, which
+// punishes errors that persist.
+func NewITAE(i int, ref dynamo.Reference, suffix string) *IntegralError {
+	return newIntegralError("itae", i, ref, suffix, func(t, e float64) float64 { return t * math.Abs(e) })
+}
+
+func newIntegralError(name string, i int, ref dynamo.Reference, suffix string, weight func(t, e float64) float64) *IntegralError {
+	return &IntegralError{
+		name:   metricName(name, suffix),
+		index:  i,
+		ref:    ref,
+		weight: weight,
+	}
+}
+
+func (m *IntegralError) Name() string {
+	return m.name
+}
+
+func (m *IntegralError) Observe(x dynamo.State, u dynamo.Control, t float64) {
+	w := m.weight(t, m.ref.Value(t)-x[m.index])
+	if m.started {
+		m.sum += 0.5 * (w + m.prevW) * (t - m.prevT)
+	}
+	m.prevT, m.prevW, m.started = t, w, true
+}
+
+func (m *IntegralError) Value() float64 {
+	return m.sum
+}
+
+func (m *IntegralError) Reset() {
+	m.sum = 0
+	m.started = false
+}
+
+// StepResponse measures a state variable's response to its reference as a
+// step: from its value when the reference first moves, or at the start
+// when it never does, to the reference's value at the end of the run.
+// Times are measured from that first move. A reference still moving at the
+// end, such as a sine, has no step to measure and reports 0. It keeps the
+// observed trajectory, so it grows with the run.
+type StepResponse struct {
+	name  string
+	index int
+	ref   dynamo.Reference
+	value func(r response) float64
+
+	times, ys, rs []float64
+}
+
+// NewOvershoot reports how far the response goes past the final reference,
+// in percent of the step.
+func NewOvershoot(i int, ref dynamo.Reference, suffix string) *StepResponse {
+	return newStepResponse("overshoot", i, ref, suffix, response.overshoot)
+}
+
+// NewRiseTime reports the time the response takes from 10% to 90% of the
+// step, or the whole run when it never gets there.
+func NewRiseTime(i int, ref dynamo.Reference, suffix string) *StepResponse {
+	return newStepResponse("rise_time", i, ref, suffix, response.riseTime)
+}
+
+// NewSettlingTime reports when the response last leaves the band of ±2%
+// of the step around the final reference, or the whole run when it ends
+// outside it.
+func NewSettlingTime(i int, ref dynamo.Reference, suffix string) *StepResponse {
+	return newStepResponse("settling_time", i, ref, suffix, response.settlingTime)
+}
+
+func newStepResponse(name string, i int, ref dynamo.Reference, suffix string, value func(response) float64) *StepResponse {
+	return &StepResponse{
+		name:  metricName(name, suffix),
+		index: i,
+		ref:   ref,
+		value: value,
+	}
+}
+
+func (m *StepResponse) Name() string {
+	return m.name
+}
+
+func (m *StepResponse) Observe(x dynamo.State, u dynamo.Control, t float64) {
+	m.times = append(m.times, t)
+	m.ys = append(m.ys, x[m.index])
+	m.rs = append(m.rs, m.ref.Value(t))
+}
+
+func (m *StepResponse) Value() float64 {
+	n := len(m.times)
+	if n < 2 || m.rs[n-1] != m.rs[n-2] {
+		return 0
+	}
+	// The response starts at the last sample before the reference moves.
+	start := 0
+	for k := 1; k < len(m.rs); k++ {
+		if m.rs[k] != m.rs[0] {
+			start = k - 1
+			break
+		}
+	}
+	r := response{
+		times: m.times[start:],
+		ys:    m.ys[start:],
+		final: m.rs[n-1],
+	}
+	if math.Abs(r.final-r.ys[0]) < 1e-12 {
+		return 0
+	}
+	return m.value(r)
+}
+
+func (m *StepResponse) Reset() {
+	m.times, m.ys, m.rs = m.times[:0], m.ys[:0], m.rs[:0]
+}
+
+// response is a trajectory normalized to the step it makes.
+type response struct {
+	times, ys []float64
+	final     float64
+}
+
+// progress is how far sample k has come, 0 at the start and 1 at the final
+// reference.
+func (r response) progress(k int) float64 {
+	return (r.ys[k] - r.ys[0]) / (r.final - r.ys[0])
+}
+
+func (r response) window() float64 {
+	return r.times[len(r.times)-1] - r.times[0]
+}
+
+func (r response) overshoot() float64 {
+	peak := 0.0
+	for k := range r.ys {
+		peak = math.Max(peak, r.progress(k))
+	}
+	return 100 * math.Max(0, peak-1)
+}
+
+func (r response) riseTime() float64 {
+	t10 := -1.0
+	for k := range r.ys {
+		z := r.progress(k)
+		if t10 < 0 && z >= 0.1 {
+			t10 = r.times[k]
+		}
+		if z >= 0.9 {
+			return r.times[k] - t10
+		}
+	}
+	return r.window()
+}
+
+func (r response) settlingTime() float64 {
+	last := len(r.ys) - 1
+	if math.Abs(r.progress(last)-1) > 0.02 {
+		return r.window()
+	}
+	for k := last; k >= 0; k-- {
+		if math.Abs(r.progress(k)-1) > 0.02 {
+			return r.times[k+1] - r.times[0]
+		}
+	}
+	return 0
+}
@@ -0,0 +1,9 @@
+// Package modelfile loads models declared in YAML: named state variables,
+// controls and parameters, one derivative expression per state and an
+// optional energy expression. Expressions may use every state, control and
+// parameter by name, plus the time t. A compiled Model is a regular
+// dynamo.System that also implements dynamo.Descriptor,
+// dynamo.Parameterized and dynamo.Hamiltonian, so it works with every
+// controller and command in the tool. Files that pair their states up as
+// positions and velocities also work with the symplectic integrators.
+package modelfile
@@ -0,0 +1,136 @@
+package modelfile
+
+import (
+	"github.com/san-kum/dynsim/internal/autodiff"
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/expr"
+)
+
+// Model is a compiled model file. The compiled expressions are shared
+// between clones; parameter values are not.
+type Model struct {
+	def    Definition
+	schema []dynamo.Param
+	derivs []expr.Func
+	duals  []expr.DualFunc
+	energy expr.Func
+	pos    []int // declared split, nil without one
+	vel    []int
+	params []float64
+}
+
+// Name returns the model name declared in the file.
+func (m *Model) Name() string { return m.def.Name }
+
+// Description returns the model description declared in the file.
+func (m *Model) Description() string { return m.def.Description }
+
+// Clone returns an independent instance with the same parameter values.
+func (m *Model) Clone() *Model {
+	c := *m
+	c.params = append([]float64(nil), m.params...)
+	return &c
+}
+
+func (m *Model) StateDim() int   { return len(m.def.State) }
+func (m *Model) ControlDim() int { return len(m.def.Controls) }
+
+// Split implements dynamo.Separable with the positions and velocities
+// declared in the file. Files that declare none report no split.
+func (m *Model) Split() (pos, vel []int) { return m.pos, m.vel }
+
+func (m *Model) StateVars() []dynamo.Variable {
+	vars := make([]dynamo.Variable, len(m.def.State))
+	for i, v := range m.def.State {
+		vars[i] = dynamo.Variable{Name: v.Name, Unit: v.Unit}
+	}
+	return vars
+}
+
+func (m *Model) ControlVars() []dynamo.Variable {
+	vars := make([]dynamo.Variable, len(m.def.Controls))
+	for i, v := range m.def.Controls {
+		vars[i] = dynamo.Variable{Name: v.Name, Unit: v.Unit}
+	}
+	return vars
+}
+
+// env lays out the expression variables: state, controls, params, t.
+// Missing controls read as zero.
+func (m *Model) env(x dynamo.State, u dynamo.Control, t float64) []float64 {
+	ns, nu := len(m.def.State), len(m.def.Controls)
+	vars := make([]float64, ns+nu+len(m.params)+1)
+	copy(vars[:ns], x)
+	copy(vars[ns:ns+nu], u)
+	copy(vars[ns+nu:], m.params)
+	vars[len(vars)-1] = t
+	return vars
+}
+
+func (m *Model) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.State {
+	vars := m.env(x, u, t)
+	dx := make(dynamo.State, len(m.derivs))
+	for i, f := range m.derivs {
+		dx[i] = f(vars)
+	}
+	return dx
+}
+
+// DeriveDual implements dynamo.Differentiable, so Jacobians of model files
+// are exact.
+func (m *Model) DeriveDual(x, u []autodiff.Dual, t float64) []autodiff.Dual {
+	ns, nu := len(m.def.State), len(m.def.Controls)
+	vars := make([]autodiff.Dual, ns+nu+len(m.params)+1)
+	copy(vars[:ns], x)
+	copy(vars[ns:ns+nu], u)
+	for i, p := range m.params {
+		vars[ns+nu+i] = autodiff.Const(p)
+	}
+	vars[len(vars)-1] = autodiff.Const(t)
+	dx := make([]autodiff.Dual, len(m.duals))
+	for i, f := range m.duals {
+		dx[i] = f(vars)
+	}
+	return dx
+}
+
+// Energy implements dynamo.Hamiltonian. Models without an energy
+// expression report zero.
+func (m *Model) Energy(x dynamo.State) float64 {
+	if m.energy == nil {
+		return 0
+	}
+	return m.energy(m.env(x, nil, 0))
+}
+
+// DefaultState returns the init values declared in the file.
+func (m *Model) DefaultState() dynamo.State {
+	x := make(dynamo.State, len(m.def.State))
+	for i, v := range m.def.State {
+		x[i] = v.Init
+	}
+	return x
+}
+
+// Params implements dynamo.Parameterized.
+func (m *Model) Params() []dynamo.Param { return m.schema }
+
+func (m *Model) GetParams() map[string]float64 {
+	values := make(map[string]float64, len(m.schema))
+	for i, p := range m.schema {
+		values[p.Name] = m.params[i]
+	}
+	return values
+}
+
+func (m *Model) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(m.schema, name, value); err != nil {
+		return err
+	}
+	for i, p := range m.schema {
+		if p.Name == name {
+			m.params[i] = value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package modelfile
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/expr"
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is the YAML form of a model.
+type Definition struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	State       []StateVar        `yaml:"state"`
+	Controls    []Var             `yaml:"controls"`
+	Params      []ParamDef        `yaml:"params"`
+	Derivatives map[string]string `yaml:"derivatives"`
+	Energy      string            `yaml:"energy"`
+	// Positions and Velocities pair up state names for the symplectic
+	// integrators: d(position)/dt must be the matching velocity.
+	Positions  []string `yaml:"positions"`
+	Velocities []string `yaml:"velocities"`
+}
+
+// Var names a control input.
+type Var struct {
+	Name string `yaml:"name"`
+	Unit string `yaml:"unit"`
+}
+
+// StateVar names a state variable and its default initial value.
+type StateVar struct {
+	Name string  `yaml:"name"`
+	Unit string  `yaml:"unit"`
+	Init float64 `yaml:"init"`
+}
+
+// ParamDef declares a tunable parameter. Missing bounds are unbounded.
+type ParamDef struct {
+	Name        string   `yaml:"name"`
+	Default     float64  `yaml:"default"`
+	Min         *float64 `yaml:"min"`
+	Max         *float64 `yaml:"max"`
+	Unit        string   `yaml:"unit"`
+	Description string   `yaml:"description"`
+}
+
+// Load reads and compiles a model file.
+func Load(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m, err := Compile(def)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Compile checks a definition and compiles its expressions.
+func Compile(def Definition) (*Model, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("model needs a name")
+	}
+	if len(def.State) == 0 {
+		return nil, fmt.Errorf("model %s declares no state", def.Name)
+	}
+
+	// Expressions see [state..., controls..., params..., t].
+	var names []string
+	seen := map[string]bool{"t": true}
+	declare := func(kind, name string) error {
+		if name == "" {
+			return fmt.Errorf("%s without a name", kind)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate name: %s", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+		return nil
+	}
+	for _, v := range def.State {
+		if err := declare("state", v.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range def.Controls {
+		if err := declare("control", v.Name); err != nil {
+			return nil, err
+		}
+	}
+	schema := make([]dynamo.Param, len(def.Params))
+	for i, p := range def.Params {
+		if err := declare("param", p.Name); err != nil {
+			return nil, err
+		}
+		schema[i] = dynamo.Param{
+			Name:        p.Name,
+			Default:     p.Default,
+			Min:         -dynamo.Unbounded,
+			Max:         dynamo.Unbounded,
+			Unit:        p.Unit,
+			Description: p.Description,
+		}
+		if p.Min != nil {
+			schema[i].Min = *p.Min
+		}
+		if p.Max != nil {
+			schema[i].Max = *p.Max
+		}
+		if !schema[i].Contains(p.Default) {
+			return nil, fmt.Errorf("param %s: default %g not in [%g, %g]", p.Name, p.Default, schema[i].Min, schema[i].Max)
+		}
+	}
+	names = append(names, "t")
+
+	for name := range def.Derivatives {
+		if !isState(def.State, name) {
+			return nil, fmt.Errorf("derivative for unknown state: %s", name)
+		}
+	}
+	nodes := make([]*expr.Node, len(def.State))
+	derivs := make([]expr.Func, len(def.State))
+	duals := make([]expr.DualFunc, len(def.State))
+	for i, v := range def.State {
+		src, ok := def.Derivatives[v.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing derivative for state %s", v.Name)
+		}
+		n, err := expr.Parse(src)
+		if err == nil {
+			derivs[i], err = n.Compile(names)
+		}
+		if err == nil {
+			duals[i], err = n.CompileDual(names)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("d%s/dt: %w", v.Name, err)
+		}
+		nodes[i] = n
+	}
+	pos, vel, err := split(def, nodes)
+	if err != nil {
+		return nil, err
+	}
+	var energy expr.Func
+	if def.Energy != "" {
+		f, err := expr.Compile(def.Energy, names)
+		if err != nil {
+			return nil, fmt.Errorf("energy: %w", err)
+		}
+		energy = f
+	}
+
+	m := &Model{
+		def:    def,
+		schema: schema,
+		derivs: derivs,
+		duals:  duals,
+		energy: energy,
+		pos:    pos,
+		vel:    vel,
+		params: make([]float64, len(schema)),
+	}
+	for i, p := range schema {
+		m.params[i] = p.Default
+	}
+	return m, nil
+}
+
+func isState(vars []StateVar, name string) bool {
+	return stateIndex(vars, name) >= 0
+}
+
+func stateIndex(vars []StateVar, name string) int {
+	for i, v := range vars {
+		if v.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// split resolves the declared positions and velocities to state indices.
+// Every state must be one or the other, each position must integrate to
+// its velocity, and the accelerations must not read the velocities, or
+// the symplectic schemes would silently lose their order.
+func split(def Definition, derivs []*expr.Node) (pos, vel []int, err error) {
+	if len(def.Positions) == 0 && len(def.Velocities) == 0 {
+		return nil, nil, nil
+	}
+	if len(def.Positions) != len(def.Velocities) {
+		return nil, nil, fmt.Errorf("%d positions but %d velocities", len(def.Positions), len(def.Velocities))
+	}
+	if 2*len(def.Positions) != len(def.State) {
+		return nil, nil, fmt.Errorf("positions and velocities must cover all %d states", len(def.State))
+	}
+	used := make(map[string]bool, len(def.State))
+	resolve := func(name string) (int, error) {
+		i := stateIndex(def.State, name)
+		if i < 0 {
+			return 0, fmt.Errorf("split names unknown state: %s", name)
+		}
+		if used[name] {
+			return 0, fmt.Errorf("state %s is split twice", name)
+		}
+		used[name] = true
+		return i, nil
+	}
+	pos, vel = make([]int, len(def.Positions)), make([]int, len(def.Velocities))
+	for k := range def.Positions {
+		if pos[k], err = resolve(def.Positions[k]); err != nil {
+			return nil, nil, err
+		}
+		if vel[k], err = resolve(def.Velocities[k]); err != nil {
+			return nil, nil, err
+		}
+	}
+	for k := range pos {
+		q, v := def.Positions[k], def.Velocities[k]
+		if d := derivs[pos[k]]; d.String() != v {
+			return nil, nil, fmt.Errorf("d%s/dt must be %s to split it as a position, not %s", q, v, d)
+		}
+		for _, a := range def.Velocities {
+			if derivs[vel[k]].Depends(a) {
+				return nil, nil, fmt.Errorf("d%s/dt reads velocity %s; split models need accelerations independent of the velocities", v, a)
+			}
+		}
+	}
+	return pos, vel, nil
+}
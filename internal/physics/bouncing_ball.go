@@ -0,0 +1,102 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// BouncingBall is a ball dropped onto the ground, a hybrid system: free
+// fall between impacts and, at each impact, an instant reversal of the
+// velocity scaled by the coefficient of restitution. A bounce slower than
+// RestSpeed leaves the ball resting on the ground.
+type BouncingBall struct {
+	Gravity     float64
+	Restitution float64
+	RestSpeed   float64
+}
+
+func NewBouncingBall() *BouncingBall {
+	return &BouncingBall{Gravity: 9.81, Restitution: 0.8, RestSpeed: 0.05}
+}
+
+func (b *BouncingBall) StateDim() int   { return 2 }
+func (b *BouncingBall) ControlDim() int { return 1 }
+
+func (b *BouncingBall) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "y", Unit: "m"}, {Name: "v", Unit: "m/s"}}
+}
+
+func (b *BouncingBall) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "accel", Unit: "m/s²"}}
+}
+
+// Split implements dynamo.Separable.
+func (b *BouncingBall) Split() (pos, vel []int) { return bodySplit(1, 1) }
+
+func (b *BouncingBall) Derive(x dynamo.State, u dynamo.Control, _ float64) dynamo.State {
+	a := -b.Gravity
+	if len(u) > 0 {
+		a += u[0]
+	}
+	// the ground holds a resting ball up
+	if x[0] <= 0 && x[1] == 0 && a < 0 {
+		a = 0
+	}
+	return dynamo.State{x[1], a}
+}
+
+// Guards implements dynamo.HybridSystem.
+func (b *BouncingBall) Guards() []dynamo.Event {
+	return []dynamo.Event{{
+		Name:      "impact",
+		Fn:        func(_ float64, x dynamo.State) float64 { return x[0] },
+		Direction: dynamo.CrossFalling,
+		Reset: func(_ float64, x dynamo.State) dynamo.State {
+			v := -b.Restitution * x[1]
+			if math.Abs(v) < b.RestSpeed {
+				v = 0
+			}
+			return dynamo.State{0, v}
+		},
+	}}
+}
+
+func (b *BouncingBall) DefaultState() dynamo.State { return dynamo.State{5, 0} }
+
+// Energy is per unit mass.
+func (b *BouncingBall) Energy(x dynamo.State) float64 {
+	return 0.5*x[1]*x[1] + b.Gravity*x[0]
+}
+
+var bouncingBallParams = []dynamo.Param{
+	{Name: "gravity", Default: 9.81, Min: 0, Max: 100, Unit: "m/s²", Description: "gravitational acceleration"},
+	{Name: "restitution", Default: 0.8, Min: 0, Max: 1, Description: "fraction of speed kept per bounce"},
+	{Name: "rest_speed", Default: 0.05, Min: 0, Max: 10, Unit: "m/s", Description: "bounce speed below which the ball stops"},
+}
+
+// Params implements dynamo.Parameterized.
+func (b *BouncingBall) Params() []dynamo.Param { return bouncingBallParams }
+
+func (b *BouncingBall) GetParams() map[string]float64 {
+	return map[string]float64{
+		"gravity":     b.Gravity,
+		"restitution": b.Restitution,
+		"rest_speed":  b.RestSpeed,
+	}
+}
+
+func (b *BouncingBall) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(bouncingBallParams, name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "gravity":
+		b.Gravity = value
+	case "restitution":
+		b.Restitution = value
+	case "rest_speed":
+		b.RestSpeed = value
+	}
+	return nil
+}
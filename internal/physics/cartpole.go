@@ -3,6 +3,7 @@ package physics
 import (
 	"math"
 
+	"github.com/san-kum/dynsim/internal/autodiff"
 	"github.com/san-kum/dynsim/internal/dynamo"
 )
 
@@ -30,6 +31,18 @@ func (c *CartPole) ControlDim() int {
 	return 1
 }
 
+func (c *CartPole) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "x", Unit: "m"}, {Name: "v", Unit: "m/s"}, {Name: "theta", Unit: "rad"}, {Name: "omega", Unit: "rad/s"}}
+}
+
+func (c *CartPole) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "force", Unit: "N"}}
+}
+
+// Split implements dynamo.Separable: the cart and the pole each hold a
+// position and its velocity.
+func (c *CartPole) Split() (pos, vel []int) { return bodySplit(2, 1) }
+
 func (c *CartPole) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.State {
 	pos := x[0]
 	vel := x[1]
@@ -57,3 +70,55 @@ func (c *CartPole) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.St
 
 	return dynamo.State{vel, xacc, omega, thetaacc}
 }
+
+// DeriveDual implements dynamo.Differentiable.
+func (c *CartPole) DeriveDual(x, u []autodiff.Dual, _ float64) []autodiff.Dual {
+	vel, theta, omega := x[1], x[2], x[3]
+	force := dualInput(u, 0)
+
+	mc, mp, l, g := c.CartMass, c.PoleMass, c.PoleLength, c.Gravity
+	sint, cost := autodiff.Sin(theta), autodiff.Cos(theta)
+
+	temp := force.Add(omega.Mul(sint).Scale(mp * l)).Scale(1 / (mc + mp))
+	den := cost.Mul(cost).Scale(-mp / (mc + mp)).AddC(4.0 / 3.0).Scale(l)
+	thetaacc := sint.Scale(g).Sub(cost.Mul(temp)).Div(den)
+	xacc := temp.Sub(thetaacc.Mul(cost).Scale(mp * l / (mc + mp)))
+
+	return []autodiff.Dual{vel, xacc, omega, thetaacc}
+}
+
+var cartPoleParams = []dynamo.Param{
+	{Name: "cart_mass", Default: 1.0, Min: 1e-3, Max: 100, Unit: "kg", Description: "cart mass"},
+	{Name: "pole_mass", Default: 0.1, Min: 1e-3, Max: 100, Unit: "kg", Description: "pole mass"},
+	{Name: "pole_length", Default: 1.0, Min: 1e-3, Max: 100, Unit: "m", Description: "pole length"},
+	{Name: "gravity", Default: 9.81, Min: 0, Max: 100, Unit: "m/s^2", Description: "gravitational acceleration"},
+}
+
+// Params implements dynamo.Parameterized.
+func (c *CartPole) Params() []dynamo.Param { return cartPoleParams }
+
+func (c *CartPole) GetParams() map[string]float64 {
+	return map[string]float64{
+		"cart_mass":   c.CartMass,
+		"pole_mass":   c.PoleMass,
+		"pole_length": c.PoleLength,
+		"gravity":     c.Gravity,
+	}
+}
+
+func (c *CartPole) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(cartPoleParams, name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "cart_mass":
+		c.CartMass = value
+	case "pole_mass":
+		c.PoleMass = value
+	case "pole_length":
+		c.PoleLength = value
+	case "gravity":
+		c.Gravity = value
+	}
+	return nil
+}
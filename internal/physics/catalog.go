@@ -0,0 +1,243 @@
+package physics
+
+import (
+	"sort"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// Spec describes a model in the catalog shared by the CLI, the experiment
+// registry, the TUI and the GUI.
+type Spec struct {
+	Name        string
+	Description string
+	ControlDim  int
+	New         func() dynamo.System
+	Init        func(dyn dynamo.System) dynamo.State
+}
+
+// Build constructs a fresh model instance and its default initial state.
+func (s Spec) Build() (dynamo.System, dynamo.State) {
+	dyn := s.New()
+	return dyn, s.Init(dyn)
+}
+
+var catalog = []Spec{
+	{
+		Name:        "pendulum",
+		Description: "simple harmonic motion",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewPendulum() },
+		Init:        fixedState(0.5, 0),
+	},
+	{
+		Name:        "double_pendulum",
+		Description: "chaotic dynamics",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewDoublePendulum() },
+		Init:        fixedState(0.5, 0.5, 0, 0),
+	},
+	{
+		Name:        "cartpole",
+		Description: "balance control",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewCartPole() },
+		Init:        fixedState(0, 0, 0.1, 0),
+	},
+	{
+		Name:        "spring_mass",
+		Description: "oscillator",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewSpringMass() },
+		Init:        fixedState(1, 0),
+	},
+	{
+		Name:        "spring_chain",
+		Description: "coupled oscillators",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewSpringMassChain(3) },
+		Init:        fixedState(1, 0, 0, 0, 0, 0),
+	},
+	{
+		Name:        "drone",
+		Description: "2d quadrotor",
+		ControlDim:  2,
+		New:         func() dynamo.System { return NewDrone() },
+		Init:        fixedState(0, 5, 0, 0, 0, 0),
+	},
+	{
+		Name:        "nbody",
+		Description: "gravitational",
+		ControlDim:  3,
+		New:         func() dynamo.System { return NewNBody(3) },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*NBody).RingState() },
+	},
+	{
+		Name:        "lorenz",
+		Description: "butterfly attractor",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewLorenz() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*Lorenz).DefaultState() },
+	},
+	{
+		Name:        "rossler",
+		Description: "spiral chaos",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewRossler() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*Rossler).DefaultState() },
+	},
+	{
+		Name:        "vanderpol",
+		Description: "limit cycle oscillator",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewVanDerPol() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*VanDerPol).DefaultState() },
+	},
+	{
+		Name:        "threebody",
+		Description: "orbital chaos",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewThreeBody() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*ThreeBody).DefaultState() },
+	},
+	{
+		Name:        "coupled",
+		Description: "energy transfer",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewCoupledPendulums() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*CoupledPendulums).DefaultState() },
+	},
+	{
+		Name:        "masschain",
+		Description: "wave propagation",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewMassChain(40) },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*MassChain).DefaultState() },
+	},
+	{
+		Name:        "gyroscope",
+		Description: "rigid body rotation",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewGyroscope() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*Gyroscope).DefaultState() },
+	},
+	{
+		Name:        "wave",
+		Description: "string vibration",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewWave(50) },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*Wave).DefaultState() },
+	},
+	{
+		Name:        "doublewell",
+		Description: "bistable potential",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewDoubleWell() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*DoubleWell).DefaultState() },
+	},
+	{
+		Name:        "duffing",
+		Description: "chaotic oscillator",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewDuffing() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*Duffing).DefaultState() },
+	},
+	{
+		Name:        "magnetic",
+		Description: "fractal basins",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewMagneticPendulum() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*MagneticPendulum).DefaultState() },
+	},
+	{
+		Name:        "fluid",
+		Description: "smoothed particle hydrodynamics",
+		ControlDim:  3,
+		New:         func() dynamo.System { return NewSPH(400) },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*SPH).DefaultState() },
+	},
+	{
+		Name:        "hybrid",
+		Description: "stars and gas galaxy",
+		ControlDim:  3,
+		New:         func() dynamo.System { return NewHybrid(8192, 4096) },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*Hybrid).DefaultState() },
+	},
+	{
+		Name:        "mackey_glass",
+		Description: "delayed feedback chaos",
+		ControlDim:  0,
+		New:         func() dynamo.System { return NewMackeyGlass() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*MackeyGlass).DefaultState() },
+	},
+	{
+		Name:        "bouncing_ball",
+		Description: "impacts with restitution",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewBouncingBall() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*BouncingBall).DefaultState() },
+	},
+	{
+		Name:        "impact_oscillator",
+		Description: "forced oscillator hitting a stop",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewImpactOscillator() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*ImpactOscillator).DefaultState() },
+	},
+	{
+		Name:        "four_bar",
+		Description: "closed linkage with constraints",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewFourBar() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*FourBar).DefaultState() },
+	},
+	{
+		Name:        "track_pendulum",
+		Description: "pendulum on a sliding cart",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewTrackPendulum() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*TrackPendulum).DefaultState() },
+	},
+	{
+		Name:        "elastic_pendulum",
+		Description: "pendulum on a spring, equations derived from its lagrangian",
+		ControlDim:  1,
+		New:         func() dynamo.System { return NewElasticPendulum() },
+		Init:        func(dyn dynamo.System) dynamo.State { return dyn.(*ElasticPendulum).DefaultState() },
+	},
+}
+
+// Catalog returns every registered model in declaration order.
+func Catalog() []Spec {
+	specs := make([]Spec, len(catalog))
+	copy(specs, catalog)
+	return specs
+}
+
+// Lookup returns the catalog entry for name.
+func Lookup(name string) (Spec, bool) {
+	for _, s := range catalog {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Spec{}, false
+}
+
+// ModelNames returns the sorted names of all catalog models.
+func ModelNames() []string {
+	names := make([]string, 0, len(catalog))
+	for _, s := range catalog {
+		names = append(names, s.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func fixedState(values ...float64) func(dynamo.System) dynamo.State {
+	return func(dynamo.System) dynamo.State {
+		s := make(dynamo.State, len(values))
+		copy(s, values)
+		return s
+	}
+}
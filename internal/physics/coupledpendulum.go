@@ -28,6 +28,15 @@ func NewCoupledPendulums() *CoupledPendulums {
 func (c *CoupledPendulums) StateDim() int   { return 4 }
 func (c *CoupledPendulums) ControlDim() int { return 0 }
 
+func (c *CoupledPendulums) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "theta1", Unit: "rad"}, {Name: "omega1", Unit: "rad/s"}, {Name: "theta2", Unit: "rad"}, {Name: "omega2", Unit: "rad/s"}}
+}
+
+func (c *CoupledPendulums) ControlVars() []dynamo.Variable { return nil }
+
+// Split implements dynamo.Separable.
+func (c *CoupledPendulums) Split() (pos, vel []int) { return bodySplit(2, 1) }
+
 func (c *CoupledPendulums) Derive(state dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	theta1, omega1, theta2, omega2 := state[0], state[1], state[2], state[3]
 
@@ -48,7 +57,15 @@ func (c *CoupledPendulums) DefaultState() dynamo.State {
 	return dynamo.State{0.5, 0.0, 0.0, 0.0} // One pendulum displaced
 }
 
-// GetParams implements dynamo.Configurable
+var coupledPendulumsParams = []dynamo.Param{
+	{Name: "l", Default: 1.0, Min: 1e-3, Max: 100, Unit: "m", Description: "pendulum length"},
+	{Name: "g", Default: 9.81, Min: 0, Max: 100, Unit: "m/s^2", Description: "gravitational acceleration"},
+	{Name: "k", Default: 20.0, Min: 0, Max: 1e3, Unit: "N/m", Description: "coupling spring constant"},
+}
+
+// Params implements dynamo.Parameterized.
+func (c *CoupledPendulums) Params() []dynamo.Param { return coupledPendulumsParams }
+
 func (c *CoupledPendulums) GetParams() map[string]float64 {
 	return map[string]float64{
 		"l": c.l,
@@ -57,8 +74,10 @@ func (c *CoupledPendulums) GetParams() map[string]float64 {
 	}
 }
 
-// SetParam implements dynamo.Configurable
-func (c *CoupledPendulums) SetParam(name string, value float64) {
+func (c *CoupledPendulums) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(coupledPendulumsParams, name, value); err != nil {
+		return err
+	}
 	switch name {
 	case "l":
 		c.l = value
@@ -67,4 +86,5 @@ func (c *CoupledPendulums) SetParam(name string, value float64) {
 	case "k":
 		c.k = value
 	}
+	return nil
 }
@@ -9,8 +9,19 @@
 //   - [ThreeBody]: gravitational three-body problem
 //   - [NBody]: N-particle gravitational simulation (GPU-accelerated)
 //
-// Many models also implement [dynamo.Configurable] for runtime parameter
-// adjustment and [dynamo.Hamiltonian] for energy calculation.
+// Every model is listed in the shared catalog ([Catalog], [Lookup]) with its
+// default initial state and tunable parameters, so the CLI, TUI and GUI can
+// all construct it the same way.
+//
+// Every model implements [dynamo.Descriptor] to name its state and control
+// variables with units, and [dynamo.Parameterized] to publish its tunable
+// parameters with defaults and bounds. Many also implement
+// [dynamo.Hamiltonian] for energy calculation, and some implement
+// [dynamo.Jacobian] so implicit integrators can skip finite differences.
+// Models with impacts implement [dynamo.HybridSystem] to declare their
+// guards and reset maps, and linkages embed [mechanics.Constrained] to solve
+// their holonomic constraints. [ElasticPendulum] is defined by its energies
+// alone, with [lagrange] deriving the equations of motion.
 //
 // # Energy Conservation
 //
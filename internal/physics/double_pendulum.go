@@ -3,6 +3,7 @@ package physics
 import (
 	"math"
 
+	"github.com/san-kum/dynsim/internal/autodiff"
 	"github.com/san-kum/dynsim/internal/dynamo"
 )
 
@@ -29,6 +30,17 @@ func NewDoublePendulum() *DoublePendulum {
 func (d *DoublePendulum) StateDim() int   { return 4 }
 func (d *DoublePendulum) ControlDim() int { return 1 }
 
+func (d *DoublePendulum) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "theta1", Unit: "rad"}, {Name: "theta2", Unit: "rad"}, {Name: "omega1", Unit: "rad/s"}, {Name: "omega2", Unit: "rad/s"}}
+}
+
+func (d *DoublePendulum) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "torque", Unit: "N·m"}}
+}
+
+// Split implements dynamo.Separable.
+func (d *DoublePendulum) Split() (pos, vel []int) { return dynamo.BlockSplit(2) }
+
 func (d *DoublePendulum) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.State {
 	theta1, theta2, omega1, omega2 := x[0], x[1], x[2], x[3]
 	m1, m2, l1, l2, g := d.M1, d.M2, d.L1, d.L2, d.Gravity
@@ -57,6 +69,33 @@ func (d *DoublePendulum) Derive(x dynamo.State, u dynamo.Control, t float64) dyn
 	return dynamo.State{omega1, omega2, alpha1, alpha2}
 }
 
+// DeriveDual implements dynamo.Differentiable.
+func (d *DoublePendulum) DeriveDual(x, u []autodiff.Dual, _ float64) []autodiff.Dual {
+	theta1, theta2, omega1, omega2 := x[0], x[1], x[2], x[3]
+	m1, m2, l1, l2, g := d.M1, d.M2, d.L1, d.L2, d.Gravity
+
+	delta := theta2.Sub(theta1)
+	sinD, cosD := autodiff.Sin(delta), autodiff.Cos(delta)
+	sin1, sin2 := autodiff.Sin(theta1), autodiff.Sin(theta2)
+	tau := dualInput(u, 0)
+
+	den1 := cosD.Mul(cosD).Scale(-m2 * l1).AddC((m1 + m2) * l1)
+	den2 := den1.Scale(l2 / l1)
+
+	alpha1 := omega1.Mul(omega1).Mul(sinD).Mul(cosD).Scale(m2 * l1).
+		Add(sin2.Mul(cosD).Scale(m2 * g)).
+		Add(omega2.Mul(omega2).Mul(sinD).Scale(m2 * l2)).
+		Sub(sin1.Scale((m1 + m2) * g)).
+		Add(tau).Div(den1)
+
+	alpha2 := omega2.Mul(omega2).Mul(sinD).Mul(cosD).Scale(-m2 * l2).
+		Add(sin1.Mul(cosD).Scale((m1 + m2) * g)).
+		Sub(omega1.Mul(omega1).Mul(sinD).Scale((m1 + m2) * l1)).
+		Sub(sin2.Scale((m1 + m2) * g)).Div(den2)
+
+	return []autodiff.Dual{omega1, omega2, alpha1, alpha2}
+}
+
 func (d *DoublePendulum) Energy(x dynamo.State) float64 {
 	theta1, theta2, omega1, omega2 := x[0], x[1], x[2], x[3]
 	m1, m2, l1, l2, g := d.M1, d.M2, d.L1, d.L2, d.Gravity
@@ -72,3 +111,43 @@ func (d *DoublePendulum) Energy(x dynamo.State) float64 {
 
 	return ke + pe
 }
+
+var doublePendulumParams = []dynamo.Param{
+	{Name: "m1", Default: DefaultMass, Min: 1e-3, Max: 100, Unit: "kg", Description: "upper bob mass"},
+	{Name: "m2", Default: DefaultMass, Min: 1e-3, Max: 100, Unit: "kg", Description: "lower bob mass"},
+	{Name: "l1", Default: DefaultLength, Min: 1e-3, Max: 100, Unit: "m", Description: "upper rod length"},
+	{Name: "l2", Default: DefaultLength, Min: 1e-3, Max: 100, Unit: "m", Description: "lower rod length"},
+	{Name: "gravity", Default: DefaultGravity, Min: 0, Max: 100, Unit: "m/s^2", Description: "gravitational acceleration"},
+}
+
+// Params implements dynamo.Parameterized.
+func (d *DoublePendulum) Params() []dynamo.Param { return doublePendulumParams }
+
+func (d *DoublePendulum) GetParams() map[string]float64 {
+	return map[string]float64{
+		"m1":      d.M1,
+		"m2":      d.M2,
+		"l1":      d.L1,
+		"l2":      d.L2,
+		"gravity": d.Gravity,
+	}
+}
+
+func (d *DoublePendulum) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(doublePendulumParams, name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "m1":
+		d.M1 = value
+	case "m2":
+		d.M2 = value
+	case "l1":
+		d.L1 = value
+	case "l2":
+		d.L2 = value
+	case "gravity":
+		d.Gravity = value
+	}
+	return nil
+}
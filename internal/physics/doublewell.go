@@ -6,18 +6,32 @@ import (
 	"github.com/san-kum/dynsim/internal/dynamo"
 )
 
-// DoubleWell models a particle in a bistable potential well.
+// DoubleWell models a particle in a bistable potential well. A nonzero Noise
+// adds a Brownian force, which with an SDE integrator drives hops between
+// the wells.
 type DoubleWell struct {
 	A, B, Mass, Damping float64
+	Noise               float64
 }
 
 func NewDoubleWell() *DoubleWell {
-	return &DoubleWell{1.0, 1.0, 1.0, 0.1}
+	return &DoubleWell{1.0, 1.0, 1.0, 0.1, 0}
 }
 
 func (d *DoubleWell) StateDim() int   { return 2 }
 func (d *DoubleWell) ControlDim() int { return 1 }
 
+func (d *DoubleWell) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "x", Unit: "m"}, {Name: "v", Unit: "m/s"}}
+}
+
+func (d *DoubleWell) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "force", Unit: "N"}}
+}
+
+// Split implements dynamo.Separable.
+func (d *DoubleWell) Split() (pos, vel []int) { return bodySplit(1, 1) }
+
 func (d *DoubleWell) Derive(s dynamo.State, u dynamo.Control, _ float64) dynamo.State {
 	if len(s) < 2 {
 		return make(dynamo.State, 2)
@@ -30,6 +44,11 @@ func (d *DoubleWell) Derive(s dynamo.State, u dynamo.Control, _ float64) dynamo.
 	return dynamo.State{v, (-4*d.A*x*(x*x-d.B) - d.Damping*v + ef) / d.Mass}
 }
 
+// Diffusion implements dynamo.Stochastic: white-noise force on the velocity.
+func (d *DoubleWell) Diffusion(_ dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
+	return dynamo.State{0, d.Noise / d.Mass}
+}
+
 func (d *DoubleWell) DefaultState() dynamo.State { return dynamo.State{math.Sqrt(d.B) + 0.1, 0} }
 
 func (d *DoubleWell) Energy(s dynamo.State) float64 {
@@ -40,20 +59,42 @@ func (d *DoubleWell) Energy(s dynamo.State) float64 {
 	return 0.5*d.Mass*v*v + d.A*math.Pow(x*x-d.B, 2)
 }
 
+var doubleWellParams = []dynamo.Param{
+	{Name: "A", Default: 1.0, Min: 0, Max: 100, Description: "quadratic barrier coefficient"},
+	{Name: "B", Default: 1.0, Min: 1e-3, Max: 100, Description: "quartic confinement coefficient"},
+	{Name: "mass", Default: 1.0, Min: 1e-3, Max: 100, Unit: "kg", Description: "particle mass"},
+	{Name: "damping", Default: 0.1, Min: 0, Max: 10, Unit: "kg/s", Description: "viscous damping"},
+	{Name: "noise", Default: 0, Min: 0, Max: 10, Unit: "N·s^0.5", Description: "brownian force intensity"},
+}
+
+// Params implements dynamo.Parameterized.
+func (d *DoubleWell) Params() []dynamo.Param { return doubleWellParams }
+
 func (d *DoubleWell) GetParams() map[string]float64 {
-	return map[string]float64{"A": d.A, "B": d.B, "mass": d.Mass, "damping": d.Damping}
+	return map[string]float64{
+		"A":       d.A,
+		"B":       d.B,
+		"mass":    d.Mass,
+		"damping": d.Damping,
+		"noise":   d.Noise,
+	}
 }
 
-func (d *DoubleWell) SetParam(n string, v float64) error {
-	switch n {
+func (d *DoubleWell) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(doubleWellParams, name, value); err != nil {
+		return err
+	}
+	switch name {
 	case "A":
-		d.A = v
+		d.A = value
 	case "B":
-		d.B = v
+		d.B = value
 	case "mass":
-		d.Mass = v
+		d.Mass = value
 	case "damping":
-		d.Damping = v
+		d.Damping = value
+	case "noise":
+		d.Noise = value
 	}
 	return nil
 }
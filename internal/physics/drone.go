@@ -1,9 +1,9 @@
 package physics
 
 import (
-	"fmt"
 	"math"
 
+	"github.com/san-kum/dynsim/internal/autodiff"
 	"github.com/san-kum/dynsim/internal/dynamo"
 )
 
@@ -11,6 +11,7 @@ type Drone struct {
 	Mass, Inertia, ArmLength float64
 	Gravity, DragCoeff       float64
 	AngDrag                  float64
+	Wind                     float64 // gust intensity, see Diffusion
 }
 
 func NewDrone() *Drone {
@@ -27,6 +28,17 @@ func NewDrone() *Drone {
 func (d *Drone) StateDim() int   { return 6 }
 func (d *Drone) ControlDim() int { return 2 }
 
+func (d *Drone) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "x", Unit: "m"}, {Name: "y", Unit: "m"}, {Name: "theta", Unit: "rad"}, {Name: "vx", Unit: "m/s"}, {Name: "vy", Unit: "m/s"}, {Name: "omega", Unit: "rad/s"}}
+}
+
+func (d *Drone) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "thrust_left", Unit: "N"}, {Name: "thrust_right", Unit: "N"}}
+}
+
+// Split implements dynamo.Separable.
+func (d *Drone) Split() (pos, vel []int) { return dynamo.BlockSplit(3) }
+
 func (d *Drone) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.State {
 	theta, vx, vy, omega := x[2], x[3], x[4], x[5]
 
@@ -54,6 +66,34 @@ func (d *Drone) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.State
 	return dynamo.State{vx, vy, omega, ax, ay, alpha}
 }
 
+// DeriveDual implements dynamo.Differentiable. Like Derive it clips
+// negative thrust, so the Jacobian there has no thrust column.
+func (d *Drone) DeriveDual(x, u []autodiff.Dual, _ float64) []autodiff.Dual {
+	theta, vx, vy, omega := x[2], x[3], x[4], x[5]
+
+	thrustL, thrustR := autodiff.Dual{}, autodiff.Dual{}
+	if len(u) >= 2 {
+		thrustL, thrustR = u[0], u[1]
+	} else if len(u) >= 1 {
+		thrustL, thrustR = u[0].Scale(0.5), u[0].Scale(0.5)
+	}
+	thrustL = autodiff.Max(autodiff.Dual{}, thrustL)
+	thrustR = autodiff.Max(autodiff.Dual{}, thrustR)
+
+	totalThrust := thrustL.Add(thrustR)
+	torque := thrustR.Sub(thrustL).Scale(d.ArmLength)
+
+	sin, cos := autodiff.Sin(theta), autodiff.Cos(theta)
+	fx := totalThrust.Mul(sin).Neg().Sub(vx.Scale(d.DragCoeff))
+	fy := totalThrust.Mul(cos).AddC(-d.Mass * d.Gravity).Sub(vy.Scale(d.DragCoeff))
+
+	ax := fx.Scale(1 / d.Mass)
+	ay := fy.Scale(1 / d.Mass)
+	alpha := torque.Sub(omega.Scale(d.AngDrag)).Scale(1 / d.Inertia)
+
+	return []autodiff.Dual{vx, vy, omega, ax, ay, alpha}
+}
+
 func (d *Drone) HoverThrust() float64 {
 	return d.Mass * d.Gravity / 2.0
 }
@@ -66,6 +106,26 @@ func (d *Drone) Energy(x dynamo.State) float64 {
 	return ke + keRot + pe
 }
 
+// Diffusion implements dynamo.Stochastic: independent white-noise gusts push
+// the airframe horizontally and vertically.
+func (d *Drone) Diffusion(_ dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
+	g := d.Wind / d.Mass
+	return dynamo.State{0, 0, 0, g, g, 0}
+}
+
+var droneParams = []dynamo.Param{
+	{Name: "mass", Default: DefaultMass, Min: 1e-3, Max: 100, Unit: "kg", Description: "airframe mass"},
+	{Name: "gravity", Default: DefaultGravity, Min: 0, Max: 100, Unit: "m/s^2", Description: "gravitational acceleration"},
+	{Name: "drag", Default: 0.1, Min: 0, Max: 10, Unit: "kg/s", Description: "linear drag coefficient"},
+	{Name: "ang_drag", Default: 0.05, Min: 0, Max: 10, Unit: "N·m·s", Description: "angular drag coefficient"},
+	{Name: "arm_length", Default: 0.25, Min: 1e-3, Max: 10, Unit: "m", Description: "rotor arm length"},
+	{Name: "inertia", Default: 0.1, Min: 1e-4, Max: 100, Unit: "kg·m^2", Description: "moment of inertia"},
+	{Name: "wind", Default: 0, Min: 0, Max: 100, Unit: "N·s^0.5", Description: "gust force intensity"},
+}
+
+// Params implements dynamo.Parameterized.
+func (d *Drone) Params() []dynamo.Param { return droneParams }
+
 func (d *Drone) GetParams() map[string]float64 {
 	return map[string]float64{
 		"mass":       d.Mass,
@@ -74,10 +134,14 @@ func (d *Drone) GetParams() map[string]float64 {
 		"ang_drag":   d.AngDrag,
 		"arm_length": d.ArmLength,
 		"inertia":    d.Inertia,
+		"wind":       d.Wind,
 	}
 }
 
 func (d *Drone) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(droneParams, name, value); err != nil {
+		return err
+	}
 	switch name {
 	case "mass":
 		d.Mass = value
@@ -91,8 +155,8 @@ func (d *Drone) SetParam(name string, value float64) error {
 		d.ArmLength = value
 	case "inertia":
 		d.Inertia = value
-	default:
-		return fmt.Errorf("unknown param: %s", name)
+	case "wind":
+		d.Wind = value
 	}
 	return nil
 }
@@ -0,0 +1,11 @@
+package physics
+
+import "github.com/san-kum/dynsim/internal/autodiff"
+
+// dualInput returns u[i], or zero for missing inputs, as Derive does.
+func dualInput(u []autodiff.Dual, i int) autodiff.Dual {
+	if i < len(u) {
+		return u[i]
+	}
+	return autodiff.Dual{}
+}
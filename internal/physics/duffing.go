@@ -18,6 +18,12 @@ func NewDuffing() *Duffing {
 func (d *Duffing) StateDim() int   { return 3 }
 func (d *Duffing) ControlDim() int { return 0 }
 
+func (d *Duffing) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "x"}, {Name: "v"}, {Name: "phi", Unit: "rad"}}
+}
+
+func (d *Duffing) ControlVars() []dynamo.Variable { return nil }
+
 func (d *Duffing) Derive(s dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	if len(s) < 3 {
 		return make(dynamo.State, 3)
@@ -43,22 +49,42 @@ func (d *Duffing) LyapunovExponent() float64 {
 	return 0.0
 }
 
+var duffingParams = []dynamo.Param{
+	{Name: "alpha", Default: -1.0, Min: -100, Max: 100, Description: "linear stiffness"},
+	{Name: "beta", Default: 1.0, Min: -100, Max: 100, Description: "cubic stiffness"},
+	{Name: "delta", Default: 0.3, Min: 0, Max: 10, Description: "damping"},
+	{Name: "gamma", Default: 0.5, Min: 0, Max: 100, Description: "forcing amplitude"},
+	{Name: "omega", Default: 1.2, Min: 0, Max: 100, Unit: "rad/s", Description: "forcing frequency"},
+}
+
+// Params implements dynamo.Parameterized.
+func (d *Duffing) Params() []dynamo.Param { return duffingParams }
+
 func (d *Duffing) GetParams() map[string]float64 {
-	return map[string]float64{"alpha": d.Alpha, "beta": d.Beta, "delta": d.Delta, "gamma": d.Gamma, "omega": d.Omega}
+	return map[string]float64{
+		"alpha": d.Alpha,
+		"beta":  d.Beta,
+		"delta": d.Delta,
+		"gamma": d.Gamma,
+		"omega": d.Omega,
+	}
 }
 
-func (d *Duffing) SetParam(n string, v float64) error {
-	switch n {
+func (d *Duffing) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(duffingParams, name, value); err != nil {
+		return err
+	}
+	switch name {
 	case "alpha":
-		d.Alpha = v
+		d.Alpha = value
 	case "beta":
-		d.Beta = v
+		d.Beta = value
 	case "delta":
-		d.Delta = v
+		d.Delta = value
 	case "gamma":
-		d.Gamma = v
+		d.Gamma = value
 	case "omega":
-		d.Omega = v
+		d.Omega = value
 	}
 	return nil
 }
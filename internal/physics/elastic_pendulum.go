@@ -0,0 +1,40 @@
+package physics
+
+import (
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/lagrange"
+)
+
+// ElasticPendulum is a bob on a spring swinging in a plane. Its equations of
+// motion are derived by package lagrange from the energies below, with r
+// the spring length and theta the angle from the downward vertical.
+type ElasticPendulum struct {
+	*lagrange.Model
+}
+
+var elasticPendulum = lagrange.MustNew(lagrange.System{
+	Coords: []lagrange.Coord{
+		{Name: "r", Velocity: "vr", Unit: "m"},
+		{Name: "theta", Velocity: "omega", Unit: "rad"},
+	},
+	Controls: []dynamo.Variable{{Name: "force", Unit: "N"}},
+	Params: []dynamo.Param{
+		{Name: "mass", Default: 1.0, Min: 1e-3, Max: 100, Unit: "kg", Description: "bob mass"},
+		{Name: "stiffness", Default: 40, Min: 1e-3, Max: 1e4, Unit: "N/m", Description: "spring constant"},
+		{Name: "rest_length", Default: 1.0, Min: 1e-3, Max: 100, Unit: "m", Description: "unstretched spring length"},
+		{Name: "damping", Default: 0, Min: 0, Max: 10, Unit: "kg/s", Description: "viscous damping on the bob"},
+		{Name: "gravity", Default: 9.81, Min: 0, Max: 100, Unit: "m/s^2", Description: "gravitational acceleration"},
+	},
+	Kinetic:   "0.5*mass*(vr^2 + r^2*omega^2)",
+	Potential: "0.5*stiffness*(r - rest_length)^2 - mass*gravity*r*cos(theta)",
+	Forces: map[string]string{
+		"r":     "force - damping*vr",
+		"theta": "-damping*r^2*omega",
+	},
+})
+
+func NewElasticPendulum() *ElasticPendulum {
+	return &ElasticPendulum{elasticPendulum.Clone()}
+}
+
+func (e *ElasticPendulum) DefaultState() dynamo.State { return dynamo.State{1.3, 0.6, 0, 0} }
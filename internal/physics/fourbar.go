@@ -0,0 +1,173 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+	"github.com/san-kum/dynsim/internal/mechanics"
+)
+
+// FourBar is a closed four-bar linkage: a crank of length L1 pivoting at
+// the origin, a rocker of length L3 pivoting at (L0, 0) and a coupler of
+// length L2 joining their free ends B and C. The link masses are lumped at
+// B and C, whose positions are the coordinates; the three rod lengths are
+// holonomic constraints. The control is a motor torque on the crank.
+type FourBar struct {
+	*mechanics.Constrained
+
+	L0, L1, L2, L3 float64
+	MassB, MassC   float64
+	Gravity        float64
+	Damping        float64
+}
+
+func NewFourBar() *FourBar {
+	f := &FourBar{
+		L0: 4, L1: 1, L2: 3.5, L3: 3,
+		MassB: 1, MassC: 1,
+		Gravity: 9.81,
+		Damping: 0.05,
+	}
+	f.Constrained = mechanics.NewConstrained(f)
+	return f
+}
+
+// Coords implements mechanics.Mechanism: q = (bx, by, cx, cy).
+func (f *FourBar) Coords() int     { return 4 }
+func (f *FourBar) ControlDim() int { return 1 }
+
+func (f *FourBar) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{
+		{Name: "bx", Unit: "m"}, {Name: "by", Unit: "m"}, {Name: "cx", Unit: "m"}, {Name: "cy", Unit: "m"},
+		{Name: "vbx", Unit: "m/s"}, {Name: "vby", Unit: "m/s"}, {Name: "vcx", Unit: "m/s"}, {Name: "vcy", Unit: "m/s"},
+	}
+}
+
+func (f *FourBar) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "torque", Unit: "N·m"}}
+}
+
+// Mass implements mechanics.Mechanism.
+func (f *FourBar) Mass(_ []float64) linalg.Matrix {
+	m := linalg.New(4, 4)
+	m[0][0], m[1][1] = f.MassB, f.MassB
+	m[2][2], m[3][3] = f.MassC, f.MassC
+	return m
+}
+
+// Forces implements mechanics.Mechanism: gravity, joint damping and the
+// crank torque, applied at B perpendicular to the crank.
+func (f *FourBar) Forces(q, v []float64, u dynamo.Control, _ float64) []float64 {
+	forces := []float64{
+		-f.Damping * v[0],
+		-f.MassB*f.Gravity - f.Damping*v[1],
+		-f.Damping * v[2],
+		-f.MassC*f.Gravity - f.Damping*v[3],
+	}
+	if len(u) > 0 {
+		k := u[0] / (f.L1 * f.L1)
+		forces[0] -= k * q[1]
+		forces[1] += k * q[0]
+	}
+	return forces
+}
+
+// Constraints implements mechanics.Mechanism.
+func (f *FourBar) Constraints(q []float64) []float64 {
+	bx, by, cx, cy := q[0], q[1], q[2], q[3]
+	return []float64{
+		0.5 * (bx*bx + by*by - f.L1*f.L1),
+		0.5 * ((cx-bx)*(cx-bx) + (cy-by)*(cy-by) - f.L2*f.L2),
+		0.5 * ((cx-f.L0)*(cx-f.L0) + cy*cy - f.L3*f.L3),
+	}
+}
+
+// ConstraintJacobian implements mechanics.Mechanism.
+func (f *FourBar) ConstraintJacobian(q []float64) linalg.Matrix {
+	bx, by, cx, cy := q[0], q[1], q[2], q[3]
+	return linalg.Matrix{
+		{bx, by, 0, 0},
+		{bx - cx, by - cy, cx - bx, cy - by},
+		{0, 0, cx - f.L0, cy},
+	}
+}
+
+// DefaultState puts the crank upright with the linkage at rest.
+func (f *FourBar) DefaultState() dynamo.State {
+	return f.Assemble(math.Pi / 2)
+}
+
+// Assemble returns the configuration at rest with the crank at angle theta,
+// choosing the elbow-up closure of the coupler and rocker.
+func (f *FourBar) Assemble(theta float64) dynamo.State {
+	bx, by := f.L1*math.Cos(theta), f.L1*math.Sin(theta)
+	dx, dy := f.L0-bx, -by
+	d := math.Hypot(dx, dy)
+	a := (f.L2*f.L2 - f.L3*f.L3 + d*d) / (2 * d)
+	h := math.Sqrt(math.Max(0, f.L2*f.L2-a*a))
+	mx, my := bx+a*dx/d, by+a*dy/d
+	cx, cy := mx-h*dy/d, my+h*dx/d
+	return dynamo.State{bx, by, cx, cy, 0, 0, 0, 0}
+}
+
+func (f *FourBar) Energy(x dynamo.State) float64 {
+	ke := 0.5*f.MassB*(x[4]*x[4]+x[5]*x[5]) + 0.5*f.MassC*(x[6]*x[6]+x[7]*x[7])
+	return ke + f.Gravity*(f.MassB*x[1]+f.MassC*x[3])
+}
+
+var fourBarParams = []dynamo.Param{
+	{Name: "ground", Default: 4, Min: 1e-3, Max: 100, Unit: "m", Description: "distance between the fixed pivots"},
+	{Name: "crank", Default: 1, Min: 1e-3, Max: 100, Unit: "m", Description: "crank length"},
+	{Name: "coupler", Default: 3.5, Min: 1e-3, Max: 100, Unit: "m", Description: "coupler length"},
+	{Name: "rocker", Default: 3, Min: 1e-3, Max: 100, Unit: "m", Description: "rocker length"},
+	{Name: "mass_b", Default: 1, Min: 1e-3, Max: 100, Unit: "kg", Description: "mass at the crank joint"},
+	{Name: "mass_c", Default: 1, Min: 1e-3, Max: 100, Unit: "kg", Description: "mass at the rocker joint"},
+	{Name: "gravity", Default: 9.81, Min: 0, Max: 100, Unit: "m/s²", Description: "gravitational acceleration"},
+	{Name: "damping", Default: 0.05, Min: 0, Max: 10, Unit: "kg/s", Description: "joint damping"},
+	{Name: "baumgarte", Default: mechanics.DefaultAlpha, Min: 0, Max: 1000, Unit: "1/s", Description: "constraint stabilization gain"},
+}
+
+// Params implements dynamo.Parameterized.
+func (f *FourBar) Params() []dynamo.Param { return fourBarParams }
+
+func (f *FourBar) GetParams() map[string]float64 {
+	return map[string]float64{
+		"ground":    f.L0,
+		"crank":     f.L1,
+		"coupler":   f.L2,
+		"rocker":    f.L3,
+		"mass_b":    f.MassB,
+		"mass_c":    f.MassC,
+		"gravity":   f.Gravity,
+		"damping":   f.Damping,
+		"baumgarte": f.Alpha,
+	}
+}
+
+func (f *FourBar) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(fourBarParams, name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "ground":
+		f.L0 = value
+	case "crank":
+		f.L1 = value
+	case "coupler":
+		f.L2 = value
+	case "rocker":
+		f.L3 = value
+	case "mass_b":
+		f.MassB = value
+	case "mass_c":
+		f.MassC = value
+	case "gravity":
+		f.Gravity = value
+	case "damping":
+		f.Damping = value
+	case "baumgarte":
+		f.Alpha, f.Beta = value, value
+	}
+	return nil
+}
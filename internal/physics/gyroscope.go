@@ -18,6 +18,12 @@ func NewGyroscope() *Gyroscope {
 func (g *Gyroscope) StateDim() int   { return 6 }
 func (g *Gyroscope) ControlDim() int { return 0 }
 
+func (g *Gyroscope) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "w1", Unit: "rad/s"}, {Name: "w2", Unit: "rad/s"}, {Name: "w3", Unit: "rad/s"}, {Name: "theta", Unit: "rad"}, {Name: "phi", Unit: "rad"}, {Name: "psi", Unit: "rad"}}
+}
+
+func (g *Gyroscope) ControlVars() []dynamo.Variable { return nil }
+
 // Derive computes the derivatives for angular velocity and Euler angles.
 func (g *Gyroscope) Derive(s dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	if len(s) < 6 {
@@ -45,24 +51,46 @@ func (g *Gyroscope) Energy(s dynamo.State) float64 {
 	return 0.5*(g.I1*w1*w1+g.I2*w2*w2+g.I3*w3*w3) + g.Mass*g.Gravity*g.Length*math.Cos(th)
 }
 
+var gyroscopeParams = []dynamo.Param{
+	{Name: "I1", Default: 1.0, Min: 1e-3, Max: 100, Unit: "kg·m^2", Description: "transverse moment of inertia"},
+	{Name: "I2", Default: 1.0, Min: 1e-3, Max: 100, Unit: "kg·m^2", Description: "transverse moment of inertia"},
+	{Name: "I3", Default: 2.0, Min: 1e-3, Max: 100, Unit: "kg·m^2", Description: "spin-axis moment of inertia"},
+	{Name: "gravity", Default: 9.81, Min: 0, Max: 100, Unit: "m/s^2", Description: "gravitational acceleration"},
+	{Name: "mass", Default: 1.0, Min: 1e-3, Max: 100, Unit: "kg", Description: "top mass"},
+	{Name: "length", Default: 0.5, Min: 0, Max: 10, Unit: "m", Description: "pivot to center of mass"},
+}
+
+// Params implements dynamo.Parameterized.
+func (g *Gyroscope) Params() []dynamo.Param { return gyroscopeParams }
+
 func (g *Gyroscope) GetParams() map[string]float64 {
-	return map[string]float64{"I1": g.I1, "I2": g.I2, "I3": g.I3, "gravity": g.Gravity, "mass": g.Mass, "length": g.Length}
+	return map[string]float64{
+		"I1":      g.I1,
+		"I2":      g.I2,
+		"I3":      g.I3,
+		"gravity": g.Gravity,
+		"mass":    g.Mass,
+		"length":  g.Length,
+	}
 }
 
-func (g *Gyroscope) SetParam(n string, v float64) error {
-	switch n {
+func (g *Gyroscope) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(gyroscopeParams, name, value); err != nil {
+		return err
+	}
+	switch name {
 	case "I1":
-		g.I1 = v
+		g.I1 = value
 	case "I2":
-		g.I2 = v
+		g.I2 = value
 	case "I3":
-		g.I3 = v
+		g.I3 = value
 	case "gravity":
-		g.Gravity = v
+		g.Gravity = value
 	case "mass":
-		g.Mass = v
+		g.Mass = value
 	case "length":
-		g.Length = v
+		g.Length = value
 	}
 	return nil
 }
@@ -34,6 +34,17 @@ func (h *Hybrid) ControlDim() int {
 	return 3
 }
 
+func (h *Hybrid) StateVars() []dynamo.Variable {
+	stars := prefixVars("star_", indexedVars(h.Stars.NumBodies, planarVars...))
+	gas := prefixVars("gas_", indexedVars(h.Gas.N, planarVars...))
+	return append(stars, gas...)
+}
+
+func (h *Hybrid) ControlVars() []dynamo.Variable { return cursorControls }
+
+// Split implements dynamo.Separable.
+func (h *Hybrid) Split() (pos, vel []int) { return bodySplit(h.Stars.NumBodies+h.Gas.N, 2) }
+
 func (h *Hybrid) DefaultState() dynamo.State {
 	// Initialize Stars using Galaxy Gen
 	starState := h.Stars.DefaultState()
@@ -272,3 +283,31 @@ func (h *Hybrid) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.Stat
 
 	return dt
 }
+
+// hybridParams combines the star gravity parameters with the gas SPH
+// parameters, minus the trailing SPH gravity: the gas falls toward the stars
+// instead.
+var hybridParams = append(append([]dynamo.Param{}, nbodyParams...), sphParams[:4]...)
+
+// Params implements dynamo.Parameterized.
+func (h *Hybrid) Params() []dynamo.Param { return hybridParams }
+
+func (h *Hybrid) GetParams() map[string]float64 {
+	params := h.Stars.GetParams()
+	for k, v := range h.Gas.GetParams() {
+		if _, ok := dynamo.LookupParam(hybridParams, k); ok {
+			params[k] = v
+		}
+	}
+	return params
+}
+
+func (h *Hybrid) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(hybridParams, name, value); err != nil {
+		return err
+	}
+	if _, ok := dynamo.LookupParam(nbodyParams, name); ok {
+		return h.Stars.SetParam(name, value)
+	}
+	return h.Gas.SetParam(name, value)
+}
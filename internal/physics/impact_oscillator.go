@@ -0,0 +1,134 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// ImpactOscillator is a harmonically forced, damped mass on a spring whose
+// travel is limited by a rigid stop at x = Gap. Impacts reverse the
+// velocity, scaled by the coefficient of restitution; an impact slower than
+// RestSpeed leaves the mass stuck to the stop until the forcing pulls it
+// away. Depending on the forcing the motion is periodic, grazing or
+// chaotic.
+type ImpactOscillator struct {
+	Mass, Stiffness, Damping float64
+	Amplitude, Frequency     float64
+	Gap, Restitution         float64
+	RestSpeed                float64
+}
+
+func NewImpactOscillator() *ImpactOscillator {
+	return &ImpactOscillator{
+		Mass:        1.0,
+		Stiffness:   1.0,
+		Damping:     0.05,
+		Amplitude:   0.8,
+		Frequency:   0.9,
+		Gap:         0.5,
+		Restitution: 0.8,
+		RestSpeed:   1e-3,
+	}
+}
+
+func (o *ImpactOscillator) StateDim() int   { return 2 }
+func (o *ImpactOscillator) ControlDim() int { return 1 }
+
+func (o *ImpactOscillator) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "x", Unit: "m"}, {Name: "v", Unit: "m/s"}}
+}
+
+func (o *ImpactOscillator) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "force", Unit: "N"}}
+}
+
+// Split implements dynamo.Separable.
+func (o *ImpactOscillator) Split() (pos, vel []int) { return bodySplit(1, 1) }
+
+func (o *ImpactOscillator) Derive(s dynamo.State, u dynamo.Control, t float64) dynamo.State {
+	x, v := s[0], s[1]
+	f := -o.Stiffness*x - o.Damping*v + o.Amplitude*math.Cos(o.Frequency*t)
+	if len(u) > 0 {
+		f += u[0]
+	}
+	// the stop holds a stuck mass while the net force presses it there
+	if x >= o.Gap && v == 0 && f > 0 {
+		f = 0
+	}
+	return dynamo.State{v, f / o.Mass}
+}
+
+// Guards implements dynamo.HybridSystem.
+func (o *ImpactOscillator) Guards() []dynamo.Event {
+	return []dynamo.Event{{
+		Name:      "impact",
+		Fn:        func(_ float64, s dynamo.State) float64 { return s[0] - o.Gap },
+		Direction: dynamo.CrossRising,
+		Reset: func(_ float64, s dynamo.State) dynamo.State {
+			v := -o.Restitution * s[1]
+			if math.Abs(v) < o.RestSpeed {
+				v = 0
+			}
+			return dynamo.State{o.Gap, v}
+		},
+	}}
+}
+
+func (o *ImpactOscillator) DefaultState() dynamo.State { return dynamo.State{0, 0} }
+
+func (o *ImpactOscillator) Energy(s dynamo.State) float64 {
+	return 0.5*o.Mass*s[1]*s[1] + 0.5*o.Stiffness*s[0]*s[0]
+}
+
+var impactOscillatorParams = []dynamo.Param{
+	{Name: "mass", Default: 1.0, Min: 1e-3, Max: 100, Unit: "kg", Description: "oscillator mass"},
+	{Name: "stiffness", Default: 1.0, Min: 0, Max: 1000, Unit: "N/m", Description: "spring constant"},
+	{Name: "damping", Default: 0.05, Min: 0, Max: 10, Unit: "kg/s", Description: "viscous damping"},
+	{Name: "amplitude", Default: 0.8, Min: 0, Max: 100, Unit: "N", Description: "forcing amplitude"},
+	{Name: "frequency", Default: 0.9, Min: 0, Max: 100, Unit: "rad/s", Description: "forcing frequency"},
+	{Name: "gap", Default: 0.5, Min: -10, Max: 10, Unit: "m", Description: "position of the stop"},
+	{Name: "restitution", Default: 0.8, Min: 0, Max: 1, Description: "fraction of speed kept per impact"},
+	{Name: "rest_speed", Default: 1e-3, Min: 0, Max: 10, Unit: "m/s", Description: "impact speed below which the mass sticks"},
+}
+
+// Params implements dynamo.Parameterized.
+func (o *ImpactOscillator) Params() []dynamo.Param { return impactOscillatorParams }
+
+func (o *ImpactOscillator) GetParams() map[string]float64 {
+	return map[string]float64{
+		"mass":        o.Mass,
+		"stiffness":   o.Stiffness,
+		"damping":     o.Damping,
+		"amplitude":   o.Amplitude,
+		"frequency":   o.Frequency,
+		"gap":         o.Gap,
+		"restitution": o.Restitution,
+		"rest_speed":  o.RestSpeed,
+	}
+}
+
+func (o *ImpactOscillator) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(impactOscillatorParams, name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "mass":
+		o.Mass = value
+	case "stiffness":
+		o.Stiffness = value
+	case "damping":
+		o.Damping = value
+	case "amplitude":
+		o.Amplitude = value
+	case "frequency":
+		o.Frequency = value
+	case "gap":
+		o.Gap = value
+	case "restitution":
+		o.Restitution = value
+	case "rest_speed":
+		o.RestSpeed = value
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package physics
 
-import "github.com/san-kum/dynsim/internal/dynamo"
+import (
+	"github.com/san-kum/dynsim/internal/autodiff"
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
 
 type Lorenz struct{ sigma, rho, beta float64 }
 
@@ -8,21 +11,55 @@ func NewLorenz() *Lorenz          { return &Lorenz{10.0, 28.0, 8.0 / 3.0} }
 func (l *Lorenz) StateDim() int   { return 3 }
 func (l *Lorenz) ControlDim() int { return 0 }
 
+func (l *Lorenz) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "x"}, {Name: "y"}, {Name: "z"}}
+}
+
+func (l *Lorenz) ControlVars() []dynamo.Variable { return nil }
+
 // Derive calculates the Lorenz attractor derivatives.
 func (l *Lorenz) Derive(s dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	return dynamo.State{l.sigma * (s[1] - s[0]), s[0]*(l.rho-s[2]) - s[1], s[0]*s[1] - l.beta*s[2]}
 }
 func (l *Lorenz) DefaultState() dynamo.State { return dynamo.State{1.0, 1.0, 1.0} }
+
+// DeriveDual implements dynamo.Differentiable.
+func (l *Lorenz) DeriveDual(s, _ []autodiff.Dual, _ float64) []autodiff.Dual {
+	return []autodiff.Dual{
+		s[1].Sub(s[0]).Scale(l.sigma),
+		s[0].Mul(s[2].Neg().AddC(l.rho)).Sub(s[1]),
+		s[0].Mul(s[1]).Sub(s[2].Scale(l.beta)),
+	}
+}
+
+var lorenzParams = []dynamo.Param{
+	{Name: "sigma", Default: 10.0, Min: 0, Max: 100, Description: "Prandtl number"},
+	{Name: "rho", Default: 28.0, Min: 0, Max: 500, Description: "Rayleigh number"},
+	{Name: "beta", Default: 8.0 / 3.0, Min: 0, Max: 50, Description: "geometric factor"},
+}
+
+// Params implements dynamo.Parameterized.
+func (l *Lorenz) Params() []dynamo.Param { return lorenzParams }
+
 func (l *Lorenz) GetParams() map[string]float64 {
-	return map[string]float64{"sigma": l.sigma, "rho": l.rho, "beta": l.beta}
+	return map[string]float64{
+		"sigma": l.sigma,
+		"rho":   l.rho,
+		"beta":  l.beta,
+	}
 }
-func (l *Lorenz) SetParam(n string, v float64) {
-	switch n {
+
+func (l *Lorenz) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(lorenzParams, name, value); err != nil {
+		return err
+	}
+	switch name {
 	case "sigma":
-		l.sigma = v
+		l.sigma = value
 	case "rho":
-		l.rho = v
+		l.rho = value
 	case "beta":
-		l.beta = v
+		l.beta = value
 	}
+	return nil
 }
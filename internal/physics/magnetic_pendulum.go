@@ -29,6 +29,15 @@ func NewMagneticPendulum() *MagneticPendulum {
 func (m *MagneticPendulum) StateDim() int   { return 4 }
 func (m *MagneticPendulum) ControlDim() int { return 0 }
 
+func (m *MagneticPendulum) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "x"}, {Name: "y"}, {Name: "vx"}, {Name: "vy"}}
+}
+
+func (m *MagneticPendulum) ControlVars() []dynamo.Variable { return nil }
+
+// Split implements dynamo.Separable.
+func (m *MagneticPendulum) Split() (pos, vel []int) { return bodySplit(1, 2) }
+
 func (m *MagneticPendulum) Derive(s dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	if len(s) < 4 {
 		return make(dynamo.State, 4)
@@ -81,20 +90,38 @@ func (m *MagneticPendulum) ClosestMagnet(s dynamo.State) int {
 	return c
 }
 
+var magneticPendulumParams = []dynamo.Param{
+	{Name: "height", Default: 0.5, Min: 1e-3, Max: 10, Description: "bob height above the magnet plane"},
+	{Name: "damping", Default: 0.2, Min: 0, Max: 10, Description: "friction"},
+	{Name: "gravity", Default: 0.5, Min: 0, Max: 100, Description: "restoring pull toward the center"},
+	{Name: "magnetPower", Default: 3.0, Min: 0, Max: 100, Description: "magnet strength"},
+}
+
+// Params implements dynamo.Parameterized.
+func (m *MagneticPendulum) Params() []dynamo.Param { return magneticPendulumParams }
+
 func (m *MagneticPendulum) GetParams() map[string]float64 {
-	return map[string]float64{"height": m.Height, "damping": m.Damping, "gravity": m.Gravity, "magnetPower": m.MagnetPower}
+	return map[string]float64{
+		"height":      m.Height,
+		"damping":     m.Damping,
+		"gravity":     m.Gravity,
+		"magnetPower": m.MagnetPower,
+	}
 }
 
-func (m *MagneticPendulum) SetParam(n string, v float64) error {
-	switch n {
+func (m *MagneticPendulum) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(magneticPendulumParams, name, value); err != nil {
+		return err
+	}
+	switch name {
 	case "height":
-		m.Height = v
+		m.Height = value
 	case "damping":
-		m.Damping = v
+		m.Damping = value
 	case "gravity":
-		m.Gravity = v
+		m.Gravity = value
 	case "magnetPower":
-		m.MagnetPower = v
+		m.MagnetPower = value
 	}
 	return nil
 }
@@ -24,6 +24,15 @@ func NewMassChain(n int) *MassChain {
 func (mc *MassChain) StateDim() int   { return mc.n * 2 }
 func (mc *MassChain) ControlDim() int { return 0 }
 
+func (mc *MassChain) StateVars() []dynamo.Variable {
+	return indexedVars(mc.n, dynamo.Variable{Name: "x", Unit: "m"}, dynamo.Variable{Name: "v", Unit: "m/s"})
+}
+
+func (mc *MassChain) ControlVars() []dynamo.Variable { return nil }
+
+// Split implements dynamo.Separable.
+func (mc *MassChain) Split() (pos, vel []int) { return bodySplit(mc.n, 1) }
+
 func (mc *MassChain) Derive(state dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	deriv := make(dynamo.State, mc.n*2)
 
@@ -63,6 +72,28 @@ func (mc *MassChain) Derive(state dynamo.State, _ dynamo.Control, _ float64) dyn
 	return deriv
 }
 
+// Jacobian implements dynamo.Jacobian. The chain is linear, so it does not
+// depend on the state.
+func (mc *MassChain) Jacobian(_ dynamo.State, _ dynamo.Control, _ float64) [][]float64 {
+	n := mc.n * 2
+	jac := make([][]float64, n)
+	for i := range jac {
+		jac[i] = make([]float64, n)
+	}
+	for i := 0; i < mc.n; i++ {
+		jac[i*2][i*2+1] = 1
+		jac[i*2+1][i*2] = -2 * mc.k / mc.m
+		if i > 0 {
+			jac[i*2+1][(i-1)*2] = mc.k / mc.m
+		}
+		if i < mc.n-1 {
+			jac[i*2+1][(i+1)*2] = mc.k / mc.m
+		}
+		jac[i*2+1][i*2+1] = -mc.damping / mc.m
+	}
+	return jac
+}
+
 func (mc *MassChain) DefaultState() dynamo.State {
 	state := make(dynamo.State, mc.n*2)
 	// Initial pulse - displace first few masses
@@ -75,7 +106,14 @@ func (mc *MassChain) DefaultState() dynamo.State {
 	return state
 }
 
-// GetParams implements dynamo.Configurable
+var massChainParams = []dynamo.Param{
+	{Name: "k", Default: 100.0, Min: 0, Max: 1e4, Unit: "N/m", Description: "spring constant"},
+	{Name: "damping", Default: 0.1, Min: 0, Max: 100, Unit: "kg/s", Description: "viscous damping"},
+}
+
+// Params implements dynamo.Parameterized.
+func (mc *MassChain) Params() []dynamo.Param { return massChainParams }
+
 func (mc *MassChain) GetParams() map[string]float64 {
 	return map[string]float64{
 		"k":       mc.k,
@@ -83,12 +121,15 @@ func (mc *MassChain) GetParams() map[string]float64 {
 	}
 }
 
-// SetParam implements dynamo.Configurable
-func (mc *MassChain) SetParam(name string, value float64) {
+func (mc *MassChain) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(massChainParams, name, value); err != nil {
+		return err
+	}
 	switch name {
 	case "k":
 		mc.k = value
 	case "damping":
 		mc.damping = value
 	}
+	return nil
 }
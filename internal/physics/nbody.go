@@ -147,6 +147,20 @@ func (nb *NBody) DefaultState() dynamo.State {
 	return state
 }
 
+// RingState places the bodies on a unit circle with tangential velocities.
+func (nb *NBody) RingState() dynamo.State {
+	n := nb.NumBodies
+	state := make(dynamo.State, n*4)
+	for i := 0; i < n; i++ {
+		angle := float64(i) * 2.0 * math.Pi / float64(n)
+		state[i*4] = math.Cos(angle)
+		state[i*4+1] = math.Sin(angle)
+		state[i*4+2] = -math.Sin(angle) * 0.5
+		state[i*4+3] = math.Cos(angle) * 0.5
+	}
+	return state
+}
+
 func (nb *NBody) StateDim() int   { return nb.NumBodies * 4 }
 func (nb *NBody) ControlDim() int { return 3 } // [CursorX, CursorY, Strength]
 
@@ -198,6 +212,15 @@ func (nb *NBody) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.Stat
 	return dx
 }
 
+func (nb *NBody) StateVars() []dynamo.Variable {
+	return indexedVars(nb.NumBodies, planarVars...)
+}
+
+func (nb *NBody) ControlVars() []dynamo.Variable { return cursorControls }
+
+// Split implements dynamo.Separable.
+func (nb *NBody) Split() (pos, vel []int) { return bodySplit(nb.NumBodies, 2) }
+
 func (nb *NBody) computeForcesCPU(x dynamo.State) ([]float64, []float64) {
 	n := nb.NumBodies
 	ax := make([]float64, n)
@@ -285,3 +308,31 @@ func (nb *NBody) AngularMomentum(x dynamo.State) float64 {
 	}
 	return L
 }
+
+var nbodyParams = []dynamo.Param{
+	{Name: "G", Default: 1.0, Min: 0, Max: 1e3, Description: "gravitational constant"},
+	{Name: "softening", Default: 0.01, Min: 1e-6, Max: 10, Description: "force softening length"},
+}
+
+// Params implements dynamo.Parameterized.
+func (nb *NBody) Params() []dynamo.Param { return nbodyParams }
+
+func (nb *NBody) GetParams() map[string]float64 {
+	return map[string]float64{
+		"G":         nb.G,
+		"softening": nb.Softening,
+	}
+}
+
+func (nb *NBody) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(nbodyParams, name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "G":
+		nb.G = value
+	case "softening":
+		nb.Softening = value
+	}
+	return nil
+}
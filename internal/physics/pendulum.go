@@ -1,9 +1,9 @@
 package physics
 
 import (
-	"fmt"
 	"math"
 
+	"github.com/san-kum/dynsim/internal/autodiff"
 	"github.com/san-kum/dynsim/internal/dynamo"
 )
 
@@ -31,6 +31,17 @@ func (p *Pendulum) ControlDim() int {
 	return 1
 }
 
+func (p *Pendulum) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "theta", Unit: "rad"}, {Name: "omega", Unit: "rad/s"}}
+}
+
+func (p *Pendulum) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "torque", Unit: "N·m"}}
+}
+
+// Split implements dynamo.Separable.
+func (p *Pendulum) Split() (pos, vel []int) { return bodySplit(1, 1) }
+
 func (p *Pendulum) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.State {
 	theta := x[0]
 	omega := x[1]
@@ -44,6 +55,15 @@ func (p *Pendulum) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.St
 	return dynamo.State{omega, alpha}
 }
 
+// DeriveDual implements dynamo.Differentiable.
+func (p *Pendulum) DeriveDual(x, u []autodiff.Dual, _ float64) []autodiff.Dual {
+	theta, omega := x[0], x[1]
+	torque := dualInput(u, 0)
+	alpha := omega.Scale(-p.Damping).Sub(autodiff.Sin(theta).Scale(p.Mass * p.Gravity * p.Length)).Add(torque).
+		Scale(1 / (p.Mass * p.Length * p.Length))
+	return []autodiff.Dual{omega, alpha}
+}
+
 func (p *Pendulum) Energy(x dynamo.State) float64 {
 	// KE = 0.5 * m * (L*omega)^2
 	// PE = m * g * L * (1 - cos(theta))
@@ -53,6 +73,16 @@ func (p *Pendulum) Energy(x dynamo.State) float64 {
 	return ke + pe
 }
 
+var pendulumParams = []dynamo.Param{
+	{Name: "mass", Default: 1.0, Min: 1e-3, Max: 100, Unit: "kg", Description: "bob mass"},
+	{Name: "length", Default: 1.0, Min: 1e-3, Max: 100, Unit: "m", Description: "rod length"},
+	{Name: "damping", Default: 0.1, Min: 0, Max: 10, Unit: "1/s", Description: "viscous damping"},
+	{Name: "gravity", Default: 9.81, Min: 0, Max: 100, Unit: "m/s^2", Description: "gravitational acceleration"},
+}
+
+// Params implements dynamo.Parameterized.
+func (p *Pendulum) Params() []dynamo.Param { return pendulumParams }
+
 func (p *Pendulum) GetParams() map[string]float64 {
 	return map[string]float64{
 		"mass":    p.Mass,
@@ -63,6 +93,9 @@ func (p *Pendulum) GetParams() map[string]float64 {
 }
 
 func (p *Pendulum) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(pendulumParams, name, value); err != nil {
+		return err
+	}
 	switch name {
 	case "mass":
 		p.Mass = value
@@ -72,8 +105,6 @@ func (p *Pendulum) SetParam(name string, value float64) error {
 		p.Damping = value
 	case "gravity":
 		p.Gravity = value
-	default:
-		return fmt.Errorf("unknown param: %s", name)
 	}
 	return nil
 }
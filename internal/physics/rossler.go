@@ -8,21 +8,46 @@ func NewRossler() *Rossler         { return &Rossler{0.2, 0.2, 5.7} }
 func (r *Rossler) StateDim() int   { return 3 }
 func (r *Rossler) ControlDim() int { return 0 }
 
+func (r *Rossler) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "x"}, {Name: "y"}, {Name: "z"}}
+}
+
+func (r *Rossler) ControlVars() []dynamo.Variable { return nil }
+
 // Derive calculates the Rossler attractor derivatives.
 func (r *Rossler) Derive(s dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	return dynamo.State{-s[1] - s[2], s[0] + r.a*s[1], r.b + s[2]*(s[0]-r.c)}
 }
 func (r *Rossler) DefaultState() dynamo.State { return dynamo.State{1.0, 1.0, 1.0} }
+
+var rosslerParams = []dynamo.Param{
+	{Name: "a", Default: 0.2, Min: -10, Max: 10, Description: "y feedback"},
+	{Name: "b", Default: 0.2, Min: -10, Max: 10, Description: "z offset"},
+	{Name: "c", Default: 5.7, Min: 0, Max: 100, Description: "z relaxation"},
+}
+
+// Params implements dynamo.Parameterized.
+func (r *Rossler) Params() []dynamo.Param { return rosslerParams }
+
 func (r *Rossler) GetParams() map[string]float64 {
-	return map[string]float64{"a": r.a, "b": r.b, "c": r.c}
+	return map[string]float64{
+		"a": r.a,
+		"b": r.b,
+		"c": r.c,
+	}
 }
-func (r *Rossler) SetParam(n string, v float64) {
-	switch n {
+
+func (r *Rossler) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(rosslerParams, name, value); err != nil {
+		return err
+	}
+	switch name {
 	case "a":
-		r.a = v
+		r.a = value
 	case "b":
-		r.b = v
+		r.b = value
 	case "c":
-		r.c = v
+		r.c = value
 	}
+	return nil
 }
@@ -38,6 +38,15 @@ func poly6(r2, h2 float64) float64 {
 	return 315.0 / (64.0 * math.Pi * math.Pow(h2, 4.5)) * math.Pow(h2-r2, 3)
 }
 
+func (s *SPH) StateVars() []dynamo.Variable {
+	return indexedVars(s.N, planarVars...)
+}
+
+func (s *SPH) ControlVars() []dynamo.Variable { return cursorControls }
+
+// Split implements dynamo.Separable.
+func (s *SPH) Split() (pos, vel []int) { return bodySplit(s.N, 2) }
+
 func spikyGrad(r, h float64) float64 {
 	if r > h || r < 1e-6 {
 		return 0
@@ -151,22 +160,42 @@ func (s *SPH) DefaultState() dynamo.State {
 	return st
 }
 
+var sphParams = []dynamo.Param{
+	{Name: "h", Default: 2.0, Min: 1e-2, Max: 20, Description: "smoothing length"},
+	{Name: "rho0", Default: 1.0, Min: 1e-3, Max: 100, Description: "rest density"},
+	{Name: "stiffness", Default: 50.0, Min: 0, Max: 1e4, Description: "pressure stiffness"},
+	{Name: "viscosity", Default: 0.1, Min: 0, Max: 100, Description: "viscosity"},
+	{Name: "gravity", Default: 9.81, Min: 0, Max: 100, Description: "gravitational acceleration"},
+}
+
+// Params implements dynamo.Parameterized.
+func (s *SPH) Params() []dynamo.Param { return sphParams }
+
 func (s *SPH) GetParams() map[string]float64 {
-	return map[string]float64{"h": s.H, "rho0": s.Rho0, "stiffness": s.K, "viscosity": s.Mu, "gravity": s.Gravity}
+	return map[string]float64{
+		"h":         s.H,
+		"rho0":      s.Rho0,
+		"stiffness": s.K,
+		"viscosity": s.Mu,
+		"gravity":   s.Gravity,
+	}
 }
 
-func (s *SPH) SetParam(n string, v float64) error {
-	switch n {
+func (s *SPH) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(sphParams, name, value); err != nil {
+		return err
+	}
+	switch name {
 	case "h":
-		s.H = v
+		s.H = value
 	case "rho0":
-		s.Rho0 = v
+		s.Rho0 = value
 	case "stiffness":
-		s.K = v
+		s.K = value
 	case "viscosity":
-		s.Mu = v
+		s.Mu = value
 	case "gravity":
-		s.Gravity = v
+		s.Gravity = value
 	}
 	return nil
 }
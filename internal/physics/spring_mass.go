@@ -5,6 +5,7 @@ import "github.com/san-kum/dynsim/internal/dynamo"
 const (
 	DefaultStiffness = 10.0
 	DefaultDamping   = 0.5
+	chainDamping     = 0.2
 )
 
 type SpringMass struct {
@@ -31,7 +32,7 @@ func NewSpringMassChain(n int) *SpringMass {
 	for i := 0; i < n; i++ {
 		masses[i] = DefaultMass
 		stiffness[i] = DefaultStiffness
-		damping[i] = 0.2
+		damping[i] = chainDamping
 	}
 	stiffness[n] = DefaultStiffness
 
@@ -46,6 +47,20 @@ func NewSpringMassChain(n int) *SpringMass {
 func (s *SpringMass) StateDim() int   { return s.NumMasses * 2 }
 func (s *SpringMass) ControlDim() int { return 1 }
 
+func (s *SpringMass) StateVars() []dynamo.Variable {
+	if s.NumMasses == 1 {
+		return []dynamo.Variable{{Name: "x", Unit: "m"}, {Name: "v", Unit: "m/s"}}
+	}
+	return blockVars(s.NumMasses, dynamo.Variable{Name: "x", Unit: "m"}, dynamo.Variable{Name: "v", Unit: "m/s"})
+}
+
+func (s *SpringMass) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "force", Unit: "N"}}
+}
+
+// Split implements dynamo.Separable.
+func (s *SpringMass) Split() (pos, vel []int) { return bodySplit(1, s.NumMasses) }
+
 func (s *SpringMass) Derive(x dynamo.State, u dynamo.Control, t float64) dynamo.State {
 	n := s.NumMasses
 	dx := make(dynamo.State, n*2)
@@ -112,3 +127,44 @@ func (s *SpringMass) Energy(x dynamo.State) float64 {
 
 	return energy
 }
+
+// Params implements dynamo.Parameterized. Each parameter applies uniformly
+// to every mass, spring or damper in the chain.
+func (s *SpringMass) Params() []dynamo.Param {
+	damping := DefaultDamping
+	if s.NumMasses > 1 {
+		damping = chainDamping
+	}
+	return []dynamo.Param{
+		{Name: "mass", Default: DefaultMass, Min: 1e-3, Max: 100, Unit: "kg", Description: "mass of each body"},
+		{Name: "stiffness", Default: DefaultStiffness, Min: 0, Max: 1e4, Unit: "N/m", Description: "spring constant"},
+		{Name: "damping", Default: damping, Min: 0, Max: 100, Unit: "kg/s", Description: "viscous damping"},
+	}
+}
+
+func (s *SpringMass) GetParams() map[string]float64 {
+	return map[string]float64{
+		"mass":      s.Masses[0],
+		"stiffness": s.Stiffness[0],
+		"damping":   s.Damping[0],
+	}
+}
+
+func (s *SpringMass) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(s.Params(), name, value); err != nil {
+		return err
+	}
+	var values []float64
+	switch name {
+	case "mass":
+		values = s.Masses
+	case "stiffness":
+		values = s.Stiffness
+	case "damping":
+		values = s.Damping
+	}
+	for i := range values {
+		values[i] = value
+	}
+	return nil
+}
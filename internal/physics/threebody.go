@@ -28,6 +28,15 @@ func NewThreeBody() *ThreeBody {
 func (t *ThreeBody) StateDim() int   { return 12 }
 func (t *ThreeBody) ControlDim() int { return 0 }
 
+func (t *ThreeBody) StateVars() []dynamo.Variable {
+	return indexedVars(3, planarVars...)
+}
+
+func (t *ThreeBody) ControlVars() []dynamo.Variable { return nil }
+
+// Split implements dynamo.Separable.
+func (t *ThreeBody) Split() (pos, vel []int) { return bodySplit(3, 2) }
+
 func (t *ThreeBody) Derive(state dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	x1, y1, vx1, vy1 := state[0], state[1], state[2], state[3]
 	x2, y2, vx2, vy2 := state[4], state[5], state[6], state[7]
@@ -72,7 +81,16 @@ func (t *ThreeBody) DefaultState() dynamo.State {
 	}
 }
 
-// GetParams implements dynamo.Configurable
+var threeBodyParams = []dynamo.Param{
+	{Name: "m1", Default: 1.0, Min: 0, Max: 1e3, Description: "mass of body 1"},
+	{Name: "m2", Default: 1.0, Min: 0, Max: 1e3, Description: "mass of body 2"},
+	{Name: "m3", Default: 1.0, Min: 0, Max: 1e3, Description: "mass of body 3"},
+	{Name: "g", Default: 1.0, Min: 0, Max: 1e3, Description: "gravitational constant"},
+}
+
+// Params implements dynamo.Parameterized.
+func (t *ThreeBody) Params() []dynamo.Param { return threeBodyParams }
+
 func (t *ThreeBody) GetParams() map[string]float64 {
 	return map[string]float64{
 		"m1": t.m1,
@@ -82,8 +100,10 @@ func (t *ThreeBody) GetParams() map[string]float64 {
 	}
 }
 
-// SetParam implements dynamo.Configurable
-func (t *ThreeBody) SetParam(name string, value float64) {
+func (t *ThreeBody) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(threeBodyParams, name, value); err != nil {
+		return err
+	}
 	switch name {
 	case "m1":
 		t.m1 = value
@@ -94,4 +114,5 @@ func (t *ThreeBody) SetParam(name string, value float64) {
 	case "g":
 		t.g = value
 	}
+	return nil
 }
@@ -0,0 +1,126 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/linalg"
+	"github.com/san-kum/dynsim/internal/mechanics"
+)
+
+// TrackPendulum is a pendulum hung from a cart that slides freely along a
+// horizontal track. The coordinates are the cart position and the bob's
+// cartesian position, tied together by the rod length constraint. The
+// control is a horizontal force on the cart.
+type TrackPendulum struct {
+	*mechanics.Constrained
+
+	CartMass, BobMass float64
+	Length            float64
+	Gravity           float64
+	Friction          float64
+}
+
+func NewTrackPendulum() *TrackPendulum {
+	p := &TrackPendulum{CartMass: 1, BobMass: 0.5, Length: 1, Gravity: 9.81, Friction: 0.1}
+	p.Constrained = mechanics.NewConstrained(p)
+	return p
+}
+
+// Coords implements mechanics.Mechanism: q = (cart x, bob x, bob y).
+func (p *TrackPendulum) Coords() int     { return 3 }
+func (p *TrackPendulum) ControlDim() int { return 1 }
+
+func (p *TrackPendulum) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{
+		{Name: "cart_x", Unit: "m"}, {Name: "bob_x", Unit: "m"}, {Name: "bob_y", Unit: "m"},
+		{Name: "cart_v", Unit: "m/s"}, {Name: "bob_vx", Unit: "m/s"}, {Name: "bob_vy", Unit: "m/s"},
+	}
+}
+
+func (p *TrackPendulum) ControlVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "force", Unit: "N"}}
+}
+
+// Mass implements mechanics.Mechanism.
+func (p *TrackPendulum) Mass(_ []float64) linalg.Matrix {
+	m := linalg.New(3, 3)
+	m[0][0], m[1][1], m[2][2] = p.CartMass, p.BobMass, p.BobMass
+	return m
+}
+
+// Forces implements mechanics.Mechanism: track friction and the control on
+// the cart, gravity on the bob.
+func (p *TrackPendulum) Forces(_, v []float64, u dynamo.Control, _ float64) []float64 {
+	push := 0.0
+	if len(u) > 0 {
+		push = u[0]
+	}
+	return []float64{push - p.Friction*v[0], 0, -p.BobMass * p.Gravity}
+}
+
+// Constraints implements mechanics.Mechanism.
+func (p *TrackPendulum) Constraints(q []float64) []float64 {
+	dx, y := q[1]-q[0], q[2]
+	return []float64{0.5 * (dx*dx + y*y - p.Length*p.Length)}
+}
+
+// ConstraintJacobian implements mechanics.Mechanism.
+func (p *TrackPendulum) ConstraintJacobian(q []float64) linalg.Matrix {
+	dx, y := q[1]-q[0], q[2]
+	return linalg.Matrix{{-dx, dx, y}}
+}
+
+// DefaultState releases the bob from rest at 0.5 rad off vertical.
+func (p *TrackPendulum) DefaultState() dynamo.State {
+	return dynamo.State{0, p.Length * math.Sin(0.5), -p.Length * math.Cos(0.5), 0, 0, 0}
+}
+
+func (p *TrackPendulum) Energy(x dynamo.State) float64 {
+	ke := 0.5*p.CartMass*x[3]*x[3] + 0.5*p.BobMass*(x[4]*x[4]+x[5]*x[5])
+	return ke + p.BobMass*p.Gravity*x[2]
+}
+
+var trackPendulumParams = []dynamo.Param{
+	{Name: "cart_mass", Default: 1, Min: 1e-3, Max: 100, Unit: "kg", Description: "cart mass"},
+	{Name: "bob_mass", Default: 0.5, Min: 1e-3, Max: 100, Unit: "kg", Description: "bob mass"},
+	{Name: "length", Default: 1, Min: 1e-3, Max: 100, Unit: "m", Description: "rod length"},
+	{Name: "gravity", Default: 9.81, Min: 0, Max: 100, Unit: "m/s²", Description: "gravitational acceleration"},
+	{Name: "friction", Default: 0.1, Min: 0, Max: 10, Unit: "kg/s", Description: "track friction"},
+	{Name: "baumgarte", Default: mechanics.DefaultAlpha, Min: 0, Max: 1000, Unit: "1/s", Description: "constraint stabilization gain"},
+}
+
+// Params implements dynamo.Parameterized.
+func (p *TrackPendulum) Params() []dynamo.Param { return trackPendulumParams }
+
+func (p *TrackPendulum) GetParams() map[string]float64 {
+	return map[string]float64{
+		"cart_mass": p.CartMass,
+		"bob_mass":  p.BobMass,
+		"length":    p.Length,
+		"gravity":   p.Gravity,
+		"friction":  p.Friction,
+		"baumgarte": p.Alpha,
+	}
+}
+
+func (p *TrackPendulum) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(trackPendulumParams, name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "cart_mass":
+		p.CartMass = value
+	case "bob_mass":
+		p.BobMass = value
+	case "length":
+		p.Length = value
+	case "gravity":
+		p.Gravity = value
+	case "friction":
+		p.Friction = value
+	case "baumgarte":
+		p.Alpha, p.Beta = value, value
+	}
+	return nil
+}
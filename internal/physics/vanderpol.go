@@ -21,6 +21,12 @@ func NewVanDerPol() *VanDerPol {
 func (v *VanDerPol) StateDim() int   { return 2 }
 func (v *VanDerPol) ControlDim() int { return 0 }
 
+func (v *VanDerPol) StateVars() []dynamo.Variable {
+	return []dynamo.Variable{{Name: "x"}, {Name: "y"}}
+}
+
+func (v *VanDerPol) ControlVars() []dynamo.Variable { return nil }
+
 func (v *VanDerPol) Derive(state dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	x, y := state[0], state[1]
 
@@ -30,20 +36,39 @@ func (v *VanDerPol) Derive(state dynamo.State, _ dynamo.Control, _ float64) dyna
 	return dynamo.State{dx, dy}
 }
 
+// Jacobian implements dynamo.Jacobian.
+func (v *VanDerPol) Jacobian(state dynamo.State, _ dynamo.Control, _ float64) [][]float64 {
+	x, y := state[0], state[1]
+	return [][]float64{
+		{0, 1},
+		{-2*v.mu*x*y - 1, v.mu * (1 - x*x)},
+	}
+}
+
 func (v *VanDerPol) DefaultState() dynamo.State {
 	return dynamo.State{2.0, 0.0}
 }
 
-// GetParams implements dynamo.Configurable
+var vanDerPolParams = []dynamo.Param{
+	{Name: "mu", Default: 1.0, Min: 0, Max: 100, Description: "nonlinear damping strength"},
+}
+
+// Params implements dynamo.Parameterized.
+func (v *VanDerPol) Params() []dynamo.Param { return vanDerPolParams }
+
 func (v *VanDerPol) GetParams() map[string]float64 {
 	return map[string]float64{
 		"mu": v.mu,
 	}
 }
 
-// SetParam implements dynamo.Configurable
-func (v *VanDerPol) SetParam(name string, value float64) {
-	if name == "mu" {
+func (v *VanDerPol) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(vanDerPolParams, name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "mu":
 		v.mu = value
 	}
+	return nil
 }
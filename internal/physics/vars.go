@@ -0,0 +1,52 @@
+package physics
+
+import (
+	"fmt"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// planarVars is the per-particle layout of the 2D particle systems.
+var planarVars = []dynamo.Variable{{Name: "x"}, {Name: "y"}, {Name: "vx"}, {Name: "vy"}}
+
+// cursorControls is the control layout of the mouse-driven particle systems.
+var cursorControls = []dynamo.Variable{{Name: "cursor_x"}, {Name: "cursor_y"}, {Name: "strength"}}
+
+// indexedVars expands per-element variables, e.g. x1, y1, x2, y2, ...
+func indexedVars(n int, vars ...dynamo.Variable) []dynamo.Variable {
+	out := make([]dynamo.Variable, 0, n*len(vars))
+	for i := 1; i <= n; i++ {
+		for _, v := range vars {
+			out = append(out, dynamo.Variable{Name: fmt.Sprintf("%s%d", v.Name, i), Unit: v.Unit})
+		}
+	}
+	return out
+}
+
+// blockVars lays out variables block by block, e.g. x1, x2, ..., v1, v2, ...
+func blockVars(n int, vars ...dynamo.Variable) []dynamo.Variable {
+	out := make([]dynamo.Variable, 0, n*len(vars))
+	for _, v := range vars {
+		out = append(out, indexedVars(n, v)...)
+	}
+	return out
+}
+
+// bodySplit is the position/velocity split of n bodies, each laid out as dim
+// coordinates followed by their dim velocities.
+func bodySplit(n, dim int) (pos, vel []int) {
+	for b := 0; b < n; b++ {
+		for k := 0; k < dim; k++ {
+			pos = append(pos, b*2*dim+k)
+			vel = append(vel, b*2*dim+dim+k)
+		}
+	}
+	return pos, vel
+}
+
+func prefixVars(prefix string, vars []dynamo.Variable) []dynamo.Variable {
+	for i := range vars {
+		vars[i].Name = prefix + vars[i].Name
+	}
+	return vars
+}
@@ -18,6 +18,15 @@ func NewWave(n int) *Wave {
 func (w *Wave) StateDim() int   { return 2 * w.N }
 func (w *Wave) ControlDim() int { return 0 }
 
+func (w *Wave) StateVars() []dynamo.Variable {
+	return blockVars(w.N, dynamo.Variable{Name: "u", Unit: "m"}, dynamo.Variable{Name: "du", Unit: "m/s"})
+}
+
+func (w *Wave) ControlVars() []dynamo.Variable { return nil }
+
+// Split implements dynamo.Separable.
+func (w *Wave) Split() (pos, vel []int) { return bodySplit(1, w.N) }
+
 func (w *Wave) Derive(s dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
 	n := w.N
 	if len(s) < 2*n {
@@ -63,18 +72,34 @@ func (w *Wave) Energy(s dynamo.State) float64 {
 	return ke + pe
 }
 
+var waveParams = []dynamo.Param{
+	{Name: "length", Default: 1.0, Min: 1e-3, Max: 100, Unit: "m", Description: "string length"},
+	{Name: "waveSpeed", Default: 1.0, Min: 1e-3, Max: 100, Unit: "m/s", Description: "propagation speed"},
+	{Name: "damping", Default: 0.01, Min: 0, Max: 10, Unit: "1/s", Description: "viscous damping"},
+}
+
+// Params implements dynamo.Parameterized.
+func (w *Wave) Params() []dynamo.Param { return waveParams }
+
 func (w *Wave) GetParams() map[string]float64 {
-	return map[string]float64{"waveSpeed": w.WaveSpeed, "damping": w.Damping, "length": w.Length}
+	return map[string]float64{
+		"length":    w.Length,
+		"waveSpeed": w.WaveSpeed,
+		"damping":   w.Damping,
+	}
 }
 
-func (w *Wave) SetParam(n string, v float64) error {
-	switch n {
+func (w *Wave) SetParam(name string, value float64) error {
+	if err := dynamo.CheckParam(waveParams, name, value); err != nil {
+		return err
+	}
+	switch name {
+	case "length":
+		w.Length, w.dx = value, value/float64(w.N-1)
 	case "waveSpeed":
-		w.WaveSpeed = v
+		w.WaveSpeed = value
 	case "damping":
-		w.Damping = v
-	case "length":
-		w.Length, w.dx = v, v/float64(w.N-1)
+		w.Damping = value
 	}
 	return nil
 }
@@ -0,0 +1,48 @@
+package reference
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// LoadCSV reads a trajectory whose header is "time" followed by one column
+// per signal, and returns each column as waypoints keyed by its header.
+func LoadCSV(path string) (map[string]*Waypoints, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(rows) < 2 || len(rows[0]) < 2 || rows[0][0] != "time" {
+		return nil, fmt.Errorf("%s: want a time column, at least one signal column and one row", path)
+	}
+
+	header := rows[0]
+	cols := make([][]float64, len(header))
+	for r, row := range rows[1:] {
+		for c := range header {
+			v, err := strconv.ParseFloat(row[c], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: row %d, %s: %w", path, r+2, header[c], err)
+			}
+			cols[c] = append(cols[c], v)
+		}
+	}
+
+	signals := make(map[string]*Waypoints, len(header)-1)
+	for c, name := range header[1:] {
+		w, err := NewWaypoints(cols[0], cols[c+1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+		signals[name] = w
+	}
+	return signals, nil
+}
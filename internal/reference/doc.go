@@ -0,0 +1,9 @@
+// Package reference provides time-varying setpoints for controllers to
+// track: steps, ramps, sines, chirps and piecewise-linear waypoints, which
+// can also be loaded from CSV.
+//
+// Every signal implements [dynamo.Reference]:
+//
+//	ref := reference.Step{Time: 1, Initial: 5, Final: 8}
+//	err := ctrl.(dynamo.Tracker).Track(1, ref) // state 1 follows ref
+package reference
@@ -0,0 +1,112 @@
+package reference
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Constant holds a fixed value.
+type Constant float64
+
+func (c Constant) Value(float64) float64 { return float64(c) }
+
+// Step switches from Initial to Final at Time.
+type Step struct {
+	Time           float64
+	Initial, Final float64
+}
+
+func (s Step) Value(t float64) float64 {
+	if t < s.Time {
+		return s.Initial
+	}
+	return s.Final
+}
+
+// Ramp holds Initial until Start, then changes at Slope per second until
+// Stop, after which it holds again. A Stop at or before Start never stops.
+type Ramp struct {
+	Start, Stop float64
+	Initial     float64
+	Slope       float64
+}
+
+func (r Ramp) Value(t float64) float64 {
+	if r.Stop > r.Start {
+		t = math.Min(t, r.Stop)
+	}
+	if t < r.Start {
+		return r.Initial
+	}
+	return r.Initial + r.Slope*(t-r.Start)
+}
+
+// Sine is Offset + Amplitude·sin(2π·Frequency·t + Phase), with Frequency
+// in hertz.
+type Sine struct {
+	Amplitude float64
+	Frequency float64
+	Phase     float64
+	Offset    float64
+}
+
+func (s Sine) Value(t float64) float64 {
+	return s.Offset + s.Amplitude*math.Sin(2*math.Pi*s.Frequency*t+s.Phase)
+}
+
+// Chirp is a sine whose frequency sweeps linearly from F0 to F1 hertz over
+// Duration seconds and stays at F1 after that.
+type Chirp struct {
+	Amplitude float64
+	F0, F1    float64
+	Duration  float64
+	Offset    float64
+}
+
+func (c Chirp) Value(t float64) float64 {
+	var cycles float64
+	if t <= c.Duration || c.Duration <= 0 {
+		k := 0.0
+		if c.Duration > 0 {
+			k = (c.F1 - c.F0) / c.Duration
+		}
+		cycles = c.F0*t + 0.5*k*t*t
+	} else {
+		cycles = 0.5*(c.F0+c.F1)*c.Duration + c.F1*(t-c.Duration)
+	}
+	return c.Offset + c.Amplitude*math.Sin(2*math.Pi*cycles)
+}
+
+// Waypoints interpolates linearly between (Times[k], Values[k]) and holds
+// the first and last values outside them.
+type Waypoints struct {
+	Times  []float64
+	Values []float64
+}
+
+// NewWaypoints checks that times are increasing and match the values.
+func NewWaypoints(times, values []float64) (*Waypoints, error) {
+	if len(times) == 0 || len(times) != len(values) {
+		return nil, fmt.Errorf("waypoints: got %d times and %d values", len(times), len(values))
+	}
+	for k := 1; k < len(times); k++ {
+		if times[k] <= times[k-1] {
+			return nil, fmt.Errorf("waypoints: times must increase, got %g after %g", times[k], times[k-1])
+		}
+	}
+	return &Waypoints{Times: times, Values: values}, nil
+}
+
+func (w *Waypoints) Value(t float64) float64 {
+	n := len(w.Times)
+	if t <= w.Times[0] {
+		return w.Values[0]
+	}
+	if t >= w.Times[n-1] {
+		return w.Values[n-1]
+	}
+	k := sort.SearchFloat64s(w.Times, t)
+	t0, t1 := w.Times[k-1], w.Times[k]
+	return w.Values[k-1] + (w.Values[k]-w.Values[k-1])*(t-t0)/(t1-t0)
+}
@@ -19,6 +19,11 @@ type ExportData struct {
 	States     [][]float64        `json:"states"`
 	Controls   [][]float64        `json:"controls"`
 	Metrics    map[string]float64 `json:"metrics"`
+
+	StateNames   []string `json:"state_names,omitempty"`
+	StateUnits   []string `json:"state_units,omitempty"`
+	ControlNames []string `json:"control_names,omitempty"`
+	ControlUnits []string `json:"control_units,omitempty"`
 }
 
 func ExportJSON(path string, id, model, integrator, controller string, dt, duration float64, result *dynamo.Result) error {
@@ -34,6 +39,11 @@ func ExportJSON(path string, id, model, integrator, controller string, dt, durat
 		States:     make([][]float64, len(result.States)),
 		Controls:   make([][]float64, len(result.Controls)),
 		Metrics:    result.Metrics,
+
+		StateNames:   dynamo.VarNames(result.StateVars),
+		StateUnits:   dynamo.VarUnits(result.StateVars),
+		ControlNames: dynamo.VarNames(result.ControlVars),
+		ControlUnits: dynamo.VarUnits(result.ControlVars),
 	}
 
 	for i, s := range result.States {
@@ -67,6 +77,11 @@ func ExportJSONStdout(id, model, integrator, controller string, dt, duration flo
 		States:     make([][]float64, len(result.States)),
 		Controls:   make([][]float64, len(result.Controls)),
 		Metrics:    result.Metrics,
+
+		StateNames:   dynamo.VarNames(result.StateVars),
+		StateUnits:   dynamo.VarUnits(result.StateVars),
+		ControlNames: dynamo.VarNames(result.ControlVars),
+		ControlUnits: dynamo.VarUnits(result.ControlVars),
 	}
 
 	for i, s := range result.States {
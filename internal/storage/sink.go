@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// RunSink writes a run's states.csv incrementally as a dynamo.Sink, so
+// streamed simulations never hold their trajectory in memory. Metadata is
+// written when the sink begins and again, with metrics, by Finish.
+type RunSink struct {
+	id          string
+	dir         string
+	meta        RunMetadata
+	file        *os.File
+	w           *csv.Writer
+	numControls int
+	estimates   bool
+	row         []string
+
+	// controls.csv, opened on the first sampled controller update
+	ctrlFile *os.File
+	ctrlW    *csv.Writer
+}
+
+// NewRunSink creates the directory for a new run and opens its states.csv.
+func (s *Store) NewRunSink(model string, dt, duration float64, seed int64, integrator, controller string) (*RunSink, error) {
+	now := time.Now()
+	runID := fmt.Sprintf("%s_%d", model, now.Unix())
+	runDir := filepath.Join(s.baseDir, runID)
+
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(filepath.Join(runDir, "states.csv"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &RunSink{
+		id:  runID,
+		dir: runDir,
+		meta: RunMetadata{
+			ID:         runID,
+			Model:      model,
+			Timestamp:  now,
+			Seed:       seed,
+			Dt:         dt,
+			Duration:   duration,
+			Integrator: integrator,
+			Controller: controller,
+		},
+		file: file,
+		w:    csv.NewWriter(file),
+	}, nil
+}
+
+// RecordEstimates adds the state estimate, as "<state>_est" columns, and
+// its covariance trace, as "cov_trace", to states.csv. Call it before Begin.
+func (r *RunSink) RecordEstimates() { r.estimates = true }
+
+// ID returns the run ID.
+func (r *RunSink) ID() string { return r.id }
+
+func (r *RunSink) Begin(stateVars, controlVars []dynamo.Variable) error {
+	r.meta.StateNames = dynamo.VarNames(stateVars)
+	r.meta.StateUnits = dynamo.VarUnits(stateVars)
+	r.meta.ControlNames = dynamo.VarNames(controlVars)
+	r.meta.ControlUnits = dynamo.VarUnits(controlVars)
+	r.numControls = len(controlVars)
+
+	if err := r.writeMetadata(); err != nil {
+		return err
+	}
+
+	header := append([]string{"time"}, r.meta.StateNames...)
+	header = append(header, r.meta.ControlNames...)
+	if r.estimates {
+		for _, name := range r.meta.StateNames {
+			header = append(header, name+"_est")
+		}
+		header = append(header, "cov_trace")
+	}
+	return r.w.Write(header)
+}
+
+func (r *RunSink) Write(sample dynamo.Sample) error {
+	row := append(r.row[:0], strconv.FormatFloat(sample.Time, 'f', 6, 64))
+	for _, val := range sample.State {
+		row = append(row, strconv.FormatFloat(val, 'f', 6, 64))
+	}
+	for j := 0; j < r.numControls; j++ {
+		val := 0.0
+		if j < len(sample.Control) {
+			val = sample.Control[j]
+		}
+		row = append(row, strconv.FormatFloat(val, 'f', 6, 64))
+	}
+	if r.estimates {
+		for j := range r.meta.StateNames {
+			val := 0.0
+			if j < len(sample.Estimate) {
+				val = sample.Estimate[j]
+			}
+			row = append(row, strconv.FormatFloat(val, 'f', 6, 64))
+		}
+		row = append(row, strconv.FormatFloat(sample.CovTrace, 'f', 6, 64))
+	}
+	r.row = row
+	return r.w.Write(row)
+}
+
+// WriteControl implements dynamo.ControlSink, recording the updates of a
+// sampled controller in controls.csv.
+func (r *RunSink) WriteControl(t float64, u dynamo.Control) error {
+	if r.ctrlW == nil {
+		file, err := os.Create(filepath.Join(r.dir, "controls.csv"))
+		if err != nil {
+			return err
+		}
+		r.ctrlFile, r.ctrlW = file, csv.NewWriter(file)
+		if err := r.ctrlW.Write(append([]string{"time"}, r.meta.ControlNames...)); err != nil {
+			return err
+		}
+	}
+	row := []string{strconv.FormatFloat(t, 'f', 6, 64)}
+	for _, val := range u {
+		row = append(row, strconv.FormatFloat(val, 'f', 6, 64))
+	}
+	return r.ctrlW.Write(row)
+}
+
+// Close flushes and closes states.csv, and controls.csv if there is one.
+func (r *RunSink) Close() error {
+	if r.ctrlW != nil {
+		r.ctrlW.Flush()
+		err := r.ctrlW.Error()
+		if cerr := r.ctrlFile.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			r.file.Close()
+			return err
+		}
+	}
+	r.w.Flush()
+	if err := r.w.Error(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// Finish records the run's metrics and events in metadata.json.
+func (r *RunSink) Finish(result *dynamo.Result) error {
+	r.meta.Metrics = result.Metrics
+	r.meta.Events = nil
+	for _, ev := range result.Events {
+		r.meta.Events = append(r.meta.Events, EventMetadata{
+			Name:     ev.Name,
+			Time:     ev.Time,
+			State:    ev.State,
+			Terminal: ev.Terminal,
+			Reset:    ev.Reset,
+		})
+	}
+	return r.writeMetadata()
+}
+
+func (r *RunSink) writeMetadata() error {
+	metaFile, err := os.Create(filepath.Join(r.dir, "metadata.json"))
+	if err != nil {
+		return err
+	}
+	defer metaFile.Close()
+
+	enc := json.NewEncoder(metaFile)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.meta)
+}
@@ -3,7 +3,6 @@ package storage
 import (
 	"encoding/csv"
 	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -34,95 +33,79 @@ type RunMetadata struct {
 	Integrator string             `json:"integrator"`
 	Controller string             `json:"controller"`
 	Metrics    map[string]float64 `json:"metrics"`
-}
-
-func (s *Store) Save(model string, dt float64, duration float64, seed int64, integrator string, controller string, result *dynamo.Result) (string, error) {
-	runID := fmt.Sprintf("%s_%d", model, time.Now().Unix())
-	runDir := filepath.Join(s.baseDir, runID)
 
-	if err := os.MkdirAll(runDir, 0755); err != nil {
-		return "", err
-	}
-
-	meta := RunMetadata{
-		ID:         runID,
-		Model:      model,
-		Timestamp:  time.Now(),
-		Seed:       seed,
-		Dt:         dt,
-		Duration:   duration,
-		Integrator: integrator,
-		Controller: controller,
-		Metrics:    result.Metrics,
-	}
+	StateNames   []string `json:"state_names,omitempty"`
+	StateUnits   []string `json:"state_units,omitempty"`
+	ControlNames []string `json:"control_names,omitempty"`
+	ControlUnits []string `json:"control_units,omitempty"`
 
-	metaPath := filepath.Join(runDir, "metadata.json")
-	metaFile, err := os.Create(metaPath)
-	if err != nil {
-		return "", err
-	}
-	defer metaFile.Close()
+	Events []EventMetadata `json:"events,omitempty"`
+}
 
-	enc := json.NewEncoder(metaFile)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(meta); err != nil {
-		return "", err
-	}
+// EventMetadata records an event that fired during a run.
+type EventMetadata struct {
+	Name     string    `json:"name"`
+	Time     float64   `json:"time"`
+	State    []float64 `json:"state"`
+	Terminal bool      `json:"terminal,omitempty"`
+	Reset    bool      `json:"reset,omitempty"`
+}
 
-	csvPath := filepath.Join(runDir, "states.csv")
-	csvFile, err := os.Create(csvPath)
+func (s *Store) Save(model string, dt float64, duration float64, seed int64, integrator string, controller string, result *dynamo.Result) (string, error) {
+	sink, err := s.NewRunSink(model, dt, duration, seed, integrator, controller)
 	if err != nil {
 		return "", err
 	}
-	defer csvFile.Close()
-
-	w := csv.NewWriter(csvFile)
-	defer w.Flush()
-
-	if len(result.States) == 0 {
-		return runID, nil
-	}
-
-	header := []string{"time"}
-	for i := range result.States[0] {
-		header = append(header, fmt.Sprintf("x%d", i))
-	}
 
-	numControls := 0
-	if len(result.Controls) > 0 && len(result.Controls[0]) > 0 {
-		numControls = len(result.Controls[0])
-		for i := 0; i < numControls; i++ {
-			header = append(header, fmt.Sprintf("u%d", i))
+	if len(result.States) > 0 {
+		numControls := 0
+		if len(result.Controls) > 0 {
+			numControls = len(result.Controls[0])
 		}
-	}
-
-	if err := w.Write(header); err != nil {
-		return "", err
-	}
-
-	for i := range result.States {
-		row := []string{strconv.FormatFloat(result.Times[i], 'f', 6, 64)}
-
-		for _, val := range result.States[i] {
-			row = append(row, strconv.FormatFloat(val, 'f', 6, 64))
+		stateVars := result.StateVars
+		if len(stateVars) != len(result.States[0]) {
+			stateVars = dynamo.GenericVars("x", len(result.States[0]))
+		}
+		controlVars := result.ControlVars
+		if len(controlVars) != numControls {
+			controlVars = dynamo.GenericVars("u", numControls)
 		}
 
-		if i < len(result.Controls) && len(result.Controls[i]) > 0 {
-			for _, val := range result.Controls[i] {
-				row = append(row, strconv.FormatFloat(val, 'f', 6, 64))
+		if len(result.Estimates) == len(result.States) {
+			sink.RecordEstimates()
+		}
+		if err := sink.Begin(stateVars, controlVars); err != nil {
+			sink.Close()
+			return "", err
+		}
+		for i := range result.States {
+			sample := dynamo.Sample{Step: i, Time: result.Times[i], State: result.States[i]}
+			if i < len(result.Controls) {
+				sample.Control = result.Controls[i]
+			}
+			if i < len(result.Estimates) {
+				sample.Estimate, sample.CovTrace = result.Estimates[i], result.CovTraces[i]
 			}
-		} else if numControls > 0 {
-			for j := 0; j < numControls; j++ {
-				row = append(row, "0")
+			if err := sink.Write(sample); err != nil {
+				sink.Close()
+				return "", err
 			}
 		}
-
-		if err := w.Write(row); err != nil {
-			return "", err
+		for i, t := range result.ControlTimes {
+			if err := sink.WriteControl(t, result.ControlSamples[i]); err != nil {
+				sink.Close()
+				return "", err
+			}
 		}
 	}
 
-	return runID, nil
+	if err := sink.Close(); err != nil {
+		return "", err
+	}
+	if err := sink.Finish(result); err != nil {
+		return "", err
+	}
+	return sink.ID(), nil
 }
 
 func (s *Store) List() ([]RunMetadata, error) {
@@ -221,3 +204,21 @@ func (s *Store) LoadStates(runID string) ([][]float64, []float64, error) {
 	return states, times, nil
 }
 
+// LoadColumns returns the column names of a run's states.csv, excluding time.
+func (s *Store) LoadColumns(runID string) ([]string, error) {
+	csvPath := filepath.Join(s.baseDir, runID, "states.csv")
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header, err := csv.NewReader(file).Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) == 0 {
+		return []string{}, nil
+	}
+	return header[1:], nil
+}
@@ -2,7 +2,6 @@ package viz
 
 import (
 	"fmt"
-	"math"
 	"strings"
 	"time"
 
@@ -24,14 +23,9 @@ var (
 	magenta = lipgloss.NewStyle().Foreground(lipgloss.Color("213"))
 )
 
-var modelInfo = map[string]string{
-	"pendulum": "simple harmonic motion", "double_pendulum": "chaotic dynamics", "cartpole": "balance control",
-	"spring_mass": "oscillator", "drone": "2d quadrotor", "nbody": "gravitational", "lorenz": "butterfly attractor",
-	"rossler": "spiral chaos", "vanderpol": "limit cycle oscillator", "threebody": "orbital chaos", "coupled": "energy transfer",
-	"masschain": "wave propagation", "gyroscope": "rigid body rotation", "wave": "string vibration", "doublewell": "bistable potential",
-	"duffing": "chaotic oscillator", "magnetic": "fractal basins",
-	"fluid": "smoothed particle hydrodynamics",
-}
+// maxEditableStates caps how many initial-state variables the config
+// screen exposes; particle systems start from their catalog default.
+const maxEditableStates = 8
 
 const (
 	stateMenu = iota
@@ -69,9 +63,9 @@ type trailPoint struct {
 func NewInteractiveApp() *model {
 	return &model{
 		state:      stateMenu,
-		models:     []string{"pendulum", "double_pendulum", "cartpole", "spring_mass", "drone", "nbody", "lorenz", "rossler", "vanderpol", "threebody", "coupled", "masschain", "gyroscope", "wave", "doublewell", "duffing", "magnetic", "fluid"},
-		params:     map[string]float64{"theta": 0.5, "theta2": 0.5, "omega": 0.0, "omega2": 0.0, "pos": 0.0, "vel": 0.0, "dt": 0.01, "duration": 30.0},
-		paramNames: []string{"theta", "omega", "dt", "duration"},
+		models:     catalogNames(),
+		params:     map[string]float64{"dt": 0.01, "duration": 30.0},
+		paramNames: []string{"dt", "duration"},
 		dt:         0.01, speed: 1.0, width: 80, height: 24,
 	}
 }
@@ -178,35 +172,32 @@ func (m model) configKey(msg tea.KeyMsg) (model, tea.Cmd) {
 }
 
 func (m *model) setParamsForModel() {
-	switch m.selected {
-	case "pendulum":
-		m.paramNames = []string{"theta", "omega", "dt", "duration"}
-	case "double_pendulum":
-		m.paramNames = []string{"theta", "theta2", "omega", "omega2", "dt", "duration"}
-	case "cartpole":
-		m.paramNames = []string{"pos", "vel", "theta", "omega", "dt", "duration"}
-	case "spring_mass":
-		m.paramNames = []string{"pos", "vel", "dt", "duration"}
-	case "drone":
-		m.paramNames = []string{"theta", "omega", "dt", "duration"}
-	case "nbody":
-		m.paramNames = []string{"dt", "duration"}
-	case "lorenz", "rossler":
-		m.paramNames = []string{"x", "y", "z", "dt", "duration"}
-	case "vanderpol":
-		m.paramNames = []string{"x", "v", "dt", "duration"}
-	case "threebody", "masschain":
-		m.paramNames = []string{"dt", "duration"}
-	case "coupled":
-		m.paramNames = []string{"theta1", "omega1", "theta2", "omega2", "dt", "duration"}
-	case "fluid":
-		m.paramNames = []string{"h", "rho0", "stiffness", "viscosity", "gravity", "dt", "duration"}
+	m.params = map[string]float64{"dt": m.params["dt"], "duration": m.params["duration"]}
+	m.paramNames = make([]string, 0, maxEditableStates+2)
+	spec, ok := physics.Lookup(m.selected)
+	if !ok {
+		m.paramNames = append(m.paramNames, "dt", "duration")
+		return
+	}
+	dyn := spec.New()
+	if dyn.StateDim() <= maxEditableStates {
+		state := spec.Init(dyn)
+		for i, v := range dynamo.DescribeState(dyn) {
+			m.paramNames = append(m.paramNames, v.Name)
+			m.params[v.Name] = state[i]
+		}
 	}
-	for _, name := range m.paramNames {
-		if _, ok := m.params[name]; !ok {
-			m.params[name] = 0.0
+	if p, ok := dyn.(dynamo.Parameterized); ok {
+		values := p.GetParams()
+		for _, param := range p.Params() {
+			if _, dup := m.params[param.Name]; dup {
+				continue
+			}
+			m.paramNames = append(m.paramNames, param.Name)
+			m.params[param.Name] = values[param.Name]
 		}
 	}
+	m.paramNames = append(m.paramNames, "dt", "duration")
 }
 
 func (m *model) start() tea.Cmd {
@@ -215,67 +206,24 @@ func (m *model) start() tea.Cmd {
 	} else {
 		m.dt = 0.01
 	}
-	var dyn dynamo.System
-	var state []float64
-	switch m.selected {
-	case "pendulum":
-		dyn, state = physics.NewPendulum(), []float64{m.params["theta"], m.params["omega"]}
-	case "double_pendulum":
-		dyn, state = physics.NewDoublePendulum(), []float64{m.params["theta"], m.params["theta2"], m.params["omega"], m.params["omega2"]}
-	case "cartpole":
-		dyn, state = physics.NewCartPole(), []float64{m.params["pos"], m.params["vel"], m.params["theta"], m.params["omega"]}
-	case "spring_mass":
-		dyn, state = physics.NewSpringMass(), []float64{m.params["pos"], m.params["vel"]}
-	case "drone":
-		dyn, state = physics.NewDrone(), []float64{0, 5, m.params["theta"], 0, 0, m.params["omega"]}
-	case "nbody":
-		dyn, state = physics.NewNBody(3), nbodyState(3)
-	case "lorenz":
-		dyn, state = physics.NewLorenz(), []float64{m.params["x"], m.params["y"], m.params["z"]}
-		if state[0] == 0 && state[1] == 0 && state[2] == 0 {
-			state = []float64{1.0, 1.0, 1.0}
-		}
-	case "rossler":
-		dyn, state = physics.NewRossler(), []float64{m.params["x"], m.params["y"], m.params["z"]}
-		if state[0] == 0 && state[1] == 0 && state[2] == 0 {
-			state = []float64{1.0, 1.0, 1.0}
-		}
-	case "vanderpol":
-		dyn, state = physics.NewVanDerPol(), []float64{m.params["x"], m.params["v"]}
-		if state[0] == 0 && state[1] == 0 {
-			state = []float64{2.0, 0.0}
+	spec, ok := physics.Lookup(m.selected)
+	if !ok {
+		spec, _ = physics.Lookup("pendulum")
+	}
+	dyn, state := spec.Build()
+	if dyn.StateDim() <= maxEditableStates {
+		for i, v := range dynamo.DescribeState(dyn) {
+			if val, ok := m.params[v.Name]; ok && i < len(state) {
+				state[i] = val
+			}
 		}
-	case "threebody":
-		dyn = physics.NewThreeBody()
-		state = dyn.(*physics.ThreeBody).DefaultState()
-	case "coupled":
-		dyn, state = physics.NewCoupledPendulums(), []float64{m.params["theta1"], m.params["omega1"], m.params["theta2"], m.params["omega2"]}
-		if state[0] == 0 && state[2] == 0 {
-			state = []float64{0.5, 0.0, 0.0, 0.0}
+	}
+	if p, ok := dyn.(dynamo.Parameterized); ok {
+		for _, param := range p.Params() {
+			if v, ok := m.params[param.Name]; ok {
+				p.SetParam(param.Name, param.Clamp(v))
+			}
 		}
-	case "masschain":
-		mc := physics.NewMassChain(20)
-		dyn, state = mc, mc.DefaultState()
-	case "gyroscope":
-		g := physics.NewGyroscope()
-		dyn, state = g, g.DefaultState()
-	case "wave":
-		w := physics.NewWave(30)
-		dyn, state = w, w.DefaultState()
-	case "doublewell":
-		dw := physics.NewDoubleWell()
-		dyn, state = dw, dw.DefaultState()
-	case "duffing":
-		df := physics.NewDuffing()
-		dyn, state = df, df.DefaultState()
-	case "magnetic":
-		mp := physics.NewMagneticPendulum()
-		dyn, state = mp, mp.DefaultState()
-	case "fluid":
-		sp := physics.NewSPH(200)
-		dyn, state = sp, sp.DefaultState()
-	default:
-		dyn, state = physics.NewPendulum(), []float64{0.5, 0}
 	}
 	integ, ctrl := integrators.NewRK4(), control.NewNone(dyn.ControlDim())
 	m.liveModel = NewModel(dyn, integ, ctrl, state, m.dt, m.selected)
@@ -300,7 +248,7 @@ func (m model) viewMenu() string {
 	h, sub := lipgloss.NewStyle().Foreground(lipgloss.Color("#00cccc")).Bold(true), lipgloss.NewStyle().Foreground(lipgloss.Color("#666688"))
 	b.WriteString("\n\n    " + h.Render("DYNSIM") + "\n    " + sub.Render("physics simulation engine") + "\n    " + sub.Render("─────────────────────────") + "\n\n")
 	for i, name := range m.models {
-		desc := modelInfo[name]
+		desc := describe(name)
 		if len(desc) > 20 {
 			desc = desc[:17] + "..."
 		}
@@ -318,7 +266,7 @@ func (m model) viewMenu() string {
 func (m model) viewConfig() string {
 	var b strings.Builder
 	h, sub := lipgloss.NewStyle().Foreground(lipgloss.Color("#00cccc")).Bold(true), lipgloss.NewStyle().Foreground(lipgloss.Color("#666688"))
-	b.WriteString("\n\n    " + h.Render(strings.ToUpper(m.selected)) + "\n    " + sub.Render(modelInfo[m.selected]) + "\n    " + sub.Render("─────────────────────────") + "\n\n")
+	b.WriteString("\n\n    " + h.Render(strings.ToUpper(m.selected)) + "\n    " + sub.Render(describe(m.selected)) + "\n    " + sub.Render("─────────────────────────") + "\n\n")
 	for i, name := range m.paramNames {
 		val := m.params[name]
 		valStr := fmt.Sprintf("%8.3f", val)
@@ -335,13 +283,20 @@ func (m model) viewConfig() string {
 	return b.String()
 }
 
-func nbodyState(n int) dynamo.State {
-	state := make(dynamo.State, n*4)
-	for i := 0; i < n; i++ {
-		angle := float64(i) * 2.0 * math.Pi / float64(n)
-		state[i*4], state[i*4+1], state[i*4+2], state[i*4+3] = math.Cos(angle), math.Sin(angle), -math.Sin(angle)*0.5, math.Cos(angle)*0.5
+func catalogNames() []string {
+	specs := physics.Catalog()
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.Name
 	}
-	return state
+	return names
+}
+
+func describe(name string) string {
+	if spec, ok := physics.Lookup(name); ok {
+		return spec.Description
+	}
+	return ""
 }
 
 func RunInteractive() error { return tea.NewProgram(NewInteractiveApp(), tea.WithAltScreen()).Start() }
@@ -1,6 +1,7 @@
 package viz
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -22,6 +23,8 @@ const (
 	width           = 80
 	height          = 24
 	historyCapacity = 600
+	// maxTelemetryStates caps the state readout; particle systems skip it.
+	maxTelemetryStates = 8
 )
 
 // Snapshot stores state at a specific time for replay.
@@ -47,6 +50,7 @@ type TickMsg time.Time
 // Model contains simulation state, visualization buffers, and UI context.
 type Model struct {
 	dyn           dynamo.System
+	stateVars     []dynamo.Variable
 	integrator    dynamo.Integrator
 	controller    dynamo.Controller
 	state         dynamo.State
@@ -95,6 +99,7 @@ func NewModel(dyn dynamo.System, integ dynamo.Integrator, ctrl dynamo.Controller
 
 	return Model{
 		dyn:           dyn,
+		stateVars:     dynamo.DescribeState(dyn),
 		integrator:    integ,
 		controller:    ctrl,
 		state:         dynamo.State(initState),
@@ -219,19 +224,34 @@ func (m *Model) adjustParam(factor float64) {
 	key := m.paramKeys[m.selected]
 	val := m.params[key]
 	newVal := val * factor
-	m.params[key] = newVal
+	if p, ok := m.dyn.(dynamo.Parameterized); ok {
+		if param, ok := dynamo.LookupParam(p.Params(), key); ok {
+			newVal = param.Clamp(newVal)
+		}
+	}
 	if t, ok := m.dyn.(dynamo.Configurable); ok {
-		t.SetParam(key, newVal)
+		if err := t.SetParam(key, newVal); err != nil {
+			return
+		}
 	}
+	m.params[key] = newVal
 }
 
 // step advances the physics simulation.
 func (m *Model) step() {
+	prev, t0 := m.state, m.t
 	m.u = m.controller.Compute(m.state, m.t)
 	if adaptive, ok := m.integrator.(dynamo.AdaptiveIntegrator); ok {
-		newState, suggestedDt, _ := adaptive.StepAdaptive(m.dyn, m.state, m.u, m.t, m.dt, 1e-6)
+		// advance by the step actually taken, retrying rejected steps
+		// until the suggestion drops below the live view's floor
+		h := m.dt
+		newState, suggestedDt, err := adaptive.StepAdaptive(m.dyn, m.state, m.u, m.t, h, 1e-6)
+		for errors.Is(err, dynamo.ErrStepRejected) && suggestedDt > 0.0001 {
+			h = suggestedDt
+			newState, suggestedDt, err = adaptive.StepAdaptive(m.dyn, m.state, m.u, m.t, h, 1e-6)
+		}
 		m.state = newState
-		m.t += m.dt
+		m.t += h
 		if suggestedDt > 0.0001 && suggestedDt < 0.1 {
 			m.dt = suggestedDt
 		}
@@ -239,6 +259,7 @@ func (m *Model) step() {
 		m.state = m.integrator.Step(m.dyn, m.state, m.u, m.t, m.dt)
 		m.t += m.dt
 	}
+	m.applyGuards(prev, t0)
 
 	energy := 0.0
 	if e, ok := m.dyn.(dynamo.Hamiltonian); ok {
@@ -263,6 +284,21 @@ func (m *Model) step() {
 	}
 }
 
+// applyGuards fires the resets of a hybrid model whose guards were crossed
+// during the last step. The live view applies them at the end of the step
+// rather than locating the crossing, which is close enough to watch.
+func (m *Model) applyGuards(prev dynamo.State, t0 float64) {
+	hs, ok := m.dyn.(dynamo.HybridSystem)
+	if !ok {
+		return
+	}
+	for _, g := range hs.Guards() {
+		if g.Reset != nil && g.Crosses(g.Fn(t0, prev), g.Fn(m.t, m.state)) {
+			m.state = g.Reset(m.t, m.state)
+		}
+	}
+}
+
 // scrub changes the playback position in history.
 func (m *Model) scrub(dir int) {
 	if m.playHead == -1 {
@@ -344,6 +380,12 @@ func (m Model) View() string {
 	} else {
 		s.WriteString(labelStyle.Render("GPU") + valueStyle.Render("CPU mode") + "\n")
 	}
+	if len(m.stateVars) <= maxTelemetryStates && len(state) >= len(m.stateVars) {
+		s.WriteString("\nSTATE\n")
+		for i, v := range m.stateVars {
+			s.WriteString(labelStyle.Render(v.Name) + valueStyle.Render(strings.TrimSpace(fmt.Sprintf("%.3f %s", state[i], v.Unit))) + "\n")
+		}
+	}
 	s.WriteString("\nPARAMETERS\n")
 	if len(m.params) > 0 {
 		for i, k := range m.paramKeys {
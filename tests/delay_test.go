@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/integrators"
+	"github.com/san-kum/dynsim/internal/physics"
+)
+
+var _ = Describe("Delay systems", func() {
+	It("keep their history across event location", func() {
+		run := func(watch bool) dynamo.State {
+			dyn := physics.NewMackeyGlass()
+			sim := dynamo.New(dyn, integrators.NewMethodOfSteps(integrators.NewRK4()), noControl{})
+			if watch {
+				sim.AddEvent(dynamo.Event{Name: "one", Fn: func(_ float64, x dynamo.State) float64 { return x[0] - 1 }})
+			}
+			res, err := sim.Run(context.Background(), dyn.DefaultState(), dynamo.Config{Dt: 0.5, Duration: 200})
+			Expect(err).NotTo(HaveOccurred())
+			if watch {
+				Expect(res.Events).NotTo(BeEmpty())
+			}
+			return res.States[len(res.States)-1]
+		}
+		Expect(run(true)).To(Equal(run(false)))
+	})
+
+	It("stay defined for negative states with a fractional exponent", func() {
+		m := physics.NewMackeyGlass()
+		Expect(m.SetParam("n", 9.5)).To(Succeed())
+		neg, pos := m.Derive(dynamo.State{-0.5}, nil, 0), m.Derive(dynamo.State{0.5}, nil, 0)
+		Expect(math.IsNaN(neg[0])).To(BeFalse())
+		Expect(neg[0]).To(BeNumerically("~", -pos[0], 1e-15))
+	})
+})
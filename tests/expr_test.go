@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/san-kum/dynsim/internal/expr"
+)
+
+// eval compiles src over x and y and evaluates it at (x, y).
+func eval(src string, x, y float64) float64 {
+	f, err := expr.Compile(src, []string{"x", "y"})
+	Expect(err).NotTo(HaveOccurred())
+	return f([]float64{x, y})
+}
+
+var _ = Describe("Expressions", func() {
+	DescribeTable("evaluate with the usual precedence",
+		func(src string, want float64) {
+			Expect(eval(src, 2, 3)).To(BeNumerically("~", want, 1e-12))
+		},
+		Entry("products before sums", "1 + 2*3", 7.0),
+		Entry("left-associative subtraction", "10 - 4 - 3", 3.0),
+		Entry("left-associative division", "12 / 3 / 2", 2.0),
+		Entry("right-associative powers", "2^3^2", 512.0),
+		Entry("unary minus below powers", "-x^2", -4.0),
+		Entry("negative exponents", "x^-1", 0.5),
+		Entry("parentheses", "(1 + 2)*3", 9.0),
+		Entry("variables", "x*y - y", 3.0),
+		Entry("comparisons yield 1 or 0", "(x < y) + (x > y)", 1.0),
+		Entry("comparisons bind loosest", "x + 1 == y", 1.0),
+		Entry("piecewise if", "if(x > 1, 10, 20)", 10.0),
+		Entry("constants", "cos(pi) + log(e)", 0.0),
+		Entry("unary functions", "sqrt(abs(-16)) + floor(2.7)", 6.0),
+		Entry("binary functions", "atan2(1, 1)*4 - pi + max(x, y) + hypot(3, 4)", 8.0),
+		Entry("scientific notation", "1.5e2 + .5", 150.5),
+	)
+
+	It("lets names shadow the constants", func() {
+		f, err := expr.Compile("e*2", []string{"e"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f([]float64{3})).To(Equal(6.0))
+	})
+
+	DescribeTable("reject malformed input",
+		func(src, msg string) {
+			_, err := expr.Compile(src, []string{"x"})
+			Expect(err).To(MatchError(ContainSubstring(msg)))
+		},
+		Entry("unknown variable", "x + z", "unknown variable: z"),
+		Entry("unknown function", "foo(x)", "unknown function: foo"),
+		Entry("wrong arity", "atan2(x)", "atan2 takes 2 arguments, got 1"),
+		Entry("unclosed parenthesis", "(x + 1", "expr"),
+		Entry("trailing tokens", "x x", "expr"),
+		Entry("dangling operator", "x +", "expr"),
+		Entry("empty input", "", "expr"),
+	)
+
+	It("formats trees with explicit grouping", func() {
+		n, err := expr.Parse("-x^2 + sin(y)*3")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n.String()).To(Equal("(-(x ^ 2) + (sin(y) * 3))"))
+	})
+
+	It("evaluates without allocating", func() {
+		f, err := expr.Compile("if(x > 0, sin(x), atan2(x, 1)) + max(x, y)^2", []string{"x", "y"})
+		Expect(err).NotTo(HaveOccurred())
+		vars := []float64{0.5, 2}
+		Expect(testing.AllocsPerRun(100, func() { f(vars) })).To(BeZero())
+		Expect(f(vars)).To(BeNumerically("~", math.Sin(0.5)+4, 1e-12))
+	})
+})
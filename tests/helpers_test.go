@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"math"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+)
+
+// oscillator is x” = -x with the state [x, v]; from (1, 0) the exact
+// solution is (cos t, -sin t).
+type oscillator struct{}
+
+func (oscillator) Derive(x dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
+	return dynamo.State{x[1], -x[0]}
+}
+func (oscillator) StateDim() int                 { return 2 }
+func (oscillator) ControlDim() int               { return 0 }
+func (oscillator) Energy(x dynamo.State) float64 { return 0.5 * (x[0]*x[0] + x[1]*x[1]) }
+func (oscillator) Split() (pos, vel []int)       { return []int{0}, []int{1} }
+
+// growth is dx/dt = x, with no position/velocity split.
+type growth struct{}
+
+func (growth) Derive(x dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
+	return dynamo.State{x[0]}
+}
+func (growth) StateDim() int   { return 1 }
+func (growth) ControlDim() int { return 0 }
+
+type noControl struct{}
+
+func (noControl) Compute(dynamo.State, float64) dynamo.Control { return nil }
+
+// oscillatorError integrates the oscillator to t = 1 in n steps and returns
+// the distance from the exact solution.
+func oscillatorError(integ dynamo.Integrator, n int) float64 {
+	x := dynamo.State{1, 0}
+	h := 1.0 / float64(n)
+	for k := 0; k < n; k++ {
+		x = integ.Step(oscillator{}, x, nil, float64(k)*h, h)
+	}
+	return math.Hypot(x[0]-math.Cos(1), x[1]+math.Sin(1))
+}
+
+// observedOrder estimates the convergence order from runs of n and 2n
+// steps.
+func observedOrder(newInteg func() dynamo.Integrator, n int) float64 {
+	coarse := oscillatorError(newInteg(), n)
+	fine := oscillatorError(newInteg(), 2*n)
+	return math.Log2(coarse / fine)
+}
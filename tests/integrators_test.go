@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/integrators"
+)
+
+var _ = Describe("Integrators", func() {
+	DescribeTable("converge at their nominal order on the harmonic oscillator",
+		func(newInteg func() dynamo.Integrator, order float64) {
+			Expect(observedOrder(newInteg, 16)).To(BeNumerically("~", order, 0.1))
+		},
+		Entry("euler", func() dynamo.Integrator { return integrators.NewEuler() }, 1.0),
+		Entry("rk4", func() dynamo.Integrator { return integrators.NewRK4() }, 4.0),
+		Entry("rk45", func() dynamo.Integrator { return integrators.NewRK45() }, 5.0),
+		Entry("backward_euler", func() dynamo.Integrator { return integrators.NewBackwardEuler() }, 1.0),
+		Entry("trapezoidal", func() dynamo.Integrator { return integrators.NewTrapezoidal() }, 2.0),
+		Entry("bdf2", func() dynamo.Integrator { return integrators.NewBDF2() }, 2.0),
+		Entry("rosenbrock", func() dynamo.Integrator { return integrators.NewRosenbrock() }, 2.0),
+	)
+
+	Describe("implicit methods", func() {
+		It("damp a stiff decay that explicit Euler blows up on", func() {
+			stiff := dynamo.State{1}
+			decay := decaying{rate: 1000}
+			be, eu := stiff.Clone(), stiff.Clone()
+			for k := 0; k < 100; k++ {
+				be = integrators.NewBackwardEuler().Step(decay, be, nil, float64(k)*0.01, 0.01)
+				eu = integrators.NewEuler().Step(decay, eu, nil, float64(k)*0.01, 0.01)
+			}
+			Expect(math.Abs(be[0])).To(BeNumerically("<", 1e-10))
+			Expect(math.Abs(eu[0])).To(BeNumerically(">", 1e10))
+		})
+
+		It("stop a fixed-step run whose iteration matrix is singular", func() {
+			// I - h·J = 1 - 1·1 is singular for backward Euler at dt = 1.
+			sim := dynamo.New(growth{}, integrators.NewBackwardEuler(), noControl{})
+			_, err := sim.Run(context.Background(), dynamo.State{1}, dynamo.Config{Dt: 1, Duration: 3})
+			Expect(err).To(MatchError(dynamo.ErrNoConvergence))
+			var simErr *dynamo.SimulationError
+			Expect(errors.As(err, &simErr)).To(BeTrue())
+			Expect(simErr.Time).To(Equal(0.0))
+		})
+
+		It("retry failed solves with shorter adaptive steps", func() {
+			sim := dynamo.New(growth{}, integrators.NewBDF2(), noControl{})
+			res, err := sim.Run(context.Background(), dynamo.State{1}, dynamo.Config{
+				Dt: 1, Duration: 3, Adaptive: true, Tolerance: 1e-4, MinDt: 1e-6,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RejectedSteps).To(BeNumerically(">", 0))
+			Expect(res.States[len(res.States)-1][0]).To(BeNumerically("~", math.Exp(3), 0.2))
+		})
+
+		It("keep BDF2 history across event location", func() {
+			run := func(watch bool) dynamo.State {
+				sim := dynamo.New(oscillator{}, integrators.NewBDF2(), noControl{})
+				if watch {
+					sim.AddEvent(dynamo.Event{Name: "zero", Fn: func(_ float64, x dynamo.State) float64 { return x[0] }})
+				}
+				res, err := sim.Run(context.Background(), dynamo.State{1, 0}, dynamo.Config{Dt: 0.01, Duration: 3})
+				Expect(err).NotTo(HaveOccurred())
+				if watch {
+					Expect(res.Events).NotTo(BeEmpty())
+					Expect(res.Events[0].Time).To(BeNumerically("~", math.Pi/2, 1e-4))
+				}
+				return res.States[len(res.States)-1]
+			}
+			Expect(run(true)).To(Equal(run(false)))
+		})
+	})
+})
+
+// decaying is dx/dt = -rate·x.
+type decaying struct{ rate float64 }
+
+func (d decaying) Derive(x dynamo.State, _ dynamo.Control, _ float64) dynamo.State {
+	return dynamo.State{-d.rate * x[0]}
+}
+func (decaying) StateDim() int   { return 1 }
+func (decaying) ControlDim() int { return 0 }
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/expr"
+	"github.com/san-kum/dynsim/internal/integrators"
+	"github.com/san-kum/dynsim/internal/lagrange"
+	"github.com/san-kum/dynsim/internal/physics"
+)
+
+func pendulumSystem() lagrange.System {
+	return lagrange.System{
+		Coords:    []lagrange.Coord{{Name: "theta", Velocity: "omega", Unit: "rad"}},
+		Controls:  []dynamo.Variable{{Name: "torque"}},
+		Params:    []dynamo.Param{{Name: "g", Default: 9.81, Min: 0, Max: 100}},
+		Kinetic:   "0.5*omega^2",
+		Potential: "-g*cos(theta)",
+		Forces:    map[string]string{"theta": "torque"},
+	}
+}
+
+var _ = Describe("Symbolic differentiation", func() {
+	DescribeTable("matches the analytic derivative",
+		func(src string, want func(x float64) float64) {
+			n, err := expr.Parse(src)
+			Expect(err).NotTo(HaveOccurred())
+			d, err := n.Diff("x").Compile([]string{"x", "y"})
+			Expect(err).NotTo(HaveOccurred())
+			for _, x := range []float64{-1.3, 0.4, 2.1} {
+				Expect(d([]float64{x, 0.7})).To(BeNumerically("~", want(x), 1e-12))
+			}
+		},
+		Entry("powers", "x^3 + 2*x", func(x float64) float64 { return 3*x*x + 2 }),
+		Entry("products", "x*y*sin(x)", func(x float64) float64 { return 0.7 * (math.Sin(x) + x*math.Cos(x)) }),
+		Entry("quotients", "y/x", func(x float64) float64 { return -0.7 / (x * x) }),
+		Entry("chain rule", "exp(cos(x^2))", func(x float64) float64 {
+			return -2 * x * math.Sin(x*x) * math.Exp(math.Cos(x*x))
+		}),
+		Entry("variable exponents", "y^x", func(x float64) float64 { return math.Pow(0.7, x) * math.Log(0.7) }),
+		Entry("other variables", "y^2 + 3", func(float64) float64 { return 0 }),
+	)
+})
+
+var _ = Describe("Lagrangian models", func() {
+	It("derive the pendulum equation", func() {
+		m, err := lagrange.New(pendulumSystem())
+		Expect(err).NotTo(HaveOccurred())
+		for _, theta := range []float64{-2, 0.3, 1.1} {
+			dx := m.Derive(dynamo.State{theta, 0.5}, dynamo.Control{0.2}, 0)
+			Expect(dx[0]).To(Equal(0.5))
+			Expect(dx[1]).To(BeNumerically("~", -9.81*math.Sin(theta)+0.2, 1e-12))
+		}
+		Expect(m.Energy(dynamo.State{0, 2})).To(BeNumerically("~", 2-9.81, 1e-12))
+	})
+
+	It("derive the elastic pendulum equations", func() {
+		p := physics.NewElasticPendulum()
+		Expect(p.SetParam("damping", 0.3)).To(Succeed())
+		mass, k, rest, c, g := 1.0, 40.0, 1.0, 0.3, 9.81
+
+		r, theta, vr, omega, force := 1.3, 0.6, -0.4, 1.7, 2.0
+		dx := p.Derive(dynamo.State{r, theta, vr, omega}, dynamo.Control{force}, 0)
+		Expect(dx[0]).To(Equal(vr))
+		Expect(dx[1]).To(Equal(omega))
+		Expect(dx[2]).To(BeNumerically("~",
+			r*omega*omega-k/mass*(r-rest)+g*math.Cos(theta)+(force-c*vr)/mass, 1e-12))
+		Expect(dx[3]).To(BeNumerically("~",
+			(-2*vr*omega-g*math.Sin(theta))/r-c*omega/mass, 1e-12))
+	})
+
+	It("conserve their energy function without forces", func() {
+		p := physics.NewElasticPendulum()
+		x := p.DefaultState()
+		e0 := p.Energy(x)
+		integ := integrators.NewRK4()
+		for k := 0; k < 5000; k++ {
+			x = integ.Step(p, x, dynamo.Control{0}, float64(k)*0.001, 0.001)
+		}
+		Expect(p.Energy(x)).To(BeNumerically("~", e0, 1e-8*math.Abs(e0)))
+	})
+
+	Describe("splits", func() {
+		It("are declared when the accelerations ignore the velocities", func() {
+			m := lagrange.MustNew(pendulumSystem())
+			pos, vel, err := dynamo.SplitOf(m)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pos).To(Equal([]int{0}))
+			Expect(vel).To(Equal([]int{1}))
+		})
+
+		DescribeTable("are withheld for velocity-coupled systems",
+			func(edit func(*lagrange.System)) {
+				sys := pendulumSystem()
+				edit(&sys)
+				m, err := lagrange.New(sys)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(dynamo.CheckIntegrator(m, integrators.NewVerlet())).To(MatchError(dynamo.ErrNotSeparable))
+			},
+			Entry("velocity-dependent forces", func(s *lagrange.System) {
+				s.Forces["theta"] = "torque - 0.1*omega"
+			}),
+			Entry("configuration-dependent mass", func(s *lagrange.System) {
+				s.Kinetic = "0.5*(2 + cos(theta))*omega^2"
+			}),
+			Entry("time-dependent mass", func(s *lagrange.System) {
+				s.Kinetic = "0.5*(2 + sin(t))*omega^2"
+			}),
+			Entry("gyroscopic terms", func(s *lagrange.System) {
+				s.Coords = append(s.Coords, lagrange.Coord{Name: "phi"})
+				s.Kinetic = "0.5*omega^2 + 0.5*phi_dot^2 + theta*phi_dot"
+			}),
+		)
+
+		It("are withheld for the elastic pendulum", func() {
+			_, _, err := dynamo.SplitOf(physics.NewElasticPendulum())
+			Expect(err).To(MatchError(dynamo.ErrNotSeparable))
+		})
+	})
+
+	DescribeTable("reject malformed systems",
+		func(edit func(*lagrange.System), msg string) {
+			sys := pendulumSystem()
+			edit(&sys)
+			_, err := lagrange.New(sys)
+			Expect(err).To(MatchError(ContainSubstring(msg)))
+		},
+		Entry("no coordinates", func(s *lagrange.System) { s.Coords = nil }, "no coordinates"),
+		Entry("no kinetic energy", func(s *lagrange.System) { s.Kinetic = "" }, "no kinetic energy"),
+		Entry("duplicate names", func(s *lagrange.System) { s.Params[0].Name = "omega" }, "duplicate name: omega"),
+		Entry("force on an unknown coordinate", func(s *lagrange.System) { s.Forces["x"] = "1" }, "unknown coordinate: x"),
+		Entry("unknown variable", func(s *lagrange.System) { s.Potential = "-h*cos(theta)" }, "unknown variable: h"),
+	)
+})
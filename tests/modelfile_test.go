@@ -0,0 +1,114 @@
+package tests
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/integrators"
+	"github.com/san-kum/dynsim/internal/modelfile"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+// springDef is a driven, damped spring: x' = v, v' = -k·x - c·v + force.
+func springDef() modelfile.Definition {
+	return modelfile.Definition{
+		Name:     "spring",
+		State:    []modelfile.StateVar{{Name: "x", Init: 1}, {Name: "v"}},
+		Controls: []modelfile.Var{{Name: "force"}},
+		Params: []modelfile.ParamDef{
+			{Name: "k", Default: 4, Min: ptr(0)},
+			{Name: "c", Default: 0},
+		},
+		Derivatives: map[string]string{"x": "v", "v": "-k*x - c*v + force"},
+		Energy:      "0.5*v^2 + 0.5*k*x^2",
+	}
+}
+
+var _ = Describe("Model files", func() {
+	It("compile derivatives over state, controls and params", func() {
+		m, err := modelfile.Compile(springDef())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.StateDim()).To(Equal(2))
+		Expect(m.ControlDim()).To(Equal(1))
+		Expect(m.DefaultState()).To(Equal(dynamo.State{1, 0}))
+		Expect(m.Derive(dynamo.State{1, 2}, dynamo.Control{3}, 0)).To(Equal(dynamo.State{2, -1}))
+		Expect(m.Derive(dynamo.State{1, 2}, nil, 0)).To(Equal(dynamo.State{2, -4}))
+		Expect(m.Energy(dynamo.State{1, 2})).To(Equal(4.0))
+	})
+
+	It("check parameters against their bounds", func() {
+		m, err := modelfile.Compile(springDef())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.SetParam("k", 9)).To(Succeed())
+		Expect(m.GetParams()).To(HaveKeyWithValue("k", 9.0))
+		Expect(m.SetParam("k", -1)).To(MatchError(dynamo.ErrParameterBounds))
+
+		clone := m.Clone()
+		Expect(clone.SetParam("k", 1)).To(Succeed())
+		Expect(m.GetParams()).To(HaveKeyWithValue("k", 9.0))
+	})
+
+	DescribeTable("reject inconsistent definitions",
+		func(edit func(*modelfile.Definition), msg string) {
+			def := springDef()
+			edit(&def)
+			_, err := modelfile.Compile(def)
+			Expect(err).To(MatchError(ContainSubstring(msg)))
+		},
+		Entry("no name", func(d *modelfile.Definition) { d.Name = "" }, "needs a name"),
+		Entry("missing derivative", func(d *modelfile.Definition) { delete(d.Derivatives, "v") }, "missing derivative for state v"),
+		Entry("unknown state", func(d *modelfile.Definition) { d.Derivatives["w"] = "0" }, "unknown state: w"),
+		Entry("duplicate name", func(d *modelfile.Definition) { d.Params[1].Name = "x" }, "duplicate name: x"),
+		Entry("default out of bounds", func(d *modelfile.Definition) { d.Params[0].Default = -1 }, "default -1 not in"),
+		Entry("bad expression", func(d *modelfile.Definition) { d.Derivatives["v"] = "-k*" }, "dv/dt"),
+		Entry("half a split", func(d *modelfile.Definition) { d.Positions = []string{"x"} }, "1 positions but 0 velocities"),
+		Entry("split of an unknown state", func(d *modelfile.Definition) {
+			d.Positions, d.Velocities = []string{"x"}, []string{"w"}
+		}, "unknown state: w"),
+		Entry("state split twice", func(d *modelfile.Definition) {
+			d.Positions, d.Velocities = []string{"x"}, []string{"x"}
+		}, "split twice"),
+		Entry("position that does not integrate to its velocity", func(d *modelfile.Definition) {
+			d.Positions, d.Velocities = []string{"x"}, []string{"v"}
+			d.Derivatives["x"] = "2*v"
+		}, "dx/dt must be v"),
+		Entry("velocity-dependent acceleration", func(d *modelfile.Definition) {
+			d.Positions, d.Velocities = []string{"x"}, []string{"v"}
+		}, "reads velocity v"),
+	)
+
+	Describe("position/velocity splits", func() {
+		It("are optional", func() {
+			m, err := modelfile.Compile(springDef())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dynamo.CheckIntegrator(m, integrators.NewVerlet())).To(MatchError(dynamo.ErrNotSeparable))
+		})
+
+		It("let the henon-heiles example run with yoshida6", func() {
+			m, err := modelfile.Load("../examples/henon_heiles.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			pos, vel, err := dynamo.SplitOf(m)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pos).To(Equal([]int{0, 1}))
+			Expect(vel).To(Equal([]int{2, 3}))
+
+			integ := integrators.NewYoshida6()
+			Expect(dynamo.CheckIntegrator(m, integ)).To(Succeed())
+			x := m.DefaultState()
+			e0 := m.Energy(x)
+			for k := 0; k < 10000; k++ {
+				x = integ.Step(m, x, nil, float64(k)*0.01, 0.01)
+			}
+			Expect(m.Energy(x)).To(BeNumerically("~", e0, 1e-10))
+		})
+	})
+
+	It("loads the lorenz96 example", func() {
+		m, err := modelfile.Load("../examples/lorenz96.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Name()).To(Equal("lorenz96"))
+		Expect(m.SetParam("F", 10)).To(Succeed())
+	})
+})
@@ -0,0 +1,36 @@
+package tests
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/integrators"
+)
+
+var _ = Describe("Simulator step accounting", func() {
+	It("records the truncated step at a terminal event", func() {
+		sim := dynamo.New(oscillator{}, integrators.NewRK4(), noControl{})
+		sim.AddEvent(dynamo.Event{Name: "zero", Fn: func(_ float64, x dynamo.State) float64 { return x[0] }, Terminal: true})
+		res, err := sim.Run(context.Background(), dynamo.State{1, 0}, dynamo.Config{Dt: 0.5, Duration: 3})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StepsTaken).To(Equal(4))
+		Expect(res.MaxStep).To(Equal(0.5))
+		Expect(res.MinStep).To(BeNumerically("~", math.Pi/2-1.5, 1e-3))
+		Expect(res.Times[len(res.Times)-1]).To(BeNumerically("~", math.Pi/2, 1e-3))
+	})
+
+	It("absorbs a final sliver shorter than MinDt", func() {
+		sim := dynamo.New(oscillator{}, integrators.NewRK45(), noControl{})
+		res, err := sim.Run(context.Background(), dynamo.State{1, 0}, dynamo.Config{
+			Dt: 0.33, Duration: 1, Adaptive: true, Tolerance: 1, MinDt: 0.05, MaxDt: 0.33,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Times).To(HaveLen(4))
+		Expect(res.Times[3]).To(Equal(1.0))
+		Expect(res.MinStep).To(BeNumerically(">=", 0.05))
+	})
+})
@@ -0,0 +1,13 @@
+package tests
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDynsim(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DynSim Suite")
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/san-kum/dynsim/internal/dynamo"
+	"github.com/san-kum/dynsim/internal/integrators"
+)
+
+// noSplit implements dynamo.Separable but declares no split.
+type noSplit struct{ oscillator }
+
+func (noSplit) Split() (pos, vel []int) { return nil, nil }
+
+// worstEnergyError steps the oscillator n times by h and returns the
+// largest energy error seen.
+func worstEnergyError(integ dynamo.Integrator, n int, h float64) float64 {
+	x := dynamo.State{1, 0}
+	e0 := oscillator{}.Energy(x)
+	worst := 0.0
+	for k := 0; k < n; k++ {
+		x = integ.Step(oscillator{}, x, nil, float64(k)*h, h)
+		worst = math.Max(worst, math.Abs(oscillator{}.Energy(x)-e0))
+	}
+	return worst
+}
+
+var _ = Describe("Symplectic integrators", func() {
+	// The higher orders reach round-off within a few dozen steps, so they
+	// are measured on coarser grids.
+	DescribeTable("converge at their nominal order on the harmonic oscillator",
+		func(newInteg func() dynamo.Integrator, n int, order, tol float64) {
+			Expect(observedOrder(newInteg, n)).To(BeNumerically("~", order, tol))
+		},
+		Entry("verlet", func() dynamo.Integrator { return integrators.NewVerlet() }, 16, 2.0, 0.1),
+		Entry("leapfrog", func() dynamo.Integrator { return integrators.NewLeapfrog() }, 16, 2.0, 0.1),
+		Entry("forest_ruth", func() dynamo.Integrator { return integrators.NewForestRuth() }, 16, 4.0, 0.1),
+		Entry("pefrl", func() dynamo.Integrator { return integrators.NewPEFRL() }, 16, 4.0, 0.1),
+		Entry("yoshida6", func() dynamo.Integrator { return integrators.NewYoshida6() }, 8, 6.0, 0.1),
+		Entry("yoshida8", func() dynamo.Integrator { return integrators.NewYoshida8() }, 4, 8.0, 0.6),
+	)
+
+	It("keep the energy error bounded over long runs", func() {
+		short := worstEnergyError(integrators.NewVerlet(), 1000, 0.1)
+		long := worstEnergyError(integrators.NewVerlet(), 100000, 0.1)
+		Expect(long).To(BeNumerically("<", 1.01*short))
+
+		// rk4 is more accurate per step but drifts steadily.
+		Expect(worstEnergyError(integrators.NewRK4(), 100000, 0.1)).
+			To(BeNumerically(">", 50*worstEnergyError(integrators.NewRK4(), 1000, 0.1)))
+	})
+
+	Describe("split checks", func() {
+		It("accept a model that declares its split", func() {
+			Expect(dynamo.CheckIntegrator(oscillator{}, integrators.NewYoshida6())).To(Succeed())
+		})
+
+		It("refuse models without a split", func() {
+			Expect(dynamo.CheckIntegrator(growth{}, integrators.NewVerlet())).
+				To(MatchError(dynamo.ErrNotSeparable))
+			Expect(dynamo.CheckIntegrator(noSplit{}, integrators.NewPEFRL())).
+				To(MatchError(dynamo.ErrNotSeparable))
+		})
+
+		It("leave other integrators alone", func() {
+			Expect(dynamo.CheckIntegrator(growth{}, integrators.NewRK4())).To(Succeed())
+		})
+
+		It("stop a run before its first step", func() {
+			sim := dynamo.New(noSplit{}, integrators.NewVerlet(), noControl{})
+			_, err := sim.Run(context.Background(), dynamo.State{1, 0}, dynamo.Config{Dt: 0.1, Duration: 1})
+			Expect(err).To(MatchError(dynamo.ErrNotSeparable))
+		})
+	})
+})